@@ -0,0 +1,116 @@
+package itispay
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+)
+
+// ErrUnknownAccount is returned by an AccountKeyFunc for accounts it has no key for
+var ErrUnknownAccount = errors.New("itispay: unknown account")
+
+// AccountKeyFunc looks up the API key of an account, e.g. in a tenant database or
+// secrets manager
+type AccountKeyFunc func(ctx context.Context, accountID string) (string, error)
+
+// AccountManager manages clients for many accounts with their own API keys, e.g. the
+// tenants of a platform. Clients are created on first use and share one connection pool
+// and the WithMaxConcurrentRequests limit; all other options apply per account. It is
+// safe for concurrent use:
+//
+//	manager := itispay.NewAccountManager(lookupTenantKey, itispay.WithMaxConcurrentRequests(50))
+//	invoice, err := manager.ForAccount(tenantID).CreateInvoice(ctx, req)
+type AccountManager struct {
+	lookup     AccountKeyFunc
+	opts       []Option
+	httpClient *http.Client
+	limiter    *concurrencyLimiter
+
+	mu      sync.Mutex
+	clients map[string]*Client
+}
+
+// NewAccountManager returns a manager obtaining account keys from lookup and
+// configuring every client with opts
+func NewAccountManager(lookup AccountKeyFunc, opts ...Option) *AccountManager {
+	shared := NewClient("", opts...)
+	return &AccountManager{
+		lookup:     lookup,
+		opts:       opts,
+		httpClient: shared.httpClient,
+		limiter:    shared.limiter,
+		clients:    make(map[string]*Client),
+	}
+}
+
+// ForAccount returns the client of an account, creating it on first use. The key is
+// looked up on the first request and cached; a request failing with HTTP 401 looks it up
+// again, so rotated keys are picked up. Lookup errors fail the request.
+func (m *AccountManager) ForAccount(accountID string) *Client {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if c, ok := m.clients[accountID]; ok {
+		return c
+	}
+	c := newClient("", m.opts)
+	c.credentials = &accountCredentials{lookup: m.lookup, accountID: accountID}
+	c.httpClient = m.httpClient
+	c.limiter = m.limiter
+	m.clients[accountID] = c
+	return c
+}
+
+// Remove discards the client of an account, e.g. when a tenant leaves or changes keys;
+// the next ForAccount call creates a new one
+func (m *AccountManager) Remove(accountID string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.clients, accountID)
+}
+
+// Accounts returns the IDs of the accounts with a client
+func (m *AccountManager) Accounts() []string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	ids := make([]string, 0, len(m.clients))
+	for id := range m.clients {
+		ids = append(ids, id)
+	}
+	return ids
+}
+
+// accountCredentials is the CredentialsRefresher of an account client, caching the key
+// obtained from the lookup function
+type accountCredentials struct {
+	lookup    AccountKeyFunc
+	accountID string
+
+	mu     sync.Mutex
+	apiKey string
+}
+
+// GetAPIKey implements CredentialsProvider
+func (a *accountCredentials) GetAPIKey(ctx context.Context) (string, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.apiKey != "" {
+		return a.apiKey, nil
+	}
+	apiKey, err := a.lookup(ctx, a.accountID)
+	if err != nil {
+		return "", fmt.Errorf("account %s: %w", a.accountID, err)
+	}
+	a.apiKey = apiKey
+	return apiKey, nil
+}
+
+// Refresh implements CredentialsRefresher
+func (a *accountCredentials) Refresh(context.Context) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.apiKey = ""
+	return nil
+}
@@ -0,0 +1,70 @@
+package itispay
+
+import (
+	"context"
+	"net/url"
+	"time"
+)
+
+// API key scope constants
+const (
+	ScopeInvoicesRead  = "invoices:read"
+	ScopeInvoicesWrite = "invoices:write"
+	ScopePayoutsRead   = "payouts:read"
+	ScopePayoutsWrite  = "payouts:write"
+	ScopeKeysManage    = "keys:manage"
+)
+
+// APIKey describes an API key of the project. The secret is only returned once, by
+// CreateAPIKey.
+type APIKey struct {
+	ID     string   `json:"id"`
+	Name   string   `json:"name"`
+	Prefix string   `json:"prefix"`
+	Scopes []string `json:"scopes"`
+	// Environment is the environment the key authenticates against
+	Environment Environment `json:"environment"`
+	CreatedAt   time.Time   `json:"created_at"`
+	ExpiresAt   *time.Time  `json:"expires_at,omitempty"`
+	LastUsedAt  *time.Time  `json:"last_used_at,omitempty"`
+	RevokedAt   *time.Time  `json:"revoked_at,omitempty"`
+}
+
+// CreateAPIKeyRequest represents the request to create an API key
+type CreateAPIKeyRequest struct {
+	Name string `json:"name"`
+	// Scopes are the Scope* values granted to the key
+	Scopes    []string   `json:"scopes"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+}
+
+// CreatedAPIKey is a newly created API key with its secret
+type CreatedAPIKey struct {
+	APIKey
+	// Secret is the key to authenticate with. It cannot be retrieved again, so store it
+	// in your secrets manager right away.
+	Secret string `json:"secret"`
+}
+
+// ListAPIKeysResponse represents the response from listing API keys
+type ListAPIKeysResponse struct {
+	Items []APIKey `json:"items"`
+}
+
+// ListAPIKeys retrieves the project's API keys, including revoked ones
+func (c *Client) ListAPIKeys(ctx context.Context, opts ...RequestOption) (*ListAPIKeysResponse, error) {
+	return do[ListAPIKeysResponse](ctx, c, "GET", "/api-keys", nil, opts...)
+}
+
+// CreateAPIKey creates an API key with the given scopes, e.g. to rotate credentials:
+// create the new key, roll it out, then RevokeAPIKey the old one. It requires the
+// ScopeKeysManage scope and may require step-up authentication.
+func (c *Client) CreateAPIKey(ctx context.Context, req CreateAPIKeyRequest, opts ...RequestOption) (*CreatedAPIKey, error) {
+	return do[CreatedAPIKey](ctx, c, "POST", "/api-keys", req, opts...)
+}
+
+// RevokeAPIKey revokes an API key; requests authenticated with it fail from then on
+func (c *Client) RevokeAPIKey(ctx context.Context, keyID string, opts ...RequestOption) error {
+	_, err := c.doRequest(ctx, "DELETE", "/api-keys/"+url.PathEscape(keyID), nil, opts...)
+	return err
+}
@@ -0,0 +1,29 @@
+package itispay
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrInvalidAutoConvert is returned when an invoice asks to auto-convert its payment into
+// the currency it is paid in
+var ErrInvalidAutoConvert = errors.New("itispay: invalid auto-convert currency")
+
+// validateAutoConvert checks the AutoConvertTo currency of a create request
+func validateAutoConvert(currency, autoConvertTo string) error {
+	if autoConvertTo != "" && strings.EqualFold(currency, autoConvertTo) {
+		return fmt.Errorf("%w: cannot convert %s to itself", ErrInvalidAutoConvert, currency)
+	}
+	return nil
+}
+
+// SettledAmount returns the amount and currency the merchant receives for the invoice:
+// the converted amount once an automatic conversion completed, otherwise the crypto
+// amount paid
+func (i *Invoice) SettledAmount() (float64, string) {
+	if i.Conversion != nil && i.Conversion.Status == ConversionCompleted {
+		return i.Conversion.ToAmount, i.Conversion.ToCurrency
+	}
+	return i.ActualCryptoAmountPaid, i.Currency
+}
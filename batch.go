@@ -0,0 +1,289 @@
+package itispay
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+)
+
+// Bulk operation defaults
+const (
+	// DefaultConcurrency is the number of parallel requests used by bulk operations
+	DefaultConcurrency = 8
+	// MaxBatchSize is the maximum number of invoices sent in one batch request
+	MaxBatchSize = 100
+)
+
+// ErrOutcomeUnknown is the error of a batch item that may or may not have been applied,
+// e.g. when the response of its chunk could not be read
+var ErrOutcomeUnknown = errors.New("itispay: outcome unknown")
+
+// BatchError reports a partially failed batch. The individual errors are in the
+// BatchResult.
+type BatchError struct {
+	Total  int
+	Failed int
+}
+
+// Error returns the error message
+func (e *BatchError) Error() string {
+	return fmt.Sprintf("itispay: %d of %d batch items failed", e.Failed, e.Total)
+}
+
+// WithConcurrency limits the number of parallel requests made by bulk operations such as
+// CreateInvoices and GetInvoices; the default is DefaultConcurrency
+func WithConcurrency(n int) RequestOption {
+	return func(o *requestOptions) {
+		o.concurrency = n
+	}
+}
+
+// batchInvoicesRequest is the body of the batch creation endpoint
+type batchInvoicesRequest struct {
+	Invoices []CreateInvoiceRequest `json:"invoices"`
+}
+
+// batchInvoicesResponse is the response of the batch creation endpoint, with one item per
+// request in the same order
+type batchInvoicesResponse struct {
+	Results []struct {
+		Invoice *Invoice  `json:"invoice,omitempty"`
+		Error   string    `json:"error,omitempty"`
+		Message string    `json:"message,omitempty"`
+		Code    ErrorCode `json:"code,omitempty"`
+		Status  int       `json:"status,omitempty"`
+	} `json:"results"`
+}
+
+// CreateInvoices creates many invoices at once, returning one result per request in the
+// same order. It uses the batch endpoint in chunks of MaxBatchSize, falling back to
+// parallel CreateInvoice calls (see WithConcurrency) when the endpoint is not available.
+// If some invoices fail, the error is a *BatchError and the other results are valid;
+// FailedItems returns the requests worth retrying. A chunk whose response cannot be
+// read fails with ErrOutcomeUnknown, as its invoices may have been created.
+//
+// With WithIdempotencyKey, each invoice gets the key suffixed with its order ID (or a
+// hash of the request if it has none), so the batch, or the FailedItems of the result,
+// can be retried safely with the same key.
+func (c *Client) CreateInvoices(ctx context.Context, reqs []CreateInvoiceRequest, opts ...RequestOption) (*BatchResult[CreateInvoiceRequest, *Invoice], error) {
+	if err := c.checkReadOnly("POST", createInvoiceEndpoint); err != nil {
+		return nil, err
+	}
+	// Without an idempotency key, repeating an invoice whose outcome is unknown may
+	// create it twice
+	idempotent := newRequestOptions(opts).headers.Get(IdempotencyKeyHeader) != ""
+	result := newBatchResult[CreateInvoiceRequest, *Invoice](reqs, idempotent)
+
+	pending := allIndexes(len(reqs))
+	var prepared map[int]CreateInvoiceRequest
+	if !c.batchUnsupported.Load() {
+		pending, prepared = c.createInvoicesBatched(ctx, reqs, result, opts)
+	}
+	c.createInvoicesParallel(ctx, reqs, prepared, result, pending, opts)
+
+	return result, result.Err()
+}
+
+// createInvoicesBatched creates invoices through the batch endpoint. If the endpoint
+// turns out to be unavailable, it returns the indexes left for parallel creation along
+// with the requests it already prepared, which must not be screened again.
+func (c *Client) createInvoicesBatched(ctx context.Context, reqs []CreateInvoiceRequest, result *BatchResult[CreateInvoiceRequest, *Invoice], opts []RequestOption) ([]int, map[int]CreateInvoiceRequest) {
+	options := newRequestOptions(opts)
+	key := options.headers.Get(IdempotencyKeyHeader)
+	dryRun := c.isDryRun("POST", options)
+
+	var created []int
+	sent := false
+	for start := 0; start < len(reqs); start += MaxBatchSize {
+		end := start + MaxBatchSize
+		if end > len(reqs) {
+			end = len(reqs)
+		}
+
+		// Requests rejected by screening, validation or precision enforcement are not sent
+		var body batchInvoicesRequest
+		var indexes []int
+		for i := start; i < end; i++ {
+			req, err := c.prepareInvoice(ctx, reqs[i])
+			if err != nil {
+				result.set(i, nil, err)
+				continue
+			}
+			body.Invoices = append(body.Invoices, req)
+			indexes = append(indexes, i)
+		}
+		if len(indexes) == 0 {
+			continue
+		}
+
+		chunkOpts := opts
+		if key != "" {
+			chunkOpts = append(opts[:len(opts):len(opts)], WithIdempotencyKey(chunkIdempotencyKey(key, reqs, indexes)))
+		}
+
+		resp, err := c.doRequest(ctx, "POST", "/invoices/batch", body, chunkOpts...)
+		if err != nil {
+			if statusCode, ok := errorStatusCode(err); !sent && ok && isBatchUnsupported(statusCode) {
+				c.batchUnsupported.Store(true)
+				prepared := make(map[int]CreateInvoiceRequest, len(indexes))
+				for j, i := range indexes {
+					prepared[i] = body.Invoices[j]
+				}
+				for i := end; i < len(reqs); i++ {
+					indexes = append(indexes, i)
+				}
+				return indexes, prepared
+			}
+			for _, i := range indexes {
+				result.set(i, nil, err)
+			}
+			sent = true
+			continue
+		}
+		sent = true
+
+		// The chunk was sent, so its invoices may exist even if the response is unusable
+		var response batchInvoicesResponse
+		if err := resp.decode(&response); err != nil {
+			for _, i := range indexes {
+				result.set(i, nil, fmt.Errorf("%w: failed to unmarshal batch invoices response: %w", ErrOutcomeUnknown, err))
+			}
+			continue
+		}
+		if len(response.Results) != len(indexes) {
+			for _, i := range indexes {
+				result.set(i, nil, fmt.Errorf("%w: batch invoices response has %d results for %d invoices", ErrOutcomeUnknown, len(response.Results), len(indexes)))
+			}
+			continue
+		}
+
+		for j, item := range response.Results {
+			i := indexes[j]
+			if item.Invoice == nil {
+				result.set(i, nil, NewAPIError(item.Status, ErrorResponse{Error: item.Error, Message: item.Message, Code: item.Code}))
+				continue
+			}
+			result.set(i, item.Invoice, nil)
+			created = append(created, i)
+		}
+	}
+
+	if !dryRun {
+		// As CreateInvoice does: record the rates and, with WithReadBack, wait for the
+		// invoices to be readable
+		runParallel(options.concurrency, len(created), func(n int) {
+			i := created[n]
+			if err := c.afterCreate(ctx, result.Items[i].Output, options, opts); err != nil {
+				result.set(i, result.Items[i].Output, err)
+			}
+		})
+	}
+	return nil, nil
+}
+
+// createInvoicesParallel creates the invoices at the given indexes with bounded
+// concurrency. Requests found in prepared were already screened and are sent as is.
+func (c *Client) createInvoicesParallel(ctx context.Context, reqs []CreateInvoiceRequest, prepared map[int]CreateInvoiceRequest, result *BatchResult[CreateInvoiceRequest, *Invoice], indexes []int, opts []RequestOption) {
+	options := newRequestOptions(opts)
+	key := options.headers.Get(IdempotencyKeyHeader)
+
+	runParallel(options.concurrency, len(indexes), func(n int) {
+		i := indexes[n]
+		itemOpts := opts
+		if key != "" {
+			itemOpts = append(opts[:len(opts):len(opts)], WithIdempotencyKey(itemIdempotencyKey(key, reqs[i])))
+		}
+		var invoice *Invoice
+		var err error
+		if req, ok := prepared[i]; ok {
+			invoice, _, err = c.createPreparedInvoice(ctx, req, itemOpts)
+		} else {
+			invoice, err = c.CreateInvoice(ctx, reqs[i], itemOpts...)
+		}
+		result.set(i, invoice, err)
+	})
+}
+
+// itemIdempotencyKey derives the idempotency key of one invoice of a batch from the
+// batch's key. It depends on the request rather than its position, which changes when
+// failed items are retried.
+func itemIdempotencyKey(key string, req CreateInvoiceRequest) string {
+	if req.OrderID != "" {
+		return key + "-" + req.OrderID
+	}
+	body, _ := json.Marshal(req)
+	sum := sha256.Sum256(body)
+	return key + "-" + hex.EncodeToString(sum[:8])
+}
+
+// chunkIdempotencyKey derives the idempotency key of a batch request from the keys of
+// the invoices it carries
+func chunkIdempotencyKey(key string, reqs []CreateInvoiceRequest, indexes []int) string {
+	h := sha256.New()
+	for _, i := range indexes {
+		h.Write([]byte(itemIdempotencyKey(key, reqs[i])))
+		h.Write([]byte{0})
+	}
+	return key + "-batch-" + hex.EncodeToString(h.Sum(nil)[:8])
+}
+
+// GetInvoices fetches many invoices in parallel (see WithConcurrency), e.g. for nightly
+// reconciliation, with one item per distinct ID. If some invoices could not be fetched,
+// the error is a *BatchError; FailedItems returns the IDs worth retrying.
+func (c *Client) GetInvoices(ctx context.Context, invoiceIDs []string, opts ...RequestOption) (*BatchResult[string, *Invoice], error) {
+	seen := make(map[string]bool, len(invoiceIDs))
+	ids := make([]string, 0, len(invoiceIDs))
+	for _, id := range invoiceIDs {
+		if !seen[id] {
+			seen[id] = true
+			ids = append(ids, id)
+		}
+	}
+
+	result := newBatchResult[string, *Invoice](ids, true)
+	runParallel(newRequestOptions(opts).concurrency, len(ids), func(i int) {
+		invoice, err := c.GetInvoice(ctx, ids[i], opts...)
+		result.set(i, invoice, err)
+	})
+	return result, result.Err()
+}
+
+// runParallel calls fn for 0..count-1 with at most concurrency calls in flight
+// (DefaultConcurrency if not positive) and waits for all of them
+func runParallel(concurrency, count int, fn func(i int)) {
+	if concurrency <= 0 {
+		concurrency = DefaultConcurrency
+	}
+	sem := make(chan struct{}, concurrency)
+
+	var wg sync.WaitGroup
+	for i := 0; i < count; i++ {
+		sem <- struct{}{}
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			fn(i)
+		}(i)
+	}
+	wg.Wait()
+}
+
+// isBatchUnsupported reports whether status means the batch endpoint does not exist
+func isBatchUnsupported(status int) bool {
+	return status == http.StatusNotFound || status == http.StatusMethodNotAllowed || status == http.StatusNotImplemented
+}
+
+// allIndexes returns 0..n-1
+func allIndexes(n int) []int {
+	indexes := make([]int, n)
+	for i := range indexes {
+		indexes[i] = i
+	}
+	return indexes
+}
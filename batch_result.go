@@ -0,0 +1,146 @@
+package itispay
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+)
+
+// ItemStatus is the outcome of one item of a bulk operation
+type ItemStatus string
+
+// Item status constants
+const (
+	ItemSucceeded ItemStatus = "succeeded"
+	ItemFailed    ItemStatus = "failed"
+)
+
+// BatchItem is the outcome of one item of a bulk operation
+type BatchItem[In, Out any] struct {
+	// Index is the position of the item in the bulk call
+	Index  int
+	Input  In
+	Output Out
+	Status ItemStatus
+	Err    error
+	// Retryable reports whether repeating the item may succeed without side effects,
+	// e.g. after rate limiting, as opposed to a rejected request. A server error,
+	// network error or timeout is retryable only if repeating cannot apply the item
+	// twice, e.g. reads or creation with WithIdempotencyKey.
+	Retryable bool
+}
+
+// BatchResult holds the per-item outcomes of a bulk operation taking In items and
+// producing Out results, in input order
+type BatchResult[In, Out any] struct {
+	Items []BatchItem[In, Out]
+
+	// idempotent is set when repeating an item cannot apply it twice
+	idempotent bool
+}
+
+// newBatchResult returns a result with one pending item per input. idempotent tells
+// whether items failing with an unknown outcome, e.g. on a timeout, may be repeated.
+func newBatchResult[In, Out any](inputs []In, idempotent bool) *BatchResult[In, Out] {
+	items := make([]BatchItem[In, Out], len(inputs))
+	for i, input := range inputs {
+		items[i] = BatchItem[In, Out]{Index: i, Input: input}
+	}
+	return &BatchResult[In, Out]{Items: items, idempotent: idempotent}
+}
+
+// set records the outcome of item i
+func (r *BatchResult[In, Out]) set(i int, output Out, err error) {
+	item := &r.Items[i]
+	item.Output, item.Err = output, err
+	if err != nil {
+		item.Status = ItemFailed
+		item.Retryable = isRetryable(err, r.idempotent)
+		return
+	}
+	item.Status = ItemSucceeded
+	item.Retryable = false
+}
+
+// Succeeded returns the outputs of the successful items, in input order
+func (r *BatchResult[In, Out]) Succeeded() []Out {
+	var outputs []Out
+	for _, item := range r.Items {
+		if item.Status == ItemSucceeded {
+			outputs = append(outputs, item.Output)
+		}
+	}
+	return outputs
+}
+
+// Failed returns the failed items
+func (r *BatchResult[In, Out]) Failed() []BatchItem[In, Out] {
+	var failed []BatchItem[In, Out]
+	for _, item := range r.Items {
+		if item.Status == ItemFailed {
+			failed = append(failed, item)
+		}
+	}
+	return failed
+}
+
+// FailedItems returns the inputs of the retryable failed items, ready to be passed to
+// the same bulk call again
+func (r *BatchResult[In, Out]) FailedItems() []In {
+	var inputs []In
+	for _, item := range r.Items {
+		if item.Status == ItemFailed && item.Retryable {
+			inputs = append(inputs, item.Input)
+		}
+	}
+	return inputs
+}
+
+// Err returns a *BatchError if any item failed, nil otherwise
+func (r *BatchResult[In, Out]) Err() error {
+	failed := 0
+	for _, item := range r.Items {
+		if item.Status == ItemFailed {
+			failed++
+		}
+	}
+	if failed == 0 {
+		return nil
+	}
+	return &BatchError{Total: len(r.Items), Failed: failed}
+}
+
+// isRetryable reports whether an operation failing with err may succeed when repeated.
+// Only failures known to be transient qualify: requests the client held back and rate
+// limiting, and, if idempotent is set, server errors, network errors, timeouts and
+// unknown outcomes, since the request may have been applied before the failure.
+func isRetryable(err error, idempotent bool) bool {
+	if errors.Is(err, ErrOutcomeUnknown) {
+		return idempotent
+	}
+	if errors.Is(err, ErrCircuitOpen) || errors.Is(err, ErrConcurrencyLimit) {
+		return true
+	}
+
+	if statusCode, ok := errorStatusCode(err); ok {
+		switch {
+		case statusCode == http.StatusRequestTimeout || statusCode == http.StatusTooManyRequests:
+			return true
+		case statusCode >= 500:
+			// The server may have failed after applying the request
+			return idempotent
+		}
+		return false
+	}
+
+	if errors.Is(err, context.Canceled) {
+		return false
+	}
+	// *url.Error and context.DeadlineExceeded are net.Errors
+	var netErr net.Error
+	if errors.As(err, &netErr) {
+		return idempotent
+	}
+	return false
+}
@@ -0,0 +1,263 @@
+package itispay_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	itispay "github.com/ItIsPay/go-client"
+	"github.com/ItIsPay/go-client/itispaytest"
+)
+
+// batchServer is an API implementing the batch creation endpoint. Invoices are named
+// after their order IDs.
+type batchServer struct {
+	mu     sync.Mutex
+	chunks []int
+	// badChunk, if positive, is the 1-based chunk answered with badBody
+	badChunk int
+	badBody  string
+
+	reads atomic.Int32
+	// keys are the idempotency keys of the batch requests
+	keys []string
+}
+
+func (s *batchServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	switch {
+	case r.Method == http.MethodPost && r.URL.Path == "/invoices/batch":
+		var body struct {
+			Invoices []itispay.CreateInvoiceRequest `json:"invoices"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		s.mu.Lock()
+		s.chunks = append(s.chunks, len(body.Invoices))
+		s.keys = append(s.keys, r.Header.Get(itispay.IdempotencyKeyHeader))
+		chunk := len(s.chunks)
+		s.mu.Unlock()
+		if chunk == s.badChunk {
+			fmt.Fprint(w, s.badBody)
+			return
+		}
+
+		type result struct {
+			Invoice *itispay.Invoice `json:"invoice"`
+		}
+		results := make([]result, len(body.Invoices))
+		for i, req := range body.Invoices {
+			results[i].Invoice = &itispay.Invoice{InvoiceID: "inv-" + req.OrderID, OrderID: req.OrderID}
+		}
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"results": results})
+	case r.Method == http.MethodGet && strings.HasPrefix(r.URL.Path, "/invoices/"):
+		s.reads.Add(1)
+		id := strings.TrimPrefix(r.URL.Path, "/invoices/")
+		_ = json.NewEncoder(w).Encode(&itispay.Invoice{InvoiceID: id, OrderID: strings.TrimPrefix(id, "inv-")})
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+func (s *batchServer) client(t *testing.T, opts ...itispay.Option) *itispay.Client {
+	t.Helper()
+	srv := httptest.NewServer(s)
+	t.Cleanup(srv.Close)
+	return itispay.NewClient(itispaytest.APIKey, append([]itispay.Option{itispay.WithBaseURL(srv.URL)}, opts...)...)
+}
+
+// invoiceRequests returns n valid requests for orders ORDER-0 to ORDER-<n-1>
+func invoiceRequests(n int) []itispay.CreateInvoiceRequest {
+	reqs := make([]itispay.CreateInvoiceRequest, n)
+	for i := range reqs {
+		amount := 10.0
+		reqs[i] = itispay.CreateInvoiceRequest{
+			OrderID:      fmt.Sprintf("ORDER-%d", i),
+			FiatAmount:   &amount,
+			FiatCurrency: "EUR",
+			Currency:     "BTC",
+		}
+	}
+	return reqs
+}
+
+func TestCreateInvoicesChunks(t *testing.T) {
+	s := &batchServer{}
+	reqs := invoiceRequests(250)
+
+	result, err := s.client(t).CreateInvoices(context.Background(), reqs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []int{100, 100, 50}; fmt.Sprint(s.chunks) != fmt.Sprint(want) {
+		t.Errorf("chunks = %v, want %v", s.chunks, want)
+	}
+	for i, item := range result.Items {
+		if item.Status != itispay.ItemSucceeded || item.Output.OrderID != reqs[i].OrderID {
+			t.Fatalf("item %d = %s %+v, want invoice of %s", i, item.Status, item.Output, reqs[i].OrderID)
+		}
+	}
+}
+
+func TestCreateInvoicesUnreadableChunk(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+	}{
+		{name: "malformed response", body: `{"results": [`},
+		{name: "wrong number of results", body: `{"results": [{"invoice": {"invoice_id": "inv-1"}}]}`},
+	}
+
+	for _, tt := range tests {
+		for _, key := range []string{"", "batch-1"} {
+			t.Run(fmt.Sprintf("%s/key=%q", tt.name, key), func(t *testing.T) {
+				s := &batchServer{badChunk: 2, badBody: tt.body}
+				var opts []itispay.RequestOption
+				if key != "" {
+					opts = append(opts, itispay.WithIdempotencyKey(key))
+				}
+
+				result, err := s.client(t).CreateInvoices(context.Background(), invoiceRequests(250), opts...)
+				var batchErr *itispay.BatchError
+				if !errors.As(err, &batchErr) || batchErr.Failed != 100 {
+					t.Fatalf("err = %v, want 100 failed items", err)
+				}
+				if result == nil {
+					t.Fatal("no result returned")
+				}
+				// The other chunks' invoices were created and are not lost
+				for i, item := range result.Items {
+					inBadChunk := i >= 100 && i < 200
+					if !inBadChunk {
+						if item.Status != itispay.ItemSucceeded {
+							t.Fatalf("item %d: %s, %v", i, item.Status, item.Err)
+						}
+						continue
+					}
+					if item.Status != itispay.ItemFailed || !errors.Is(item.Err, itispay.ErrOutcomeUnknown) {
+						t.Fatalf("item %d: %s, %v, want ErrOutcomeUnknown", i, item.Status, item.Err)
+					}
+					// Repeating may create the invoice twice unless it is idempotent
+					if item.Retryable != (key != "") {
+						t.Fatalf("item %d: retryable = %t with key %q", i, item.Retryable, key)
+					}
+				}
+			})
+		}
+	}
+}
+
+func TestCreateInvoicesFallbackScreensOnce(t *testing.T) {
+	// The fake API has no batch endpoint
+	srv := itispaytest.NewServer()
+	defer srv.Close()
+	var screened atomic.Int32
+	client := srv.Client(itispay.WithPreCreateHook(itispay.PreCreateHookFunc(func(ctx context.Context, req *itispay.CreateInvoiceRequest) error {
+		screened.Add(1)
+		if req.OrderID == "ORDER-1" {
+			return itispay.Reject("denied")
+		}
+		return nil
+	})))
+
+	result, err := client.CreateInvoices(context.Background(), invoiceRequests(3))
+	var batchErr *itispay.BatchError
+	if !errors.As(err, &batchErr) || batchErr.Failed != 1 {
+		t.Fatalf("err = %v, want the rejected invoice to fail", err)
+	}
+	if got := screened.Load(); got != 3 {
+		t.Errorf("hooks ran %d times for 3 invoices", got)
+	}
+	for i, item := range result.Items {
+		if i != 1 && (item.Status != itispay.ItemSucceeded || item.Output == nil) {
+			t.Errorf("item %d: %s, %v", i, item.Status, item.Err)
+		}
+	}
+}
+
+func TestCreateInvoicesReadBack(t *testing.T) {
+	s := &batchServer{}
+	result, err := s.client(t).CreateInvoices(context.Background(), invoiceRequests(3), itispay.WithReadBack())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Succeeded()) != 3 {
+		t.Fatalf("%d invoices created, want 3", len(result.Succeeded()))
+	}
+	if got := s.reads.Load(); got != 3 {
+		t.Errorf("%d invoices read back, want 3", got)
+	}
+}
+
+func TestCreateInvoicesRetryKeepsKeys(t *testing.T) {
+	s := &batchServer{badChunk: 1, badBody: `{"results": [`}
+	client := s.client(t)
+	ctx := context.Background()
+
+	result, _ := client.CreateInvoices(ctx, invoiceRequests(3), itispay.WithIdempotencyKey("batch-1"))
+	retry := result.FailedItems()
+	if len(retry) != 3 {
+		t.Fatalf("%d items to retry, want 3", len(retry))
+	}
+	if _, err := client.CreateInvoices(ctx, retry, itispay.WithIdempotencyKey("batch-1")); err != nil {
+		t.Fatal(err)
+	}
+	if len(s.keys) != 2 || s.keys[0] == "" || s.keys[0] != s.keys[1] {
+		t.Errorf("batch keys = %q, want the retry to reuse the key", s.keys)
+	}
+}
+
+func TestCreateInvoicesServerErrorRetryable(t *testing.T) {
+	for _, key := range []string{"", "batch-1"} {
+		t.Run(fmt.Sprintf("key=%q", key), func(t *testing.T) {
+			srv := itispaytest.NewServer()
+			defer srv.Close()
+			// Invoices are created one by one and fail
+			srv.SetDown("POST /invoices/batch", http.StatusNotFound)
+			srv.SetDown("POST /invoices", http.StatusServiceUnavailable)
+			var keys []string
+			var mu sync.Mutex
+			client := srv.Client(itispay.WithInterceptor(func(next itispay.RoundTripFunc) itispay.RoundTripFunc {
+				return func(req *http.Request) (*http.Response, error) {
+					if req.URL.Path == "/invoices" {
+						mu.Lock()
+						keys = append(keys, req.Header.Get(itispay.IdempotencyKeyHeader))
+						mu.Unlock()
+					}
+					return next(req)
+				}
+			}))
+			var opts []itispay.RequestOption
+			if key != "" {
+				opts = append(opts, itispay.WithIdempotencyKey(key))
+			}
+
+			result, _ := client.CreateInvoices(context.Background(), invoiceRequests(2), opts...)
+			for i, item := range result.Items {
+				// The server may have created the invoice before failing
+				if item.Status != itispay.ItemFailed || item.Retryable != (key != "") {
+					t.Errorf("item %d: %s, retryable = %t", i, item.Status, item.Retryable)
+				}
+			}
+			if key != "" {
+				if len(keys) != 2 {
+					t.Fatalf("invoice keys = %q, want one per invoice", keys)
+				}
+				for _, k := range keys {
+					if k != key+"-ORDER-0" && k != key+"-ORDER-1" {
+						t.Errorf("invoice key %q not derived from its order ID", k)
+					}
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,226 @@
+package itispay
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// ErrCircuitOpen is returned without sending the request while the circuit breaker is
+// open, i.e. the API failed repeatedly and is given time to recover
+var ErrCircuitOpen = errors.New("itispay: circuit breaker is open")
+
+// Circuit breaker defaults
+const (
+	DefaultBreakerFailureThreshold = 5
+	DefaultBreakerOpenDuration     = 30 * time.Second
+	DefaultBreakerHalfOpenProbes   = 1
+)
+
+// BreakerState is the state of the circuit breaker
+type BreakerState int
+
+// Circuit breaker states
+const (
+	// BreakerClosed lets every request through
+	BreakerClosed BreakerState = iota
+	// BreakerOpen fails every request with ErrCircuitOpen
+	BreakerOpen
+	// BreakerHalfOpen lets a limited number of probe requests through to test recovery
+	BreakerHalfOpen
+)
+
+// String returns the state name
+func (s BreakerState) String() string {
+	switch s {
+	case BreakerClosed:
+		return "closed"
+	case BreakerOpen:
+		return "open"
+	case BreakerHalfOpen:
+		return "half-open"
+	default:
+		return fmt.Sprintf("BreakerState(%d)", int(s))
+	}
+}
+
+// CircuitBreakerConfig configures WithCircuitBreaker
+type CircuitBreakerConfig struct {
+	// FailureThreshold is the number of consecutive failures that opens the circuit,
+	// DefaultBreakerFailureThreshold if zero
+	FailureThreshold int
+	// OpenDuration is how long the circuit stays open before probing,
+	// DefaultBreakerOpenDuration if zero
+	OpenDuration time.Duration
+	// HalfOpenProbes is the number of probe requests let through when half-open; the
+	// circuit closes once they all succeed. DefaultBreakerHalfOpenProbes if zero.
+	HalfOpenProbes int
+	// OnStateChange, if set, is called on every state transition
+	OnStateChange func(from, to BreakerState)
+}
+
+// WithCircuitBreaker makes the client fail fast with ErrCircuitOpen once the API has
+// failed FailureThreshold times in a row, instead of letting every call wait for its
+// timeout while the API is down. Network errors and 5xx responses count as failures;
+// calls cancelled by their context do not. After OpenDuration, probe requests test
+// whether the API has recovered.
+func WithCircuitBreaker(config CircuitBreakerConfig) Option {
+	return func(c *Client) {
+		if config.FailureThreshold <= 0 {
+			config.FailureThreshold = DefaultBreakerFailureThreshold
+		}
+		if config.OpenDuration <= 0 {
+			config.OpenDuration = DefaultBreakerOpenDuration
+		}
+		if config.HalfOpenProbes <= 0 {
+			config.HalfOpenProbes = DefaultBreakerHalfOpenProbes
+		}
+		c.breaker = &circuitBreaker{config: config}
+	}
+}
+
+// BreakerState returns the state of the circuit breaker, BreakerClosed if the client
+// has none
+func (c *Client) BreakerState() BreakerState {
+	if c.breaker == nil {
+		return BreakerClosed
+	}
+	return c.breaker.currentState()
+}
+
+// circuitBreaker tracks consecutive failures of requests to the API
+type circuitBreaker struct {
+	config CircuitBreakerConfig
+	clock  Clock
+
+	mu       sync.Mutex
+	state    BreakerState
+	failures int
+	openedAt time.Time
+	// generation counts state changes, telling probes of different half-open periods apart
+	generation uint64
+	// probes is the number of probes in flight, successes the number that succeeded
+	probes    int
+	successes int
+}
+
+// currentState returns the state, moving from open to half-open once OpenDuration passed
+func (b *circuitBreaker) currentState() BreakerState {
+	b.mu.Lock()
+	notify := b.expire(b.clock.Now())
+	state := b.state
+	b.mu.Unlock()
+
+	if notify != nil {
+		notify()
+	}
+	return state
+}
+
+// allow reports whether a request may be sent. Probes get a non-zero token identifying
+// the half-open period they belong to, to be passed to record.
+func (b *circuitBreaker) allow() (probe uint64, err error) {
+	b.mu.Lock()
+	now := b.clock.Now()
+	notify := b.expire(now)
+	switch b.state {
+	case BreakerOpen:
+		retryIn := b.openedAt.Add(b.config.OpenDuration).Sub(now).Round(time.Second)
+		err = fmt.Errorf("%w, retry in %s", ErrCircuitOpen, retryIn)
+	case BreakerHalfOpen:
+		if b.probes+b.successes >= b.config.HalfOpenProbes {
+			err = fmt.Errorf("%w, probing recovery", ErrCircuitOpen)
+		} else {
+			b.probes++
+			probe = b.generation
+		}
+	}
+	b.mu.Unlock()
+
+	if notify != nil {
+		notify()
+	}
+	return probe, err
+}
+
+// record updates the breaker with the outcome of a request; statusCode is 0 if the
+// request failed without a response
+func (b *circuitBreaker) record(ctx context.Context, probe uint64, statusCode int) {
+	var notify func()
+	b.mu.Lock()
+	if probe != 0 {
+		if probe != b.generation {
+			// A probe of an earlier half-open period, already decided
+			b.mu.Unlock()
+			return
+		}
+		b.probes--
+	}
+	switch {
+	case ctx.Err() != nil:
+		// Cancelled by the caller, which says nothing about the API
+	case statusCode == 0 || statusCode >= 500:
+		if probe != 0 {
+			notify = b.open()
+		} else if b.state == BreakerClosed {
+			b.failures++
+			if b.failures >= b.config.FailureThreshold {
+				notify = b.open()
+			}
+		}
+	case probe != 0:
+		b.successes++
+		if b.successes >= b.config.HalfOpenProbes {
+			notify = b.setState(BreakerClosed)
+		}
+	case b.state == BreakerClosed:
+		b.failures = 0
+	}
+	b.mu.Unlock()
+
+	if notify != nil {
+		notify()
+	}
+}
+
+// release gives back the probe slot taken by allow for a request that was not sent
+func (b *circuitBreaker) release(probe uint64) {
+	if probe == 0 {
+		return
+	}
+	b.mu.Lock()
+	if probe == b.generation {
+		b.probes--
+	}
+	b.mu.Unlock()
+}
+
+// open opens the circuit; b.mu must be held
+func (b *circuitBreaker) open() func() {
+	b.openedAt = b.clock.Now()
+	return b.setState(BreakerOpen)
+}
+
+// expire moves an open breaker to half-open once OpenDuration passed; b.mu must be held
+func (b *circuitBreaker) expire(now time.Time) func() {
+	if b.state != BreakerOpen || now.Before(b.openedAt.Add(b.config.OpenDuration)) {
+		return nil
+	}
+	return b.setState(BreakerHalfOpen)
+}
+
+// setState changes the state and resets the counters; b.mu must be held. It returns
+// the OnStateChange notification to run after unlocking, so the callback may query the
+// client, or nil.
+func (b *circuitBreaker) setState(to BreakerState) func() {
+	from := b.state
+	b.state = to
+	b.generation++
+	b.failures, b.probes, b.successes = 0, 0, 0
+	if b.config.OnStateChange == nil {
+		return nil
+	}
+	return func() { b.config.OnStateChange(from, to) }
+}
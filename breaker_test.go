@@ -0,0 +1,272 @@
+package itispay_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	itispay "github.com/ItIsPay/go-client"
+	"github.com/ItIsPay/go-client/itispaytest"
+)
+
+func TestCircuitBreakerOpens(t *testing.T) {
+	tests := []struct {
+		name   string
+		fault  func(srv *itispaytest.Server)
+		calls  int
+		want   itispay.BreakerState
+		faults int
+	}{
+		{
+			name:   "server errors open the circuit",
+			fault:  func(srv *itispaytest.Server) { srv.SetErrorRate("/rates", http.StatusServiceUnavailable, 1) },
+			calls:  5,
+			want:   itispay.BreakerOpen,
+			faults: 3,
+		},
+		{
+			name:   "dropped connections open the circuit",
+			fault:  func(srv *itispaytest.Server) { srv.SetDropRate("/rates", 1) },
+			calls:  5,
+			want:   itispay.BreakerOpen,
+			faults: 3,
+		},
+		{
+			name:   "client errors do not count",
+			fault:  func(srv *itispaytest.Server) { srv.SetErrorRate("/rates", http.StatusBadRequest, 1) },
+			calls:  5,
+			want:   itispay.BreakerClosed,
+			faults: 5,
+		},
+		{
+			name:   "rate limiting does not count",
+			fault:  func(srv *itispaytest.Server) { srv.RateLimitStorm("/rates", time.Minute, time.Second) },
+			calls:  5,
+			want:   itispay.BreakerClosed,
+			faults: 5,
+		},
+		{
+			name:   "failures below the threshold keep it closed",
+			fault:  func(srv *itispaytest.Server) { srv.SetErrorRate("/rates", http.StatusInternalServerError, 1) },
+			calls:  2,
+			want:   itispay.BreakerClosed,
+			faults: 2,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := itispaytest.NewServer()
+			defer srv.Close()
+			tt.fault(srv)
+			client := srv.Client(itispay.WithCircuitBreaker(itispay.CircuitBreakerConfig{FailureThreshold: 3}))
+
+			var lastErr error
+			for i := 0; i < tt.calls; i++ {
+				_, lastErr = client.GetRates(context.Background())
+			}
+			if got := client.BreakerState(); got != tt.want {
+				t.Errorf("state = %s, want %s", got, tt.want)
+			}
+			if got := srv.InjectedFaults(); got != tt.faults {
+				t.Errorf("server saw %d failing requests, want %d", got, tt.faults)
+			}
+			if tt.want == itispay.BreakerOpen && !errors.Is(lastErr, itispay.ErrCircuitOpen) {
+				t.Errorf("err = %v, want ErrCircuitOpen", lastErr)
+			}
+		})
+	}
+}
+
+func TestCircuitBreakerSuccessResetsFailures(t *testing.T) {
+	srv := itispaytest.NewServer()
+	defer srv.Close()
+	client := srv.Client(itispay.WithCircuitBreaker(itispay.CircuitBreakerConfig{FailureThreshold: 2}))
+	ctx := context.Background()
+
+	srv.SetErrorRate("/rates", http.StatusServiceUnavailable, 1)
+	client.GetRates(ctx)
+	srv.Reset()
+	if _, err := client.GetRates(ctx); err != nil {
+		t.Fatal(err)
+	}
+	srv.SetErrorRate("/rates", http.StatusServiceUnavailable, 1)
+	client.GetRates(ctx)
+
+	if got := client.BreakerState(); got != itispay.BreakerClosed {
+		t.Errorf("state = %s, want closed: failures must be consecutive", got)
+	}
+}
+
+func TestCircuitBreakerHalfOpen(t *testing.T) {
+	tests := []struct {
+		name    string
+		healthy bool
+		want    itispay.BreakerState
+	}{
+		{name: "successful probe closes the circuit", healthy: true, want: itispay.BreakerClosed},
+		{name: "failed probe reopens the circuit", healthy: false, want: itispay.BreakerOpen},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := itispaytest.NewServer()
+			defer srv.Close()
+			clock := itispaytest.NewFakeClock(time.Now())
+			var transitions []string
+			client := srv.Client(itispay.WithClock(clock), itispay.WithCircuitBreaker(itispay.CircuitBreakerConfig{
+				FailureThreshold: 1,
+				OpenDuration:     30 * time.Second,
+				OnStateChange: func(from, to itispay.BreakerState) {
+					transitions = append(transitions, from.String()+">"+to.String())
+				},
+			}))
+			ctx := context.Background()
+
+			srv.SetDown("/rates", http.StatusServiceUnavailable)
+			client.GetRates(ctx)
+			clock.Advance(29 * time.Second)
+			if got := client.BreakerState(); got != itispay.BreakerOpen {
+				t.Fatalf("state before OpenDuration = %s, want open", got)
+			}
+			clock.Advance(time.Second)
+			if got := client.BreakerState(); got != itispay.BreakerHalfOpen {
+				t.Fatalf("state after OpenDuration = %s, want half-open", got)
+			}
+
+			if tt.healthy {
+				srv.SetHealthy("/rates")
+			}
+			client.GetRates(ctx)
+			if got := client.BreakerState(); got != tt.want {
+				t.Errorf("state after probe = %s, want %s (transitions %v)", got, tt.want, transitions)
+			}
+		})
+	}
+}
+
+// gate holds requests to one path until released, reporting when they arrive
+type gate struct {
+	path    string
+	arrived chan struct{}
+	release chan struct{}
+}
+
+func newGate(path string) *gate {
+	return &gate{path: path, arrived: make(chan struct{}, 10), release: make(chan struct{})}
+}
+
+func (g *gate) intercept(next itispay.RoundTripFunc) itispay.RoundTripFunc {
+	return func(req *http.Request) (*http.Response, error) {
+		if req.URL.Path == g.path {
+			g.arrived <- struct{}{}
+			<-g.release
+		}
+		return next(req)
+	}
+}
+
+func TestCircuitBreakerLimitsProbes(t *testing.T) {
+	srv := itispaytest.NewServer()
+	defer srv.Close()
+	clock := itispaytest.NewFakeClock(time.Now())
+	held := newGate("/currencies")
+	client := srv.Client(
+		itispay.WithClock(clock),
+		itispay.WithInterceptor(held.intercept),
+		itispay.WithCircuitBreaker(itispay.CircuitBreakerConfig{FailureThreshold: 1, HalfOpenProbes: 1}),
+	)
+	ctx := context.Background()
+
+	srv.SetDown("/rates", http.StatusServiceUnavailable)
+	client.GetRates(ctx)
+	srv.SetHealthy("/rates")
+	clock.Advance(itispay.DefaultBreakerOpenDuration)
+
+	done := make(chan error)
+	go func() {
+		_, err := client.GetCurrencies(ctx)
+		done <- err
+	}()
+	<-held.arrived
+
+	if _, err := client.GetRates(ctx); !errors.Is(err, itispay.ErrCircuitOpen) {
+		t.Errorf("second request while probing: err = %v, want ErrCircuitOpen", err)
+	}
+	close(held.release)
+	if err := <-done; err != nil {
+		t.Fatalf("probe failed: %v", err)
+	}
+	if got := client.BreakerState(); got != itispay.BreakerClosed {
+		t.Errorf("state = %s, want closed", got)
+	}
+}
+
+func TestCircuitBreakerIgnoresStaleProbes(t *testing.T) {
+	srv := itispaytest.NewServer()
+	defer srv.Close()
+	clock := itispaytest.NewFakeClock(time.Now())
+	held := newGate("/currencies")
+	client := srv.Client(
+		itispay.WithClock(clock),
+		itispay.WithInterceptor(held.intercept),
+		itispay.WithCircuitBreaker(itispay.CircuitBreakerConfig{FailureThreshold: 1, HalfOpenProbes: 2}),
+	)
+	ctx := context.Background()
+
+	srv.SetDown("/rates", http.StatusServiceUnavailable)
+	client.GetRates(ctx)
+	clock.Advance(itispay.DefaultBreakerOpenDuration)
+
+	// A slow probe of the first half-open period...
+	done := make(chan error)
+	go func() {
+		_, err := client.GetCurrencies(ctx)
+		done <- err
+	}()
+	<-held.arrived
+	// ...outlives the period: a second probe fails and reopens the circuit
+	client.GetRates(ctx)
+	if got := client.BreakerState(); got != itispay.BreakerOpen {
+		t.Fatalf("state after failed probe = %s, want open", got)
+	}
+
+	// In the next period, one of the two probes succeeds...
+	srv.SetHealthy("/rates")
+	clock.Advance(itispay.DefaultBreakerOpenDuration)
+	client.GetRates(ctx)
+	if got := client.BreakerState(); got != itispay.BreakerHalfOpen {
+		t.Fatalf("state after 1 of 2 probes = %s, want half-open", got)
+	}
+
+	// ...and the stale probe finishing now must not count as the second one
+	close(held.release)
+	if err := <-done; err != nil {
+		t.Fatalf("slow probe failed: %v", err)
+	}
+	if got := client.BreakerState(); got != itispay.BreakerHalfOpen {
+		t.Errorf("state after stale probe succeeded = %s, want half-open", got)
+	}
+	client.GetRates(ctx)
+	if got := client.BreakerState(); got != itispay.BreakerClosed {
+		t.Errorf("state after 2 of 2 probes = %s, want closed", got)
+	}
+}
+
+func TestCircuitBreakerIgnoresCancelledCalls(t *testing.T) {
+	srv := itispaytest.NewServer()
+	defer srv.Close()
+	srv.SetLatency("/rates", itispaytest.FixedLatency(time.Second))
+	client := srv.Client(itispay.WithCircuitBreaker(itispay.CircuitBreakerConfig{FailureThreshold: 1}))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if _, err := client.GetRates(ctx); err == nil {
+		t.Fatal("expected the call to be cancelled")
+	}
+	if got := client.BreakerState(); got != itispay.BreakerClosed {
+		t.Errorf("state = %s, want closed", got)
+	}
+}
@@ -0,0 +1,55 @@
+package itispay
+
+import (
+	"errors"
+	"net/url"
+)
+
+// ErrNoCheckoutURL is returned when an invoice has no hosted checkout page
+var ErrNoCheckoutURL = errors.New("itispay: invoice has no checkout URL")
+
+// Checkout theme constants
+const (
+	CheckoutThemeLight = "light"
+	CheckoutThemeDark  = "dark"
+	CheckoutThemeAuto  = "auto"
+)
+
+// CheckoutOptions customizes the hosted checkout page
+type CheckoutOptions struct {
+	// Locale is the page language as a BCP 47 tag, e.g. "de" or "pt-BR"; the buyer's
+	// browser language if empty
+	Locale string
+	// Theme is one of the CheckoutTheme constants; the platform default if empty
+	Theme string
+	// ReturnURL is where the buyer is sent after paying or leaving the page, if set
+	ReturnURL string
+}
+
+// CheckoutLink returns the hosted checkout URL of the invoice with opts applied, ready to
+// redirect the buyer to instead of building a payment UI:
+//
+//	link, err := invoice.CheckoutLink(itispay.CheckoutOptions{Locale: "de", Theme: itispay.CheckoutThemeDark})
+//	http.Redirect(w, r, link, http.StatusSeeOther)
+func (i *Invoice) CheckoutLink(opts CheckoutOptions) (string, error) {
+	if i.CheckoutURL == "" {
+		return "", ErrNoCheckoutURL
+	}
+	u, err := url.Parse(i.CheckoutURL)
+	if err != nil {
+		return "", err
+	}
+
+	query := u.Query()
+	if opts.Locale != "" {
+		query.Set("locale", opts.Locale)
+	}
+	if opts.Theme != "" {
+		query.Set("theme", opts.Theme)
+	}
+	if opts.ReturnURL != "" {
+		query.Set("return_url", opts.ReturnURL)
+	}
+	u.RawQuery = query.Encode()
+	return u.String(), nil
+}
@@ -3,11 +3,12 @@ package itispay
 import (
 	"bytes"
 	"context"
-	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
 	"net/url"
+	"sync/atomic"
 	"time"
 )
 
@@ -20,51 +21,290 @@ const (
 
 // Client represents an ItIsPay API client
 type Client struct {
-	baseURL    string
-	apiKey     string
-	httpClient *http.Client
+	baseURL       string
+	environment   Environment
+	apiKey        string
+	httpClient    *http.Client
+	interceptors  []Interceptor
+	metrics       Metrics
+	traceID       func(ctx context.Context) string
+	rateRecorder  *RateRecorder
+	debug         *debugDumper
+	journal       *journal
+	breaker       *circuitBreaker
+	limiter       *concurrencyLimiter
+	failover      *failover
+	conditional   *conditionalCache
+	responseCache *responseCache
+	precision     precisionPolicy
+	credentials   CredentialsProvider
+	signingSecret []byte
+	clock         Clock
+	codec         Codec
+	// acceptLanguage is the default Accept-Language header, set by WithLanguage
+	acceptLanguage string
+
+	strictDecoding bool
+	readOnly       bool
+	dryRun         bool
+	preCreateHooks []PreCreateHook
+	// configErr is a configuration error returned by every request
+	configErr error
+
+	onDeprecation func(DeprecationWarning)
+
+	transportConfig  transportConfig
+	limiterConfig    limiterConfig
+	compression      compression
+	currencyCache    currencyCache
+	sessions         sessionCache
+	batchUnsupported atomic.Bool
 }
 
 // NewClient creates a new ItIsPay API client
-func NewClient(apiKey string) *Client {
-	return &Client{
-		baseURL: DefaultBaseURL,
-		apiKey:  apiKey,
+func NewClient(apiKey string, opts ...Option) *Client {
+	c := newClient(apiKey, opts)
+	c.configureTransport()
+	c.limiter = newConcurrencyLimiter(c.limiterConfig)
+	return c
+}
+
+// newClient returns a client with opts applied, without its transport and limiter
+func newClient(apiKey string, opts []Option) *Client {
+	c := &Client{
+		environment: EnvProduction,
+		apiKey:      apiKey,
 		httpClient: &http.Client{
 			Timeout: DefaultTimeout,
 		},
+		metrics: noopMetrics{},
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	c.resolveEnvironment()
+	c.clock = clockOrSystem(c.clock)
+	if c.codec == nil {
+		c.codec = JSONCodec{}
+	}
+	if c.breaker != nil {
+		c.breaker.clock = c.clock
 	}
+	if c.failover != nil {
+		c.failover.clock = c.clock
+	}
+	if c.journal != nil {
+		c.journal.basePaths = c.basePaths()
+	}
+	return c
 }
 
 // doRequest performs an HTTP request and unmarshals the response
-func (c *Client) doRequest(ctx context.Context, method, path string, body interface{}) ([]byte, error) {
-	var reqBody io.Reader
+func (c *Client) doRequest(ctx context.Context, method, path string, body interface{}, opts ...RequestOption) (*apiResponse, error) {
+	if c.configErr != nil {
+		return nil, c.configErr
+	}
+	options := newRequestOptions(opts)
+	// Resolved here so that requests the client makes on its own behalf, such as
+	// session exchanges, are never dry runs
+	options.dryRun = c.isDryRun(method, options)
+
+	endpoint := endpointLabel(method, path)
+	if err := c.checkReadOnly(method, endpoint); err != nil {
+		return nil, err
+	}
+
+	var jsonBody []byte
 	if body != nil {
-		jsonBody, err := json.Marshal(body)
+		var err error
+		jsonBody, err = c.codec.Marshal(body)
 		if err != nil {
 			return nil, fmt.Errorf("failed to marshal request body: %w", err)
 		}
-		reqBody = bytes.NewBuffer(jsonBody)
 	}
 
-	req, err := http.NewRequestWithContext(ctx, method, c.baseURL+path, reqBody)
+	apiKey, overridden := overrideAPIKey(ctx, options)
+	if !overridden {
+		var err error
+		if apiKey, err = c.resolveAPIKey(ctx); err != nil {
+			return nil, err
+		}
+	}
+
+	cacheable := c.responseCache != nil && method == http.MethodGet && options.stream == nil
+	if cacheable && !options.cacheRefresh {
+		if resp, ok := c.responseCache.get(ctx, apiKey, endpoint, path); ok {
+			resp.strict = c.strictDecoding
+			resp.codec = c.codec
+			if options.meta != nil {
+				*options.meta = ResponseMeta{StatusCode: resp.statusCode, Cached: true}
+			}
+			return resp, nil
+		}
+	}
+
+	// Dashboard-scope endpoints take a session token obtained with the API key
+	if c.sessions.required(endpoint) {
+		if err := c.authorizeSession(ctx, apiKey, options, false); err != nil {
+			return nil, err
+		}
+	}
+
+	resp, err := c.send(ctx, method, path, jsonBody, apiKey, options)
+	if isSessionRequired(err) {
+		c.sessions.markRequired(endpoint)
+		if err := c.authorizeSession(ctx, apiKey, options, true); err != nil {
+			return nil, err
+		}
+		c.metrics.IncRetry(endpoint)
+		resp, err = c.send(ctx, method, path, jsonBody, apiKey, options)
+	}
+	if isUnauthorized(err) && !overridden {
+		// The key may have been rotated: refresh it and retry once
+		if refreshedKey, ok := c.refreshAPIKey(ctx, apiKey); ok {
+			if c.sessions.required(endpoint) {
+				if err := c.authorizeSession(ctx, refreshedKey, options, true); err != nil {
+					return nil, err
+				}
+			}
+			c.metrics.IncRetry(endpoint)
+			resp, err = c.send(ctx, method, path, jsonBody, refreshedKey, options)
+		}
+	}
+	if err == nil && c.isDryRun(method, options) {
+		// Nothing changed, so cached responses stay valid
+		return resp, checkDryRun(method, path, resp)
+	}
+	if err == nil && c.responseCache != nil {
+		if cacheable {
+			c.responseCache.store(ctx, apiKey, endpoint, path, resp)
+		} else if method != http.MethodGet {
+			c.responseCache.invalidate(ctx, apiKey, path)
+		}
+	}
+	return resp, err
+}
+
+// send performs a single HTTP request attempt, or one per base URL while failing over
+func (c *Client) send(ctx context.Context, method, path string, jsonBody []byte, apiKey string, options *requestOptions) (*apiResponse, error) {
+	if c.failover != nil {
+		return c.failover.send(ctx, method, path, options, func(baseURL string) (*apiResponse, error) {
+			return c.sendTo(ctx, baseURL, method, path, jsonBody, apiKey, options)
+		}, c.metrics)
+	}
+	return c.sendTo(ctx, c.baseURL, method, path, jsonBody, apiKey, options)
+}
+
+// sendTo performs a single HTTP request attempt against baseURL
+func (c *Client) sendTo(ctx context.Context, baseURL, method, path string, jsonBody []byte, apiKey string, options *requestOptions) (*apiResponse, error) {
+	httpClient := c.httpClient
+	if options.timeout > 0 {
+		// Copy the client so the override does not leak into concurrent calls
+		hc := *c.httpClient
+		hc.Timeout = options.timeout
+		httpClient = &hc
+	}
+
+	var reqBody io.Reader
+	if jsonBody != nil {
+		reqBody = bytes.NewReader(jsonBody)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, baseURL+path, reqBody)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
 
 	// Set headers
 	req.Header.Set("Content-Type", "application/json")
-	if c.apiKey != "" {
-		req.Header.Set("Api-key", c.apiKey)
+	if apiKey != "" {
+		req.Header.Set("Api-key", apiKey)
+	}
+	if c.environment == EnvSandbox {
+		req.Header.Set(TestModeHeader, "true")
+	}
+	if c.acceptLanguage != "" {
+		req.Header.Set(AcceptLanguageHeader, c.acceptLanguage)
+	}
+	if options.dryRun {
+		req.Header.Set(DryRunHeader, "true")
+	}
+	// Per-request headers take precedence over the defaults above
+	for key, values := range options.headers {
+		req.Header[key] = values
 	}
 
-	resp, err := c.httpClient.Do(req)
+	endpoint := endpointLabel(method, path)
+	if c.limiter != nil {
+		release, err := c.limiter.acquire(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s", err, endpoint)
+		}
+		defer release()
+	}
+	var probe uint64
+	if c.breaker != nil {
+		if probe, err = c.breaker.allow(); err != nil {
+			return nil, fmt.Errorf("%w: %s", err, endpoint)
+		}
+	}
+	if c.signingSecret != nil {
+		// Signed once the request may go, so waiting for a slot does not age its timestamp
+		if err := c.signRequest(req, jsonBody); err != nil {
+			if c.breaker != nil {
+				c.breaker.release(probe)
+			}
+			return nil, fmt.Errorf("failed to sign request: %w", err)
+		}
+	}
+	start := time.Now()
+	var resp *http.Response
+	if options.hedgeDelay > 0 && method == http.MethodGet {
+		var prepare func(*http.Request) error
+		if c.signingSecret != nil {
+			// A copy of the signed request would be rejected as a replay of its nonce
+			prepare = func(hedge *http.Request) error { return c.signRequest(hedge, jsonBody) }
+		}
+		resp, err = hedgedRoundTrip(c.clock, c.roundTripper(httpClient), req, options.hedgeDelay, prepare)
+	} else {
+		resp, err = c.roundTripper(httpClient)(req)
+	}
+	if c.breaker != nil {
+		statusCode := 0
+		if err == nil {
+			statusCode = resp.StatusCode
+		}
+		c.breaker.record(ctx, probe, statusCode)
+	}
 	if err != nil {
+		c.observeRequest(ctx, endpoint, 0, time.Since(start))
 		return nil, fmt.Errorf("failed to execute request: %w", err)
 	}
 	defer resp.Body.Close()
+	options.recordMeta(resp.StatusCode, resp.Header)
+
+	if options.stream != nil && resp.StatusCode < 400 {
+		err := options.stream(resp.Body)
+		c.observeRequest(ctx, endpoint, resp.StatusCode, time.Since(start))
+		c.reportDeprecation(endpoint, resp.Header)
+		if err != nil {
+			return nil, err
+		}
+		return &apiResponse{
+			endpoint:   endpoint,
+			statusCode: resp.StatusCode,
+			header:     resp.Header,
+			strict:     c.strictDecoding,
+			codec:      c.codec,
+		}, nil
+	}
 
 	respBody, err := io.ReadAll(resp.Body)
+	c.observeRequest(ctx, endpoint, resp.StatusCode, time.Since(start))
+	if resp.StatusCode == http.StatusTooManyRequests {
+		c.metrics.IncRateLimited(endpoint)
+	}
+	c.reportDeprecation(endpoint, resp.Header)
 	if err != nil {
 		return nil, fmt.Errorf("failed to read response body: %w", err)
 	}
@@ -72,162 +312,289 @@ func (c *Client) doRequest(ctx context.Context, method, path string, body interf
 	// Check for HTTP errors
 	if resp.StatusCode >= 400 {
 		var apiError ErrorResponse
-		if err := json.Unmarshal(respBody, &apiError); err != nil {
-			return nil, fmt.Errorf("HTTP %d: %s", resp.StatusCode, string(respBody))
+		if err := c.codec.Unmarshal(respBody, &apiError); err != nil {
+			return nil, &HTTPError{StatusCode: resp.StatusCode, Body: respBody}
 		}
-		return nil, &APIError{
-			StatusCode: resp.StatusCode,
-			ErrorType:  apiError.Error,
-			Message:    apiError.Message,
+		if challenge, ok := stepUpChallenge(resp.StatusCode, apiError, respBody); ok {
+			return nil, challenge
 		}
+		apiErr := NewAPIError(resp.StatusCode, apiError)
+		apiErr.Language = resp.Header.Get("Content-Language")
+		return nil, apiErr
+	}
+
+	return &apiResponse{
+		endpoint:   endpoint,
+		statusCode: resp.StatusCode,
+		header:     resp.Header,
+		body:       respBody,
+		strict:     c.strictDecoding,
+		codec:      c.codec,
+	}, nil
+}
+
+// HTTPError is an HTTP error response without a JSON error body, e.g. from a gateway
+// or load balancer in front of the API. Responses with an error body are returned as
+// *APIError instead.
+type HTTPError struct {
+	StatusCode int
+	Body       []byte
+}
+
+// Error returns the status code and body
+func (e *HTTPError) Error() string {
+	return fmt.Sprintf("HTTP %d: %s", e.StatusCode, string(e.Body))
+}
+
+// errorStatusCode returns the HTTP status of an *APIError or *HTTPError in err's chain
+func errorStatusCode(err error) (int, bool) {
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.StatusCode, true
+	}
+	var httpErr *HTTPError
+	if errors.As(err, &httpErr) {
+		return httpErr.StatusCode, true
 	}
+	return 0, false
+}
 
-	return respBody, nil
+// do performs a request and decodes the response body into a new T. Decoding
+// failures are returned as a *DecodeError.
+func do[T any](ctx context.Context, c *Client, method, path string, body interface{}, opts ...RequestOption) (*T, error) {
+	v, _, err := doWithResponse[T](ctx, c, method, path, body, opts...)
+	return v, err
 }
 
-// CreateInvoice creates a new cryptocurrency invoice
-func (c *Client) CreateInvoice(ctx context.Context, req CreateInvoiceRequest) (*Invoice, error) {
-	// Debug: print the request being sent
-	reqJSON, _ := json.MarshalIndent(req, "", "  ")
-	fmt.Printf("DEBUG: Creating invoice with request:\n%s\n", string(reqJSON))
-	
-	respBody, err := c.doRequest(ctx, "POST", "/invoices", req)
+// doWithResponse is like do and also returns the raw response, which is set when
+// decoding fails
+func doWithResponse[T any](ctx context.Context, c *Client, method, path string, body interface{}, opts ...RequestOption) (*T, *Response, error) {
+	resp, err := c.doRequest(ctx, method, path, body, opts...)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
-	var invoice Invoice
-	if err := json.Unmarshal(respBody, &invoice); err != nil {
-		return nil, fmt.Errorf("failed to unmarshal invoice response: %w", err)
+	var v T
+	if err := resp.decode(&v); err != nil {
+		return nil, resp.response(), err
 	}
 
-	return &invoice, nil
+	return &v, resp.response(), nil
 }
 
-// GetInvoice retrieves a specific invoice by ID
-func (c *Client) GetInvoice(ctx context.Context, invoiceID string) (*Invoice, error) {
-	respBody, err := c.doRequest(ctx, "GET", "/invoices/"+invoiceID, nil)
+// Do calls an endpoint the client has no method for yet. path is relative to the base
+// URL, e.g. "/invoices/abc/notes". body, if not nil, is sent as JSON; the response is
+// decoded into out unless out is nil. Authentication, signing, interceptors and
+// request options apply as for any other call.
+func (c *Client) Do(ctx context.Context, method, path string, body, out interface{}, opts ...RequestOption) (*Response, error) {
+	resp, err := c.doRequest(ctx, method, path, body, opts...)
 	if err != nil {
 		return nil, err
 	}
 
-	var invoice Invoice
-	if err := json.Unmarshal(respBody, &invoice); err != nil {
-		return nil, fmt.Errorf("failed to unmarshal invoice response: %w", err)
+	if out != nil {
+		if err := resp.decode(out); err != nil {
+			return resp.response(), err
+		}
 	}
 
-	return &invoice, nil
+	return resp.response(), nil
 }
 
-// ListInvoices retrieves a paginated list of invoices with optional filtering
-func (c *Client) ListInvoices(ctx context.Context, params ListInvoicesParams) (*ListInvoicesResponse, error) {
-	// Build query parameters
-	queryParams := url.Values{}
-	if params.Page > 0 {
-		queryParams.Set("page", fmt.Sprintf("%d", params.Page))
+// CreateInvoice creates a new cryptocurrency invoice. With WithReadBack, it waits until
+// the invoice is readable and returns ErrReadBackTimeout alongside the invoice if it is not.
+func (c *Client) CreateInvoice(ctx context.Context, req CreateInvoiceRequest, opts ...RequestOption) (*Invoice, error) {
+	invoice, _, err := c.CreateInvoiceWithResponse(ctx, req, opts...)
+	return invoice, err
+}
+
+// CreateInvoiceWithResponse is like CreateInvoice and also returns the raw response
+func (c *Client) CreateInvoiceWithResponse(ctx context.Context, req CreateInvoiceRequest, opts ...RequestOption) (*Invoice, *Response, error) {
+	// Fail before screening hooks and precision lookups call other services
+	if err := c.checkReadOnly("POST", createInvoiceEndpoint); err != nil {
+		return nil, nil, err
 	}
-	if params.PageSize > 0 {
-		queryParams.Set("page_size", fmt.Sprintf("%d", params.PageSize))
+	req, err := c.prepareInvoice(ctx, req)
+	if err != nil {
+		return nil, nil, err
 	}
-	if params.Status != "" {
-		queryParams.Set("status", params.Status)
+	return c.createPreparedInvoice(ctx, req, opts)
+}
+
+// prepareInvoice runs the pre-create hooks on req, validates it and enforces the
+// currency's precision on its crypto amount. req is a copy, so repointing does not
+// modify the caller's value.
+func (c *Client) prepareInvoice(ctx context.Context, req CreateInvoiceRequest) (CreateInvoiceRequest, error) {
+	if err := c.screenInvoice(ctx, &req); err != nil {
+		return req, err
 	}
-	if params.Currency != "" {
-		queryParams.Set("currency", params.Currency)
+	if err := req.validate(); err != nil {
+		return req, err
 	}
-	if !params.CreatedAfter.IsZero() {
-		queryParams.Set("created_after", params.CreatedAfter.Format(time.RFC3339))
+	if req.CryptoAmount != nil {
+		amount, err := c.enforcePrecision(ctx, req.Currency, *req.CryptoAmount)
+		if err != nil {
+			return req, err
+		}
+		req.CryptoAmount = &amount
 	}
-	if !params.CreatedBefore.IsZero() {
-		queryParams.Set("created_before", params.CreatedBefore.Format(time.RFC3339))
+	return req, nil
+}
+
+// createPreparedInvoice creates an invoice from a request prepared with prepareInvoice
+func (c *Client) createPreparedInvoice(ctx context.Context, req CreateInvoiceRequest, opts []RequestOption) (*Invoice, *Response, error) {
+	invoice, resp, err := doWithResponse[Invoice](ctx, c, "POST", "/invoices", req, opts...)
+	if err != nil {
+		return nil, resp, err
+	}
+	options := newRequestOptions(opts)
+	if c.isDryRun("POST", options) {
+		// The invoice was not created: there is nothing to record or read back
+		return invoice, resp, nil
 	}
-	if params.SortBy != "" {
-		queryParams.Set("sort_by", params.SortBy)
+	return invoice, resp, c.afterCreate(ctx, invoice, options, opts)
+}
+
+// afterCreate records the rate snapshot of a created invoice and, with WithReadBack,
+// waits until it is readable
+func (c *Client) afterCreate(ctx context.Context, invoice *Invoice, options *requestOptions, opts []RequestOption) error {
+	if c.rateRecorder != nil {
+		// Recording failures are reported through RateRecorder.OnError
+		_ = c.rateRecorder.Record(ctx, invoice)
 	}
-	if params.SortOrder != "" {
-		queryParams.Set("sort_order", params.SortOrder)
+	if policy := options.readBack; policy != nil {
+		return c.readBackInvoice(ctx, policy, invoice.InvoiceID, func(*Invoice) bool { return true }, opts)
 	}
+	return nil
+}
+
+// GetInvoice retrieves a specific invoice by ID
+func (c *Client) GetInvoice(ctx context.Context, invoiceID string, opts ...RequestOption) (*Invoice, error) {
+	invoice, _, err := c.GetInvoiceWithResponse(ctx, invoiceID, opts...)
+	return invoice, err
+}
 
+// GetInvoiceWithResponse is like GetInvoice and also returns the raw response
+func (c *Client) GetInvoiceWithResponse(ctx context.Context, invoiceID string, opts ...RequestOption) (*Invoice, *Response, error) {
+	return doWithResponse[Invoice](ctx, c, "GET", "/invoices/"+invoiceID, nil, opts...)
+}
+
+// ListInvoices retrieves a paginated list of invoices with optional filtering
+func (c *Client) ListInvoices(ctx context.Context, params ListInvoicesParams, opts ...RequestOption) (*ListInvoicesResponse, error) {
+	response, _, err := c.ListInvoicesWithResponse(ctx, params, opts...)
+	return response, err
+}
+
+// ListInvoicesWithResponse is like ListInvoices and also returns the raw response
+func (c *Client) ListInvoicesWithResponse(ctx context.Context, params ListInvoicesParams, opts ...RequestOption) (*ListInvoicesResponse, *Response, error) {
 	path := "/invoices"
-	if len(queryParams) > 0 {
+	if queryParams := params.query(); len(queryParams) > 0 {
 		path += "?" + queryParams.Encode()
 	}
 
-	respBody, err := c.doRequest(ctx, "GET", path, nil)
-	if err != nil {
-		return nil, err
-	}
+	return doWithResponse[ListInvoicesResponse](ctx, c, "GET", path, nil, opts...)
+}
 
-	var response ListInvoicesResponse
-	if err := json.Unmarshal(respBody, &response); err != nil {
-		return nil, fmt.Errorf("failed to unmarshal invoices response: %w", err)
+// query returns the query parameters selecting the invoices
+func (p ListInvoicesParams) query() url.Values {
+	queryParams := url.Values{}
+	if p.Page > 0 {
+		queryParams.Set("page", fmt.Sprintf("%d", p.Page))
 	}
-
-	return &response, nil
+	if p.PageSize > 0 {
+		queryParams.Set("page_size", fmt.Sprintf("%d", p.PageSize))
+	}
+	if p.Status != "" {
+		queryParams.Set("status", p.Status)
+	}
+	if p.Currency != "" {
+		queryParams.Set("currency", p.Currency)
+	}
+	if !p.CreatedAfter.IsZero() {
+		queryParams.Set("created_after", p.CreatedAfter.Format(time.RFC3339))
+	}
+	if !p.CreatedBefore.IsZero() {
+		queryParams.Set("created_before", p.CreatedBefore.Format(time.RFC3339))
+	}
+	if p.SortBy != "" {
+		queryParams.Set("sort_by", p.SortBy)
+	}
+	if p.SortOrder != "" {
+		queryParams.Set("sort_order", p.SortOrder)
+	}
+	for key, value := range p.ExternalRefs {
+		queryParams.Set("external_ref["+key+"]", value)
+	}
+	return queryParams
 }
 
 // GetCurrencies retrieves the list of supported currencies
-func (c *Client) GetCurrencies(ctx context.Context) (*CurrenciesResponse, error) {
-	respBody, err := c.doRequest(ctx, "GET", "/currencies", nil)
-	if err != nil {
-		return nil, err
-	}
+func (c *Client) GetCurrencies(ctx context.Context, opts ...RequestOption) (*CurrenciesResponse, error) {
+	response, _, err := c.GetCurrenciesWithResponse(ctx, opts...)
+	return response, err
+}
 
+// GetCurrenciesWithResponse is like GetCurrencies and also returns the raw response
+func (c *Client) GetCurrenciesWithResponse(ctx context.Context, opts ...RequestOption) (*CurrenciesResponse, *Response, error) {
 	// The API returns an array of currency objects directly
-	var currencies []Currency
-	if err := json.Unmarshal(respBody, &currencies); err != nil {
-		return nil, fmt.Errorf("failed to unmarshal currencies response: %w", err)
+	currencies, resp, err := doWithResponse[[]Currency](ctx, c, "GET", "/currencies", nil, opts...)
+	if err != nil {
+		return nil, resp, err
 	}
 
-	return &CurrenciesResponse{Currencies: currencies}, nil
+	return &CurrenciesResponse{Currencies: *currencies}, resp, nil
 }
 
 // GetRates retrieves current exchange rates for supported cryptocurrencies
-func (c *Client) GetRates(ctx context.Context) (*RatesResponse, error) {
-	respBody, err := c.doRequest(ctx, "GET", "/rates", nil)
-	if err != nil {
-		return nil, err
-	}
+func (c *Client) GetRates(ctx context.Context, opts ...RequestOption) (*RatesResponse, error) {
+	response, _, err := c.GetRatesWithResponse(ctx, opts...)
+	return response, err
+}
 
-	var response RatesResponse
-	if err := json.Unmarshal(respBody, &response); err != nil {
-		return nil, fmt.Errorf("failed to unmarshal rates response: %w", err)
-	}
+// GetRatesWithResponse is like GetRates and also returns the raw response
+func (c *Client) GetRatesWithResponse(ctx context.Context, opts ...RequestOption) (*RatesResponse, *Response, error) {
+	return doWithResponse[RatesResponse](ctx, c, "GET", "/rates", nil, opts...)
+}
 
-	return &response, nil
+// UpdateInvoiceStatus updates the status of an existing invoice. With WithReadBack, it
+// waits until reads return the new status and returns ErrReadBackTimeout alongside the
+// invoice if they do not.
+func (c *Client) UpdateInvoiceStatus(ctx context.Context, invoiceID string, status string, opts ...RequestOption) (*Invoice, error) {
+	invoice, _, err := c.UpdateInvoiceStatusWithResponse(ctx, invoiceID, status, opts...)
+	return invoice, err
 }
 
-// UpdateInvoiceStatus updates the status of an existing invoice
-func (c *Client) UpdateInvoiceStatus(ctx context.Context, invoiceID string, status string) (*Invoice, error) {
+// UpdateInvoiceStatusWithResponse is like UpdateInvoiceStatus and also returns the raw response
+func (c *Client) UpdateInvoiceStatusWithResponse(ctx context.Context, invoiceID string, status string, opts ...RequestOption) (*Invoice, *Response, error) {
 	req := UpdateInvoiceRequest{Status: status}
-	respBody, err := c.doRequest(ctx, "PATCH", "/invoices/"+invoiceID, req)
+	invoice, resp, err := doWithResponse[Invoice](ctx, c, "PATCH", "/invoices/"+invoiceID, req, opts...)
 	if err != nil {
-		return nil, err
+		return nil, resp, err
 	}
 
-	var invoice Invoice
-	if err := json.Unmarshal(respBody, &invoice); err != nil {
-		return nil, fmt.Errorf("failed to unmarshal invoice response: %w", err)
+	options := newRequestOptions(opts)
+	if policy := options.readBack; policy != nil && !c.isDryRun("PATCH", options) {
+		err := c.readBackInvoice(ctx, policy, invoiceID, func(fetched *Invoice) bool {
+			return fetched.Status == Status(status)
+		}, opts)
+		if err != nil {
+			return invoice, resp, err
+		}
 	}
 
-	return &invoice, nil
+	return invoice, resp, nil
 }
 
-// SimulateWebhook simulates a webhook callback for testing purposes (no authentication required)
-func (c *Client) SimulateWebhook(ctx context.Context, invoiceID, status string) (*WebhookSimulateResponse, error) {
+// SimulateWebhook simulates a webhook callback for testing purposes (no authentication required).
+// It is only available in the sandbox environment.
+func (c *Client) SimulateWebhook(ctx context.Context, invoiceID, status string, opts ...RequestOption) (*WebhookSimulateResponse, error) {
+	if c.environment != EnvSandbox {
+		return nil, ErrSimulationInProduction
+	}
+
 	req := WebhookSimulateRequest{
 		InvoiceID: invoiceID,
 		Status:    status,
 	}
-	respBody, err := c.doRequest(ctx, "POST", "/webhooks/simulate", req)
-	if err != nil {
-		return nil, err
-	}
-
-	var response WebhookSimulateResponse
-	if err := json.Unmarshal(respBody, &response); err != nil {
-		return nil, fmt.Errorf("failed to unmarshal webhook simulate response: %w", err)
-	}
-
-	return &response, nil
+	return do[WebhookSimulateResponse](ctx, c, "POST", "/webhooks/simulate", req, opts...)
 }
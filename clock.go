@@ -0,0 +1,80 @@
+package itispay
+
+import (
+	"context"
+	"time"
+)
+
+// Clock tells the time and creates timers. The client uses it for polling, backoff,
+// cache expiry and the circuit breaker, so tests can replace the system clock with a
+// fake, such as itispaytest.FakeClock, instead of sleeping.
+type Clock interface {
+	Now() time.Time
+	NewTimer(d time.Duration) Timer
+}
+
+// Timer is a timer created by a Clock
+type Timer interface {
+	// C returns the channel the current time is sent on when the timer fires
+	C() <-chan time.Time
+	// Stop prevents the timer from firing, reporting whether it was still pending
+	Stop() bool
+}
+
+// SystemClock is the Clock of the time package
+var SystemClock Clock = systemClock{}
+
+// WithClock replaces the system clock used by the client, e.g. with a fake clock in tests
+func WithClock(clock Clock) Option {
+	return func(c *Client) {
+		c.clock = clock
+	}
+}
+
+// systemClock implements Clock with the time package
+type systemClock struct{}
+
+// Now implements Clock
+func (systemClock) Now() time.Time {
+	return time.Now()
+}
+
+// NewTimer implements Clock
+func (systemClock) NewTimer(d time.Duration) Timer {
+	return systemTimer{time.NewTimer(d)}
+}
+
+// systemTimer implements Timer with a time.Timer
+type systemTimer struct {
+	timer *time.Timer
+}
+
+// C implements Timer
+func (t systemTimer) C() <-chan time.Time {
+	return t.timer.C
+}
+
+// Stop implements Timer
+func (t systemTimer) Stop() bool {
+	return t.timer.Stop()
+}
+
+// clockOrSystem returns clock, or SystemClock if it is nil
+func clockOrSystem(clock Clock) Clock {
+	if clock == nil {
+		return SystemClock
+	}
+	return clock
+}
+
+// sleep waits for d on clock, returning early with the context's error if ctx is done
+func sleep(ctx context.Context, clock Clock, d time.Duration) error {
+	timer := clock.NewTimer(d)
+	select {
+	case <-ctx.Done():
+		timer.Stop()
+		return ctx.Err()
+	case <-timer.C():
+		return nil
+	}
+}
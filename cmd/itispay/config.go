@@ -0,0 +1,101 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	itispay "github.com/ItIsPay/go-client"
+)
+
+// apiKeyEnv is the environment variable holding the API key
+const apiKeyEnv = "ITISPAY_API_KEY"
+
+// configFileName is the config file path relative to the user config directory
+var configFileName = filepath.Join("itispay", "config.json")
+
+// config is the content of the config file
+type config struct {
+	APIKey  string `json:"api_key"`
+	Sandbox bool   `json:"sandbox"`
+	BaseURL string `json:"base_url"`
+}
+
+// globalFlags are the flags shared by all commands
+type globalFlags struct {
+	apiKey     string
+	configPath string
+	sandbox    bool
+	baseURL    string
+	output     string
+}
+
+// defaultConfigPath returns the config file path in the user config directory
+func defaultConfigPath() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, configFileName), nil
+}
+
+// loadConfig reads the config file. A missing default config file is not an error.
+func loadConfig(path string) (config, error) {
+	explicit := path != ""
+	if !explicit {
+		var err error
+		if path, err = defaultConfigPath(); err != nil {
+			return config{}, nil
+		}
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if !explicit && errors.Is(err, fs.ErrNotExist) {
+			return config{}, nil
+		}
+		return config{}, fmt.Errorf("reading config: %w", err)
+	}
+
+	var cfg config
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return config{}, fmt.Errorf("parsing config %s: %w", path, err)
+	}
+	return cfg, nil
+}
+
+// newClient creates a client from flags, environment and config file
+func (g *globalFlags) newClient() (*itispay.Client, error) {
+	cfg, err := loadConfig(g.configPath)
+	if err != nil {
+		return nil, err
+	}
+
+	apiKey := g.apiKey
+	if apiKey == "" {
+		apiKey = os.Getenv(apiKeyEnv)
+	}
+	if apiKey == "" {
+		apiKey = cfg.APIKey
+	}
+	if apiKey == "" {
+		return nil, fmt.Errorf("no API key: use --api-key, $%s or the config file", apiKeyEnv)
+	}
+
+	var opts []itispay.Option
+	if g.sandbox || cfg.Sandbox {
+		opts = append(opts, itispay.WithEnvironment(itispay.EnvSandbox))
+	}
+	baseURL := g.baseURL
+	if baseURL == "" {
+		baseURL = cfg.BaseURL
+	}
+	if baseURL != "" {
+		opts = append(opts, itispay.WithBaseURL(baseURL))
+	}
+
+	return itispay.NewClient(apiKey, opts...), nil
+}
@@ -0,0 +1,151 @@
+package main
+
+import (
+	"github.com/spf13/cobra"
+
+	itispay "github.com/ItIsPay/go-client"
+)
+
+// newInvoiceCommand builds the invoice command group
+func newInvoiceCommand(g *globalFlags) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "invoice",
+		Short: "Create and inspect invoices",
+	}
+	cmd.AddCommand(
+		newInvoiceCreateCommand(g),
+		newInvoiceGetCommand(g),
+		newInvoiceListCommand(g),
+	)
+	return cmd
+}
+
+func newInvoiceCreateCommand(g *globalFlags) *cobra.Command {
+	var req itispay.CreateInvoiceRequest
+	var fiatAmount, cryptoAmount float64
+	var allowedErrorPercent, expireMin, gracePeriodMin int
+	var idempotencyKey string
+
+	cmd := &cobra.Command{
+		Use:   "create",
+		Short: "Create an invoice",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			p, err := newPrinter(cmd.OutOrStdout(), g.output)
+			if err != nil {
+				return err
+			}
+			client, err := g.newClient()
+			if err != nil {
+				return err
+			}
+
+			flags := cmd.Flags()
+			if flags.Changed("fiat-amount") {
+				req.FiatAmount = &fiatAmount
+			}
+			if flags.Changed("crypto-amount") {
+				req.CryptoAmount = &cryptoAmount
+			}
+			if flags.Changed("allowed-error-percent") {
+				req.AllowedErrorPercent = &allowedErrorPercent
+			}
+			if flags.Changed("expire-min") {
+				req.ExpireMin = &expireMin
+			}
+			if flags.Changed("grace-period-min") {
+				req.GracePeriodMin = &gracePeriodMin
+			}
+			var opts []itispay.RequestOption
+			if idempotencyKey != "" {
+				opts = append(opts, itispay.WithIdempotencyKey(idempotencyKey))
+			}
+
+			invoice, err := client.CreateInvoice(cmd.Context(), req, opts...)
+			if err != nil {
+				return err
+			}
+			return p.print(invoice, invoiceHeader, [][]string{invoiceRow(invoice)})
+		},
+	}
+
+	flags := cmd.Flags()
+	flags.StringVar(&req.OrderID, "order-id", "", "merchant order ID (required)")
+	flags.StringVar(&req.Currency, "currency", "", "cryptocurrency to pay in, e.g. BTC (required)")
+	flags.Float64Var(&fiatAmount, "fiat-amount", 0, "amount in fiat currency")
+	flags.StringVar(&req.FiatCurrency, "fiat-currency", "", "fiat currency, e.g. EUR")
+	flags.Float64Var(&cryptoAmount, "crypto-amount", 0, "amount in cryptocurrency")
+	flags.IntVar(&allowedErrorPercent, "allowed-error-percent", 0, "accepted payment deviation in percent")
+	flags.StringVar(&req.OrderName, "order-name", "", "order description shown to the payer")
+	flags.IntVar(&expireMin, "expire-min", 0, "minutes until the invoice expires")
+	flags.IntVar(&gracePeriodMin, "grace-period-min", 0, "minutes after expiry during which payments still complete the invoice")
+	flags.StringVar(&req.CallbackURL, "callback-url", "", "webhook URL for status updates")
+	flags.StringVar(&idempotencyKey, "idempotency-key", "", "idempotency key for safe retries")
+	_ = cmd.MarkFlagRequired("order-id")
+	_ = cmd.MarkFlagRequired("currency")
+	cmd.MarkFlagsOneRequired("fiat-amount", "crypto-amount")
+	return cmd
+}
+
+func newInvoiceGetCommand(g *globalFlags) *cobra.Command {
+	return &cobra.Command{
+		Use:   "get <invoice-id>",
+		Short: "Show an invoice",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			p, err := newPrinter(cmd.OutOrStdout(), g.output)
+			if err != nil {
+				return err
+			}
+			client, err := g.newClient()
+			if err != nil {
+				return err
+			}
+
+			invoice, err := client.GetInvoice(cmd.Context(), args[0])
+			if err != nil {
+				return err
+			}
+			return p.print(invoice, invoiceHeader, [][]string{invoiceRow(invoice)})
+		},
+	}
+}
+
+func newInvoiceListCommand(g *globalFlags) *cobra.Command {
+	var params itispay.ListInvoicesParams
+
+	cmd := &cobra.Command{
+		Use:   "list",
+		Short: "List invoices",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			p, err := newPrinter(cmd.OutOrStdout(), g.output)
+			if err != nil {
+				return err
+			}
+			client, err := g.newClient()
+			if err != nil {
+				return err
+			}
+
+			response, err := client.ListInvoices(cmd.Context(), params)
+			if err != nil {
+				return err
+			}
+			rows := make([][]string, 0, len(response.Items))
+			for i := range response.Items {
+				rows = append(rows, invoiceRow(&response.Items[i]))
+			}
+			return p.print(response, invoiceHeader, rows)
+		},
+	}
+
+	flags := cmd.Flags()
+	flags.IntVar(&params.Page, "page", 1, "page number")
+	flags.IntVar(&params.PageSize, "page-size", 20, "invoices per page")
+	flags.StringVar(&params.Status, "status", "", "filter by status")
+	flags.StringVar(&params.Currency, "currency", "", "filter by cryptocurrency")
+	flags.StringVar(&params.SortBy, "sort-by", "", "sort field, e.g. created_at")
+	flags.StringVar(&params.SortOrder, "sort-order", "", "sort order: asc or desc")
+	return cmd
+}
@@ -0,0 +1,201 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	itispay "github.com/ItIsPay/go-client"
+)
+
+// listenPageSize is the number of recently updated invoices checked per poll
+const listenPageSize = 50
+
+// skipHeaders are the headers not copied when forwarding received webhooks
+var skipHeaders = map[string]bool{
+	"Connection":        true,
+	"Content-Length":    true,
+	"Host":              true,
+	"Keep-Alive":        true,
+	"Transfer-Encoding": true,
+}
+
+func newListenCommand(g *globalFlags) *cobra.Command {
+	var forwardTo, listenAddr string
+	var interval time.Duration
+	var noPoll bool
+
+	cmd := &cobra.Command{
+		Use:   "listen --forward-to localhost:3000/webhook",
+		Short: "Forward webhook callbacks to a local server",
+		Long: `Forward webhook callbacks to a local server, so callbacks can be tested without a
+public URL.
+
+By default invoices are polled for status changes and each change is posted to the
+--forward-to URL as a webhook payload. With --listen, webhooks delivered to the given
+local address (e.g. through a tunnel) are forwarded as well.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if !strings.Contains(forwardTo, "://") {
+				forwardTo = "http://" + forwardTo
+			}
+			if noPoll && listenAddr == "" {
+				return errors.New("nothing to do: --no-poll requires --listen")
+			}
+
+			f := &forwarder{url: forwardTo, out: cmd.ErrOrStderr(), httpClient: &http.Client{Timeout: 30 * time.Second}}
+			ctx := cmd.Context()
+			errs := make(chan error, 2)
+
+			if listenAddr != "" {
+				srv := &http.Server{Addr: listenAddr, Handler: f, ReadHeaderTimeout: 10 * time.Second}
+				go func() {
+					<-ctx.Done()
+					_ = srv.Close()
+				}()
+				go func() {
+					fmt.Fprintf(f.out, "Listening for webhooks on %s\n", listenAddr)
+					if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+						errs <- err
+					}
+				}()
+			}
+			if !noPoll {
+				client, err := g.newClient()
+				if err != nil {
+					return err
+				}
+				go func() {
+					fmt.Fprintf(f.out, "Polling invoice changes every %s\n", interval)
+					errs <- f.poll(ctx, client, interval)
+				}()
+			}
+			fmt.Fprintf(f.out, "Forwarding to %s (Ctrl-C to stop)\n", forwardTo)
+
+			select {
+			case err := <-errs:
+				return err
+			case <-ctx.Done():
+				return nil
+			}
+		},
+	}
+
+	flags := cmd.Flags()
+	flags.StringVar(&forwardTo, "forward-to", "", "local URL receiving the webhooks, e.g. localhost:3000/webhook (required)")
+	flags.StringVar(&listenAddr, "listen", "", "also accept webhooks on this address, e.g. :8765")
+	flags.DurationVar(&interval, "interval", 2*time.Second, "invoice polling interval")
+	flags.BoolVar(&noPoll, "no-poll", false, "only forward webhooks received with --listen")
+	_ = cmd.MarkFlagRequired("forward-to")
+	return cmd
+}
+
+// forwarder posts webhook payloads to a local URL
+type forwarder struct {
+	url        string
+	out        io.Writer
+	httpClient *http.Client
+}
+
+// ServeHTTP forwards a received webhook
+func (f *forwarder) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, "Failed to read body", http.StatusBadRequest)
+		return
+	}
+
+	// Signature headers are kept so the local server can verify the webhook
+	header := http.Header{}
+	for name, values := range r.Header {
+		if !skipHeaders[name] {
+			header[name] = values
+		}
+	}
+	status, err := f.forward(r.Context(), body, header, "received")
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadGateway)
+		return
+	}
+	w.WriteHeader(status)
+}
+
+// poll forwards a payload for every invoice status change until ctx is done. Invoices
+// existing at startup are recorded but not forwarded.
+func (f *forwarder) poll(ctx context.Context, client *itispay.Client, interval time.Duration) error {
+	seen := make(map[string]itispay.Status)
+	first := true
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		response, err := client.ListInvoices(ctx, itispay.ListInvoicesParams{
+			PageSize:  listenPageSize,
+			SortBy:    itispay.SortByUpdatedAt,
+			SortOrder: itispay.SortOrderDesc,
+		})
+		switch {
+		case ctx.Err() != nil:
+			return nil
+		case err != nil:
+			fmt.Fprintf(f.out, "Polling failed: %v\n", err)
+		default:
+			// Oldest change first, so the local server sees changes in order
+			for i := len(response.Items) - 1; i >= 0; i-- {
+				invoice := response.Items[i]
+				if status, ok := seen[invoice.InvoiceID]; first || ok && status == invoice.Status {
+					seen[invoice.InvoiceID] = invoice.Status
+					continue
+				}
+				seen[invoice.InvoiceID] = invoice.Status
+
+				body, err := json.Marshal(invoice)
+				if err != nil {
+					return err
+				}
+				header := http.Header{"Content-Type": {"application/json"}}
+				if _, err := f.forward(ctx, body, header, invoice.InvoiceID+" "+string(invoice.Status)); err != nil && ctx.Err() == nil {
+					fmt.Fprintf(f.out, "Forwarding failed: %v\n", err)
+				}
+			}
+			first = false
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// forward posts body to the local URL and logs the outcome
+func (f *forwarder) forward(ctx context.Context, body []byte, header http.Header, label string) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, f.url, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+	req.Header = header
+
+	resp, err := f.httpClient.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, resp.Body)
+
+	fmt.Fprintf(f.out, "%s  --> %s  [%d]\n", time.Now().Format(time.TimeOnly), label, resp.StatusCode)
+	return resp.StatusCode, nil
+}
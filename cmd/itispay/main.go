@@ -0,0 +1,68 @@
+// Command itispay is a command line interface to the ItIsPay API.
+//
+// The API key is read from the --api-key flag, the ITISPAY_API_KEY environment
+// variable or the config file (by default itispay/config.json in the user config
+// directory), in that order.
+//
+// Usage:
+//
+//	itispay invoice create --order-id ORDER-1 --currency BTC --fiat-amount 50 --fiat-currency EUR
+//	itispay invoice get <invoice-id>
+//	itispay invoice list --status completed -o table
+//	itispay rates
+//	itispay currencies
+//	itispay webhook simulate <invoice-id> <status>
+//	itispay listen --forward-to localhost:3000/webhook
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+
+	"github.com/spf13/cobra"
+)
+
+func main() {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	err := newRootCommand().ExecuteContext(ctx)
+	stop()
+	if err != nil {
+		os.Exit(1)
+	}
+}
+
+// newRootCommand builds the command tree
+func newRootCommand() *cobra.Command {
+	var g globalFlags
+
+	root := &cobra.Command{
+		Use:          "itispay",
+		Short:        "Command line interface to the ItIsPay API",
+		SilenceUsage: true,
+	}
+	root.PersistentFlags().StringVar(&g.apiKey, "api-key", "", "API key (default $"+apiKeyEnv+" or config file)")
+	root.PersistentFlags().StringVar(&g.configPath, "config", "", "config file (default "+defaultConfigPathHint()+")")
+	root.PersistentFlags().BoolVar(&g.sandbox, "sandbox", false, "use the sandbox environment")
+	root.PersistentFlags().StringVar(&g.baseURL, "base-url", "", "override the API base URL")
+	root.PersistentFlags().StringVarP(&g.output, "output", "o", outputJSON, "output format: json or table")
+
+	root.AddCommand(
+		newInvoiceCommand(&g),
+		newRatesCommand(&g),
+		newCurrenciesCommand(&g),
+		newWebhookCommand(&g),
+		newListenCommand(&g),
+	)
+	return root
+}
+
+// defaultConfigPathHint describes the default config path for help texts
+func defaultConfigPathHint() string {
+	path, err := defaultConfigPath()
+	if err != nil {
+		return fmt.Sprintf("$XDG_CONFIG_HOME/%s", configFileName)
+	}
+	return path
+}
@@ -0,0 +1,65 @@
+package main
+
+import (
+	"strconv"
+
+	"github.com/spf13/cobra"
+)
+
+func newRatesCommand(g *globalFlags) *cobra.Command {
+	return &cobra.Command{
+		Use:   "rates",
+		Short: "Show current exchange rates",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			p, err := newPrinter(cmd.OutOrStdout(), g.output)
+			if err != nil {
+				return err
+			}
+			client, err := g.newClient()
+			if err != nil {
+				return err
+			}
+
+			response, err := client.GetRates(cmd.Context())
+			if err != nil {
+				return err
+			}
+			return p.print(response, []string{"CURRENCY", "RATE"}, ratesRows(response.Rates))
+		},
+	}
+}
+
+func newCurrenciesCommand(g *globalFlags) *cobra.Command {
+	return &cobra.Command{
+		Use:   "currencies",
+		Short: "List supported currencies",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			p, err := newPrinter(cmd.OutOrStdout(), g.output)
+			if err != nil {
+				return err
+			}
+			client, err := g.newClient()
+			if err != nil {
+				return err
+			}
+
+			response, err := client.GetCurrencies(cmd.Context())
+			if err != nil {
+				return err
+			}
+			rows := make([][]string, 0, len(response.Currencies))
+			for _, currency := range response.Currencies {
+				rows = append(rows, []string{
+					currency.CurrencyCode,
+					currency.Network,
+					strconv.Itoa(currency.Precision),
+					strconv.FormatBool(currency.IsCrypto),
+					strconv.FormatBool(currency.IsActive),
+				})
+			}
+			return p.print(response.Currencies, []string{"CODE", "NETWORK", "PRECISION", "CRYPTO", "ACTIVE"}, rows)
+		},
+	}
+}
@@ -0,0 +1,90 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	itispay "github.com/ItIsPay/go-client"
+)
+
+// Output formats
+const (
+	outputJSON  = "json"
+	outputTable = "table"
+)
+
+// printer writes command results in the selected format
+type printer struct {
+	w      io.Writer
+	format string
+}
+
+// newPrinter validates the output format
+func newPrinter(w io.Writer, format string) (*printer, error) {
+	if format != outputJSON && format != outputTable {
+		return nil, fmt.Errorf("unknown output format %q (use %s or %s)", format, outputJSON, outputTable)
+	}
+	return &printer{w: w, format: format}, nil
+}
+
+// print writes v as indented JSON, or as a table of header and rows
+func (p *printer) print(v interface{}, header []string, rows [][]string) error {
+	if p.format == outputJSON {
+		enc := json.NewEncoder(p.w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(v)
+	}
+
+	tw := tabwriter.NewWriter(p.w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, strings.Join(header, "\t"))
+	for _, row := range rows {
+		fmt.Fprintln(tw, strings.Join(row, "\t"))
+	}
+	return tw.Flush()
+}
+
+// invoiceHeader are the table columns of invoices
+var invoiceHeader = []string{"INVOICE ID", "ORDER ID", "STATUS", "AMOUNT", "PAID", "FIAT", "CREATED"}
+
+// invoiceRow formats an invoice as a table row
+func invoiceRow(invoice *itispay.Invoice) []string {
+	fiat := ""
+	if invoice.FiatAmount > 0 {
+		fiat = fmt.Sprintf("%s %s", formatAmount(invoice.FiatAmount), invoice.FiatCurrency)
+	}
+	return []string{
+		invoice.InvoiceID,
+		invoice.OrderID,
+		string(invoice.Status),
+		fmt.Sprintf("%s %s", formatAmount(invoice.CryptoAmount), invoice.Currency),
+		formatAmount(invoice.ActualCryptoAmountPaid),
+		fiat,
+		invoice.CreatedAt.Local().Format(time.DateTime),
+	}
+}
+
+// ratesRows formats exchange rates as sorted table rows
+func ratesRows(rates map[string]float64) [][]string {
+	currencies := make([]string, 0, len(rates))
+	for currency := range rates {
+		currencies = append(currencies, currency)
+	}
+	sort.Strings(currencies)
+
+	rows := make([][]string, 0, len(currencies))
+	for _, currency := range currencies {
+		rows = append(rows, []string{currency, formatAmount(rates[currency])})
+	}
+	return rows
+}
+
+// formatAmount formats an amount without trailing zeros
+func formatAmount(amount float64) string {
+	return strconv.FormatFloat(amount, 'f', -1, 64)
+}
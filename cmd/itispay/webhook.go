@@ -0,0 +1,39 @@
+package main
+
+import (
+	"github.com/spf13/cobra"
+)
+
+// newWebhookCommand builds the webhook command group
+func newWebhookCommand(g *globalFlags) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "webhook",
+		Short: "Test webhook integrations",
+	}
+	cmd.AddCommand(newWebhookSimulateCommand(g))
+	return cmd
+}
+
+func newWebhookSimulateCommand(g *globalFlags) *cobra.Command {
+	return &cobra.Command{
+		Use:   "simulate <invoice-id> <status>",
+		Short: "Simulate a webhook callback (sandbox only)",
+		Args:  cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			p, err := newPrinter(cmd.OutOrStdout(), g.output)
+			if err != nil {
+				return err
+			}
+			client, err := g.newClient()
+			if err != nil {
+				return err
+			}
+
+			response, err := client.SimulateWebhook(cmd.Context(), args[0], args[1])
+			if err != nil {
+				return err
+			}
+			return p.print(response, []string{"STATUS", "MESSAGE"}, [][]string{{response.Status, response.Message}})
+		},
+	}
+}
@@ -0,0 +1,36 @@
+package itispay
+
+import "encoding/json"
+
+// Codec encodes request bodies and decodes response bodies. Implement it on a faster
+// JSON library, such as jsoniter or segmentio/encoding, for high-throughput services.
+type Codec interface {
+	Marshal(v interface{}) ([]byte, error)
+	Unmarshal(data []byte, v interface{}) error
+}
+
+// JSONCodec is the default Codec, backed by encoding/json
+type JSONCodec struct{}
+
+// Marshal implements Codec
+func (JSONCodec) Marshal(v interface{}) ([]byte, error) {
+	return json.Marshal(v)
+}
+
+// Unmarshal implements Codec
+func (JSONCodec) Unmarshal(data []byte, v interface{}) error {
+	return json.Unmarshal(data, v)
+}
+
+// WithCodec replaces encoding/json for request and response bodies, e.g. with an adapter
+// for jsoniter:
+//
+//	client := itispay.NewClient(apiKey, itispay.WithCodec(jsoniter.ConfigCompatibleWithStandardLibrary))
+//
+// WithStrictDecoding and streamed listings such as ListInvoicesStream still decode with
+// encoding/json, which reports unknown fields and decodes incrementally.
+func WithCodec(codec Codec) Option {
+	return func(c *Client) {
+		c.codec = codec
+	}
+}
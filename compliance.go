@@ -0,0 +1,76 @@
+package itispay
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"strings"
+)
+
+// ErrComplianceRejected is returned when a pre-create hook vetoes an invoice
+var ErrComplianceRejected = errors.New("itispay: rejected by compliance screening")
+
+// PreCreateHook screens invoices before they are created, e.g. against sanctions or fraud
+// lists. It may annotate req, e.g. with a risk score in Metadata, or veto the invoice by
+// returning an error from Reject. Other errors, such as an unavailable screening service,
+// fail the creation as they are.
+type PreCreateHook interface {
+	BeforeCreateInvoice(ctx context.Context, req *CreateInvoiceRequest) error
+}
+
+// PreCreateHookFunc adapts a function to the PreCreateHook interface
+type PreCreateHookFunc func(ctx context.Context, req *CreateInvoiceRequest) error
+
+// BeforeCreateInvoice implements PreCreateHook
+func (f PreCreateHookFunc) BeforeCreateInvoice(ctx context.Context, req *CreateInvoiceRequest) error {
+	return f(ctx, req)
+}
+
+// WithPreCreateHook registers hooks run before every invoice is created, including by
+// CreateInvoices, so compliance controls apply to all invoices created through the client.
+// Hooks run in the order they are registered; the first error stops the chain.
+func WithPreCreateHook(hooks ...PreCreateHook) Option {
+	return func(c *Client) {
+		c.preCreateHooks = append(c.preCreateHooks, hooks...)
+	}
+}
+
+// Reject returns the error a PreCreateHook uses to veto an invoice
+func Reject(reason string) error {
+	return fmt.Errorf("%w: %s", ErrComplianceRejected, reason)
+}
+
+// DenyList returns a hook rejecting invoices whose external reference or metadata value
+// for key is one of values, compared case-insensitively, e.g.
+// DenyList("country", "KP", "IR") or DenyList("customer_id", blockedCustomers...)
+func DenyList(key string, values ...string) PreCreateHook {
+	denied := make(map[string]bool, len(values))
+	for _, value := range values {
+		denied[strings.ToLower(value)] = true
+	}
+	return PreCreateHookFunc(func(ctx context.Context, req *CreateInvoiceRequest) error {
+		for _, fields := range []map[string]string{req.ExternalRefs, req.Metadata} {
+			if value, ok := fields[key]; ok && denied[strings.ToLower(value)] {
+				return Reject(fmt.Sprintf("%s %q is denied", key, value))
+			}
+		}
+		return nil
+	})
+}
+
+// screenInvoice runs the pre-create hooks on req. The maps of req are copied first, so
+// annotations do not modify the caller's request.
+func (c *Client) screenInvoice(ctx context.Context, req *CreateInvoiceRequest) error {
+	if len(c.preCreateHooks) == 0 {
+		return nil
+	}
+	req.ExternalRefs = maps.Clone(req.ExternalRefs)
+	req.Metadata = maps.Clone(req.Metadata)
+	for _, hook := range c.preCreateHooks {
+		if err := hook.BeforeCreateInvoice(ctx, req); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,121 @@
+package itispay
+
+import (
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// DefaultCompressionThreshold is the minimum size of request bodies gzipped by
+// WithRequestCompression when no size is given
+const DefaultCompressionThreshold = 4 << 10
+
+// WithCompression asks the API for gzip-compressed responses and decompresses them
+// before they reach interceptors and decoding. It speeds up large responses such as
+// ListInvoices pages over slow links, also with a custom WithTransport.
+func WithCompression() Option {
+	return func(c *Client) {
+		c.compression.responses = true
+	}
+}
+
+// WithRequestCompression gzips request bodies of at least minSize bytes
+// (DefaultCompressionThreshold if zero), e.g. for bulk invoice creation. Request
+// signatures are computed over the uncompressed body.
+func WithRequestCompression(minSize int) Option {
+	return func(c *Client) {
+		if minSize <= 0 {
+			minSize = DefaultCompressionThreshold
+		}
+		c.compression.requestMinSize = minSize
+	}
+}
+
+// compression holds the settings of WithCompression and WithRequestCompression
+type compression struct {
+	responses      bool
+	requestMinSize int
+}
+
+// enabled reports whether any compression was configured
+func (cfg compression) enabled() bool {
+	return cfg.responses || cfg.requestMinSize > 0
+}
+
+// intercept compresses request bodies and decompresses responses. It runs innermost,
+// so interceptors, the journal and debug dumps see uncompressed bodies.
+func (cfg compression) intercept(next RoundTripFunc) RoundTripFunc {
+	return func(req *http.Request) (*http.Response, error) {
+		if cfg.requestMinSize > 0 && req.ContentLength >= int64(cfg.requestMinSize) && req.GetBody != nil {
+			compressed, err := gzipRequest(req)
+			if err != nil {
+				return nil, err
+			}
+			req = compressed
+		}
+		if cfg.responses {
+			// Setting the header ourselves turns off the transport's transparent
+			// decompression, so decompression does not depend on the transport
+			req.Header.Set("Accept-Encoding", "gzip")
+		}
+
+		resp, err := next(req)
+		if err != nil || !cfg.responses || !strings.EqualFold(resp.Header.Get("Content-Encoding"), "gzip") {
+			return resp, err
+		}
+		reader, err := gzip.NewReader(resp.Body)
+		if err != nil {
+			resp.Body.Close()
+			return nil, fmt.Errorf("failed to decompress response: %w", err)
+		}
+		resp.Body = &gzipBody{Reader: reader, body: resp.Body}
+		resp.Header.Del("Content-Encoding")
+		resp.Header.Del("Content-Length")
+		resp.ContentLength = -1
+		resp.Uncompressed = true
+		return resp, nil
+	}
+}
+
+// gzipRequest returns a copy of req with a gzip-compressed body
+func gzipRequest(req *http.Request) (*http.Request, error) {
+	body, err := req.GetBody()
+	if err != nil {
+		return nil, err
+	}
+	defer body.Close()
+
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	if _, err := io.Copy(zw, body); err != nil {
+		return nil, fmt.Errorf("failed to compress request body: %w", err)
+	}
+	if err := zw.Close(); err != nil {
+		return nil, fmt.Errorf("failed to compress request body: %w", err)
+	}
+
+	compressed := buf.Bytes()
+	clone := req.Clone(req.Context())
+	clone.Body = io.NopCloser(bytes.NewReader(compressed))
+	clone.GetBody = func() (io.ReadCloser, error) {
+		return io.NopCloser(bytes.NewReader(compressed)), nil
+	}
+	clone.ContentLength = int64(len(compressed))
+	clone.Header.Set("Content-Encoding", "gzip")
+	return clone, nil
+}
+
+// gzipBody is a decompressed response body closing the underlying body
+type gzipBody struct {
+	*gzip.Reader
+	body io.ReadCloser
+}
+
+// Close closes the decompressor and the underlying body
+func (b *gzipBody) Close() error {
+	b.Reader.Close()
+	return b.body.Close()
+}
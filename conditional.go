@@ -0,0 +1,121 @@
+package itispay
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+)
+
+// conditionalPaths are the endpoints WithConditionalRequests revalidates
+var conditionalPaths = map[string]bool{
+	"/currencies": true,
+	"/rates":      true,
+}
+
+// WithConditionalRequests caches the responses of GetCurrencies and GetRates and
+// revalidates them with If-None-Match and If-Modified-Since. When the API answers 304 Not
+// Modified, the cached response is returned, saving latency, bandwidth and quota for
+// clients that poll these endpoints.
+func WithConditionalRequests() Option {
+	return func(c *Client) {
+		c.conditional = &conditionalCache{entries: make(map[string]*conditionalEntry)}
+	}
+}
+
+// conditionalEntry is a cached response with its validators
+type conditionalEntry struct {
+	etag         string
+	lastModified string
+	header       http.Header
+	body         []byte
+}
+
+// conditionalCache holds the last response of each revalidated URL
+type conditionalCache struct {
+	mu      sync.Mutex
+	entries map[string]*conditionalEntry
+}
+
+// intercept adds validators to requests for cached responses and replays the cached
+// response on 304 Not Modified
+func (cc *conditionalCache) intercept(next RoundTripFunc) RoundTripFunc {
+	return func(req *http.Request) (*http.Response, error) {
+		if req.Method != http.MethodGet || !cc.applies(req) {
+			return next(req)
+		}
+
+		key := req.URL.String()
+		cc.mu.Lock()
+		entry := cc.entries[key]
+		cc.mu.Unlock()
+		if entry != nil {
+			req = req.Clone(req.Context())
+			if entry.etag != "" {
+				req.Header.Set("If-None-Match", entry.etag)
+			}
+			if entry.lastModified != "" {
+				req.Header.Set("If-Modified-Since", entry.lastModified)
+			}
+		}
+
+		resp, err := next(req)
+		if err != nil {
+			return resp, err
+		}
+		switch {
+		case resp.StatusCode == http.StatusNotModified && entry != nil:
+			resp.Body.Close()
+			header := entry.header.Clone()
+			// Validators may be refreshed on a 304
+			for _, name := range []string{"Etag", "Last-Modified", "Date", "Cache-Control"} {
+				if value := resp.Header.Get(name); value != "" {
+					header.Set(name, value)
+				}
+			}
+			return &http.Response{
+				Status:        "200 OK",
+				StatusCode:    http.StatusOK,
+				Proto:         resp.Proto,
+				ProtoMajor:    resp.ProtoMajor,
+				ProtoMinor:    resp.ProtoMinor,
+				Header:        header,
+				Body:          io.NopCloser(bytes.NewReader(entry.body)),
+				ContentLength: int64(len(entry.body)),
+				Request:       req,
+			}, nil
+		case resp.StatusCode == http.StatusOK:
+			etag, lastModified := resp.Header.Get("ETag"), resp.Header.Get("Last-Modified")
+			if etag == "" && lastModified == "" {
+				return resp, nil
+			}
+			body, err := io.ReadAll(resp.Body)
+			resp.Body.Close()
+			if err != nil {
+				return nil, err
+			}
+			resp.Body = io.NopCloser(bytes.NewReader(body))
+			cc.mu.Lock()
+			cc.entries[key] = &conditionalEntry{
+				etag:         etag,
+				lastModified: lastModified,
+				header:       resp.Header.Clone(),
+				body:         body,
+			}
+			cc.mu.Unlock()
+		}
+		return resp, nil
+	}
+}
+
+// applies reports whether req targets a revalidated endpoint
+func (cc *conditionalCache) applies(req *http.Request) bool {
+	// The base URL may carry a path prefix such as /api/v1
+	for path := range conditionalPaths {
+		if strings.HasSuffix(req.URL.Path, path) {
+			return true
+		}
+	}
+	return false
+}
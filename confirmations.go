@@ -0,0 +1,27 @@
+package itispay
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrInvalidConfirmations is returned when CreateInvoiceRequest.RequiredConfirmations
+// is negative
+var ErrInvalidConfirmations = errors.New("itispay: invalid required confirmations")
+
+// validateConfirmations checks the requested confirmation threshold
+func validateConfirmations(required *int) error {
+	if required != nil && *required < 0 {
+		return fmt.Errorf("%w: %d", ErrInvalidConfirmations, *required)
+	}
+	return nil
+}
+
+// ConfirmationsRemaining returns the number of confirmations the payment still needs
+// before the invoice completes, 0 once the threshold is reached
+func (i *Invoice) ConfirmationsRemaining() int {
+	if i.CurrentConfirmations >= i.RequiredConfirmations {
+		return 0
+	}
+	return i.RequiredConfirmations - i.CurrentConfirmations
+}
@@ -0,0 +1,83 @@
+package itispay
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+)
+
+// CredentialsProvider supplies the API key for each request, allowing keys to be loaded
+// from a secrets manager and rotated at runtime. Implementations must be safe for
+// concurrent use and should cache keys rather than fetch them on every call.
+type CredentialsProvider interface {
+	GetAPIKey(ctx context.Context) (string, error)
+}
+
+// CredentialsRefresher is implemented by providers that can discard a cached key.
+// When a request fails with HTTP 401 the client calls Refresh, fetches the key again
+// and retries the request once.
+type CredentialsRefresher interface {
+	CredentialsProvider
+	Refresh(ctx context.Context) error
+}
+
+// CredentialsProviderFunc adapts a function to the CredentialsProvider interface
+type CredentialsProviderFunc func(ctx context.Context) (string, error)
+
+// GetAPIKey calls f
+func (f CredentialsProviderFunc) GetAPIKey(ctx context.Context) (string, error) {
+	return f(ctx)
+}
+
+// StaticCredentials is a CredentialsProvider returning a fixed API key
+type StaticCredentials string
+
+// GetAPIKey returns the static key
+func (s StaticCredentials) GetAPIKey(context.Context) (string, error) {
+	return string(s), nil
+}
+
+// WithCredentialsProvider makes the client obtain its API key from provider, taking
+// precedence over the key passed to NewClient
+func WithCredentialsProvider(provider CredentialsProvider) Option {
+	return func(c *Client) {
+		c.credentials = provider
+	}
+}
+
+// resolveAPIKey returns the API key to use for a request
+func (c *Client) resolveAPIKey(ctx context.Context) (string, error) {
+	if c.credentials == nil {
+		return c.apiKey, nil
+	}
+	apiKey, err := c.credentials.GetAPIKey(ctx)
+	if err != nil {
+		return "", fmt.Errorf("failed to get API key: %w", err)
+	}
+	return apiKey, nil
+}
+
+// refreshAPIKey obtains a fresh key after an authentication failure. It reports false
+// when no credentials provider is configured or the key did not change, in which case
+// retrying would fail again.
+func (c *Client) refreshAPIKey(ctx context.Context, previous string) (string, bool) {
+	if c.credentials == nil {
+		return "", false
+	}
+	if refresher, ok := c.credentials.(CredentialsRefresher); ok {
+		if err := refresher.Refresh(ctx); err != nil {
+			return "", false
+		}
+	}
+	apiKey, err := c.credentials.GetAPIKey(ctx)
+	if err != nil || apiKey == previous {
+		return "", false
+	}
+	return apiKey, true
+}
+
+// isUnauthorized reports whether err is an HTTP 401 response, from the API or a gateway
+func isUnauthorized(err error) bool {
+	statusCode, ok := errorStatusCode(err)
+	return ok && statusCode == http.StatusUnauthorized
+}
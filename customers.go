@@ -0,0 +1,51 @@
+package itispay
+
+import (
+	"context"
+	"net/url"
+	"time"
+)
+
+// Customer represents a buyer whose invoices are grouped for payment history
+type Customer struct {
+	ID    string `json:"id"`
+	Email string `json:"email,omitempty"`
+	Name  string `json:"name,omitempty"`
+	// ExternalRefs correlates the customer with your own entities, e.g. {"user_id": "42"}
+	ExternalRefs map[string]string `json:"external_refs,omitempty"`
+	Metadata     map[string]string `json:"metadata,omitempty"`
+	CreatedAt    time.Time         `json:"created_at"`
+}
+
+// CreateCustomerRequest represents the request to create a customer
+type CreateCustomerRequest struct {
+	Email        string            `json:"email,omitempty"`
+	Name         string            `json:"name,omitempty"`
+	ExternalRefs map[string]string `json:"external_refs,omitempty"`
+	// Metadata holds your own key-value data; see ValidateMetadata for the limits
+	Metadata map[string]string `json:"metadata,omitempty"`
+}
+
+// CreateCustomer creates a customer. Pass its ID as CreateInvoiceRequest.CustomerID to
+// associate invoices with it.
+func (c *Client) CreateCustomer(ctx context.Context, req CreateCustomerRequest, opts ...RequestOption) (*Customer, error) {
+	if err := ValidateMetadata(req.Metadata); err != nil {
+		return nil, err
+	}
+	return do[Customer](ctx, c, "POST", "/customers", req, opts...)
+}
+
+// GetCustomer retrieves a customer by ID
+func (c *Client) GetCustomer(ctx context.Context, customerID string, opts ...RequestOption) (*Customer, error) {
+	return do[Customer](ctx, c, "GET", "/customers/"+url.PathEscape(customerID), nil, opts...)
+}
+
+// ListCustomerInvoices lists the invoices of a customer, e.g. to show a buyer's payment
+// history. params filters and pages them as in ListInvoices.
+func (c *Client) ListCustomerInvoices(ctx context.Context, customerID string, params ListInvoicesParams, opts ...RequestOption) (*ListInvoicesResponse, error) {
+	path := "/customers/" + url.PathEscape(customerID) + "/invoices"
+	if query := params.query(); len(query) > 0 {
+		path += "?" + query.Encode()
+	}
+	return do[ListInvoicesResponse](ctx, c, "GET", path, nil, opts...)
+}
@@ -0,0 +1,212 @@
+package itispay
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httputil"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// redacted replaces sensitive values in debug output
+const redacted = "[REDACTED]"
+
+// sensitiveHeaders lists headers whose values are never written to debug output
+var sensitiveHeaders = []string{
+	"Api-Key",
+	"Authorization",
+	"Cookie",
+	"Set-Cookie",
+	StepUpTokenHeader,
+}
+
+// sensitiveFields lists JSON fields whose values are never written to debug output
+var sensitiveFields = map[string]bool{
+	"api_key":         true,
+	"approver_token":  true,
+	"callback_secret": true,
+	"password":        true,
+	"secret":          true,
+	"session_token":   true,
+	"step_up_token":   true,
+	"token":           true,
+	"webhook_secret":  true,
+}
+
+// debugDumper writes redacted HTTP dumps to a writer
+type debugDumper struct {
+	mu      sync.Mutex
+	w       io.Writer
+	enabled atomic.Bool
+}
+
+// WithDebugTransport dumps every HTTP request and response to w, with API keys and
+// callback secrets redacted. Dumping can be toggled at runtime with Client.SetDebug.
+func WithDebugTransport(w io.Writer) Option {
+	return func(c *Client) {
+		c.debug = &debugDumper{w: w}
+		c.debug.enabled.Store(true)
+	}
+}
+
+// SetDebug enables or disables debug dumping at runtime. It has no effect unless the
+// client was created with WithDebugTransport.
+func (c *Client) SetDebug(enabled bool) {
+	if c.debug != nil {
+		c.debug.enabled.Store(enabled)
+	}
+}
+
+// intercept returns an Interceptor dumping the requests passing through it
+func (d *debugDumper) intercept(next RoundTripFunc) RoundTripFunc {
+	return func(req *http.Request) (*http.Response, error) {
+		if !d.enabled.Load() {
+			return next(req)
+		}
+
+		d.dumpRequest(req)
+		resp, err := next(req)
+		if err != nil {
+			d.write(fmt.Sprintf("<-- %s %s failed: %v\n\n", req.Method, req.URL.Path, err))
+			return resp, err
+		}
+		d.dumpResponse(resp)
+		return resp, nil
+	}
+}
+
+// dumpRequest writes a redacted dump of req
+func (d *debugDumper) dumpRequest(req *http.Request) {
+	clone := req.Clone(req.Context())
+	clone.Header = redactHeaders(req.Header)
+	if req.GetBody != nil {
+		body, err := req.GetBody()
+		if err == nil {
+			raw, _ := io.ReadAll(body)
+			body.Close()
+			redactedBody := redactBody(raw)
+			clone.Body = io.NopCloser(bytes.NewReader(redactedBody))
+			clone.ContentLength = int64(len(redactedBody))
+		}
+	}
+
+	dump, err := httputil.DumpRequestOut(clone, true)
+	if err != nil {
+		d.write(fmt.Sprintf("--> %s %s (dump failed: %v)\n\n", req.Method, req.URL.Path, err))
+		return
+	}
+	d.write("--> " + string(dump) + "\n\n")
+}
+
+// dumpResponse writes a redacted dump of resp, leaving its body readable
+func (d *debugDumper) dumpResponse(resp *http.Response) {
+	raw, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	resp.Body = io.NopCloser(bytes.NewReader(raw))
+	if err != nil {
+		d.write(fmt.Sprintf("<-- %s (body read failed: %v)\n\n", resp.Status, err))
+		return
+	}
+
+	clone := *resp
+	clone.Header = redactHeaders(resp.Header)
+	redactedBody := redactBody(raw)
+	clone.Body = io.NopCloser(bytes.NewReader(redactedBody))
+	clone.ContentLength = int64(len(redactedBody))
+
+	dump, err := httputil.DumpResponse(&clone, true)
+	if err != nil {
+		d.write(fmt.Sprintf("<-- %s (dump failed: %v)\n\n", resp.Status, err))
+		return
+	}
+	d.write("<-- " + string(dump) + "\n\n")
+}
+
+// write serializes writes to the underlying writer
+func (d *debugDumper) write(s string) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	io.WriteString(d.w, s)
+}
+
+// redactHeaders returns a copy of h with sensitive header values replaced
+func redactHeaders(h http.Header) http.Header {
+	clone := h.Clone()
+	for _, name := range sensitiveHeaders {
+		if clone.Get(name) != "" {
+			clone.Set(name, redacted)
+		}
+	}
+	return clone
+}
+
+// redactBody replaces sensitive values in a JSON body. Non-JSON bodies are returned unchanged.
+func redactBody(body []byte) []byte {
+	if len(body) == 0 {
+		return body
+	}
+	// Numbers are kept as written: float64 would round large amounts and IDs
+	var value interface{}
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.UseNumber()
+	if err := decoder.Decode(&value); err != nil || decoder.More() {
+		return body
+	}
+	redactedBody, err := json.Marshal(redactValue(value))
+	if err != nil {
+		return body
+	}
+	return redactedBody
+}
+
+// redactValue walks a decoded JSON value redacting sensitive fields
+func redactValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, field := range v {
+			switch {
+			case sensitiveFields[strings.ToLower(key)]:
+				v[key] = redacted
+			case strings.HasSuffix(strings.ToLower(key), "_url"):
+				if s, ok := field.(string); ok {
+					v[key] = redactURLQuery(s)
+				}
+			default:
+				v[key] = redactValue(field)
+			}
+		}
+	case []interface{}:
+		for i, item := range v {
+			v[i] = redactValue(item)
+		}
+	}
+	return value
+}
+
+// redactURLQuery hides query parameter values and credentials of a URL, which
+// commonly carry callback secrets
+func redactURLQuery(raw string) string {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return raw
+	}
+	if u.User != nil {
+		u.User = url.User(redacted)
+	}
+	if u.RawQuery != "" {
+		query := u.Query()
+		params := make([]string, 0, len(query))
+		for key := range query {
+			params = append(params, url.QueryEscape(key)+"="+redacted)
+		}
+		sort.Strings(params)
+		u.RawQuery = strings.Join(params, "&")
+	}
+	return u.String()
+}
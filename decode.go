@@ -0,0 +1,191 @@
+package itispay
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+)
+
+// snippetRadius is the number of bytes shown on each side of a decode error
+const snippetRadius = 80
+
+// DecodeError is returned when an API response cannot be decoded. It identifies the
+// endpoint, the offending field and includes a sanitized excerpt of the response body.
+type DecodeError struct {
+	// Endpoint is the request that produced the response, e.g. "GET /invoices/{id}"
+	Endpoint string
+	// Field is the dotted path of the offending field, if known
+	Field string
+	// Offset is the byte offset in the body where decoding failed
+	Offset int64
+	// Snippet is a truncated excerpt of the body around Offset with secrets redacted
+	Snippet string
+	Err     error
+}
+
+// Error returns the error message
+func (e *DecodeError) Error() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "decoding %s response", e.Endpoint)
+	if e.Field != "" {
+		fmt.Fprintf(&b, " at field %q", e.Field)
+	}
+	b.WriteString(": ")
+	b.WriteString(e.Err.Error())
+	if e.Snippet != "" {
+		fmt.Fprintf(&b, " (body: %s)", e.Snippet)
+	}
+	return b.String()
+}
+
+// Unwrap returns the underlying decoding error
+func (e *DecodeError) Unwrap() error {
+	return e.Err
+}
+
+// WithStrictDecoding makes responses containing fields unknown to the client fail with
+// a *DecodeError instead of silently dropping them. Enable it in staging to catch API
+// changes before they reach production.
+func WithStrictDecoding() Option {
+	return func(c *Client) {
+		c.strictDecoding = true
+	}
+}
+
+// apiResponse holds a successful API response
+type apiResponse struct {
+	endpoint   string
+	statusCode int
+	header     http.Header
+	body       []byte
+	strict     bool
+	// codec decodes the body unless strict, encoding/json if nil
+	codec Codec
+}
+
+// decode unmarshals the response body into v, returning a *DecodeError on failure
+func (r *apiResponse) decode(v interface{}) error {
+	if !r.strict {
+		codec := r.codec
+		if codec == nil {
+			codec = JSONCodec{}
+		}
+		if err := codec.Unmarshal(r.body, v); err != nil {
+			return newDecodeError(r.endpoint, r.body, err)
+		}
+		return nil
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(r.body))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(v); err != nil {
+		decodeErr := newDecodeError(r.endpoint, r.body, err)
+		if field, ok := unknownField(err); ok {
+			decodeErr.Field = field
+			decodeErr.Offset = dec.InputOffset()
+			decodeErr.Snippet = bodySnippet(r.body, decodeErr.Offset)
+		}
+		return decodeErr
+	}
+	if dec.More() {
+		offset := dec.InputOffset()
+		return &DecodeError{
+			Endpoint: r.endpoint,
+			Offset:   offset,
+			Snippet:  bodySnippet(r.body, offset),
+			Err:      errors.New("unexpected data after JSON value"),
+		}
+	}
+	return nil
+}
+
+// unknownField extracts the field name from the error reported by DisallowUnknownFields
+func unknownField(err error) (string, bool) {
+	name, ok := strings.CutPrefix(err.Error(), "json: unknown field ")
+	if !ok {
+		return "", false
+	}
+	return strings.Trim(name, `"`), true
+}
+
+// newDecodeError wraps a JSON decoding error with its location in body
+func newDecodeError(endpoint string, body []byte, err error) *DecodeError {
+	decodeErr := &DecodeError{Endpoint: endpoint, Err: err}
+
+	var typeErr *json.UnmarshalTypeError
+	var syntaxErr *json.SyntaxError
+	switch {
+	case errors.As(err, &typeErr):
+		decodeErr.Field = typeErr.Field
+		decodeErr.Offset = typeErr.Offset
+	case errors.As(err, &syntaxErr):
+		decodeErr.Offset = syntaxErr.Offset
+	}
+
+	decodeErr.Snippet = bodySnippet(body, decodeErr.Offset)
+	return decodeErr
+}
+
+// bodySnippet returns a redacted excerpt of body around offset
+func bodySnippet(body []byte, offset int64) string {
+	body = maskSensitiveValues(body)
+
+	start := int(offset) - snippetRadius
+	if start < 0 {
+		start = 0
+	}
+	end := int(offset) + snippetRadius
+	if end > len(body) {
+		end = len(body)
+	}
+	if start > end {
+		start = end
+	}
+
+	// Avoid cutting multi-byte characters in half
+	for start > 0 && !utf8.RuneStart(body[start]) {
+		start--
+	}
+	for end < len(body) && !utf8.RuneStart(body[end]) {
+		end++
+	}
+
+	snippet := string(body[start:end])
+	if start > 0 {
+		snippet = "…" + snippet
+	}
+	if end < len(body) {
+		snippet += "…"
+	}
+	return snippet
+}
+
+// sensitiveValuePattern matches string values of sensitive fields, including ones cut
+// off by truncated or malformed JSON
+var sensitiveValuePattern = regexp.MustCompile(`"(?i:` + sensitiveFieldPattern() + `)"\s*:\s*"([^"]*)`)
+
+// sensitiveFieldPattern returns an alternation of the sensitive JSON field names
+func sensitiveFieldPattern() string {
+	names := make([]string, 0, len(sensitiveFields))
+	for name := range sensitiveFields {
+		names = append(names, regexp.QuoteMeta(name))
+	}
+	return strings.Join(names, "|")
+}
+
+// maskSensitiveValues returns a copy of body with the values of sensitive fields
+// replaced by asterisks. Lengths are preserved so error offsets remain valid.
+func maskSensitiveValues(body []byte) []byte {
+	masked := append([]byte(nil), body...)
+	for _, match := range sensitiveValuePattern.FindAllSubmatchIndex(masked, -1) {
+		for i := match[2]; i < match[3]; i++ {
+			masked[i] = '*'
+		}
+	}
+	return masked
+}
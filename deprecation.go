@@ -0,0 +1,98 @@
+package itispay
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DeprecationWarning describes deprecation information returned by the API through the
+// Deprecation (RFC 9745), Sunset (RFC 8594) and Warning headers
+type DeprecationWarning struct {
+	// Endpoint is the request that returned the warning, e.g. "GET /invoices/{id}"
+	Endpoint string
+	// Deprecated reports whether the endpoint is marked as deprecated
+	Deprecated bool
+	// DeprecatedAt is when the endpoint was or will be deprecated, if announced
+	DeprecatedAt time.Time
+	// Sunset is when the endpoint will stop working, if announced
+	Sunset time.Time
+	// Links are the deprecation and sunset documentation links from the Link header
+	Links []string
+	// Warnings are the raw Warning header values
+	Warnings []string
+}
+
+// DeprecationMetrics is an optional extension of Metrics counting deprecation warnings
+type DeprecationMetrics interface {
+	IncDeprecationWarning(endpoint string)
+}
+
+// WithDeprecationHandler calls handler for every response carrying deprecation, sunset
+// or warning headers, so integrators learn about upcoming breaking changes. Without a
+// handler the warnings are only counted by Metrics implementing DeprecationMetrics.
+func WithDeprecationHandler(handler func(DeprecationWarning)) Option {
+	return func(c *Client) {
+		c.onDeprecation = handler
+	}
+}
+
+// reportDeprecation inspects response headers and reports any deprecation warning
+func (c *Client) reportDeprecation(endpoint string, header http.Header) {
+	warning, ok := parseDeprecationHeaders(endpoint, header)
+	if !ok {
+		return
+	}
+	if m, ok := c.metrics.(DeprecationMetrics); ok {
+		m.IncDeprecationWarning(endpoint)
+	}
+	if c.onDeprecation != nil {
+		c.onDeprecation(warning)
+	}
+}
+
+// parseDeprecationHeaders extracts deprecation information from response headers
+func parseDeprecationHeaders(endpoint string, header http.Header) (DeprecationWarning, bool) {
+	warning := DeprecationWarning{
+		Endpoint: endpoint,
+		Warnings: header.Values("Warning"),
+	}
+
+	if deprecation := strings.TrimSpace(header.Get("Deprecation")); deprecation != "" {
+		warning.Deprecated = true
+		warning.DeprecatedAt = parseDeprecationDate(deprecation)
+	}
+	if sunset := header.Get("Sunset"); sunset != "" {
+		if t, err := http.ParseTime(sunset); err == nil {
+			warning.Sunset = t
+		}
+	}
+	for _, link := range header.Values("Link") {
+		for _, part := range strings.Split(link, ",") {
+			if strings.Contains(part, `rel="deprecation"`) || strings.Contains(part, `rel="sunset"`) ||
+				strings.Contains(part, "rel=deprecation") || strings.Contains(part, "rel=sunset") {
+				if start, end := strings.IndexByte(part, '<'), strings.IndexByte(part, '>'); start >= 0 && end > start {
+					warning.Links = append(warning.Links, part[start+1:end])
+				}
+			}
+		}
+	}
+
+	ok := warning.Deprecated || !warning.Sunset.IsZero() || len(warning.Warnings) > 0
+	return warning, ok
+}
+
+// parseDeprecationDate parses a Deprecation header value: "@<unix seconds>" (RFC 9745),
+// an HTTP date (earlier drafts) or "true"
+func parseDeprecationDate(value string) time.Time {
+	if strings.HasPrefix(value, "@") {
+		if seconds, err := strconv.ParseInt(value[1:], 10, 64); err == nil {
+			return time.Unix(seconds, 0).UTC()
+		}
+	}
+	if t, err := http.ParseTime(value); err == nil {
+		return t
+	}
+	return time.Time{}
+}
@@ -0,0 +1,44 @@
+package itispay
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+// DryRunHeader asks the API to validate a mutating request without applying it. The API
+// echoes it on responses to dry runs.
+const DryRunHeader = "X-Dry-Run"
+
+// ErrDryRunNotHonored is returned when the response to a dry run does not confirm it was
+// one, meaning the API may have applied the request
+var ErrDryRunNotHonored = errors.New("itispay: dry run not honored")
+
+// WithDryRun makes every mutating request of the client a dry run: the API validates it
+// and returns the resource it would create or update, without creating anything. Use it
+// for pre-flight checks in deployment pipelines. GET requests are unaffected.
+func WithDryRun() Option {
+	return func(c *Client) {
+		c.dryRun = true
+	}
+}
+
+// WithRequestDryRun makes this call a dry run; see WithDryRun
+func WithRequestDryRun() RequestOption {
+	return func(o *requestOptions) {
+		o.dryRun = true
+	}
+}
+
+// isDryRun reports whether a request is a dry run
+func (c *Client) isDryRun(method string, options *requestOptions) bool {
+	return method != http.MethodGet && (c.dryRun || options.dryRun)
+}
+
+// checkDryRun verifies that the API treated a dry run as one
+func checkDryRun(method, path string, resp *apiResponse) error {
+	if resp.header.Get(DryRunHeader) != "true" {
+		return fmt.Errorf("%w: %s %s", ErrDryRunNotHonored, method, path)
+	}
+	return nil
+}
@@ -0,0 +1,276 @@
+package itispay
+
+import (
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultDuplicateWindow is how long the DuplicateDetector remembers payments
+const DefaultDuplicateWindow = 30 * 24 * time.Hour
+
+// duplicatePruneInterval is how often the DuplicateDetector forgets payments older than
+// its window, at most
+const duplicatePruneInterval = time.Hour
+
+// Duplicate kinds
+const (
+	// DuplicateTxHash means one transaction was attributed to several invoices
+	DuplicateTxHash = "tx_hash"
+	// DuplicateAddress means payments to one address were attributed to several invoices
+	DuplicateAddress = "address"
+)
+
+// PaymentAttempt is a payment detected for an invoice
+type PaymentAttempt struct {
+	InvoiceID  string
+	OrderID    string
+	Currency   string
+	TxHash     string
+	Address    string
+	Amount     float64
+	DetectedAt time.Time
+}
+
+// DuplicatePayment reports a transaction or address attributed to multiple invoices
+type DuplicatePayment struct {
+	// Kind is DuplicateTxHash or DuplicateAddress
+	Kind string
+	// Key is the transaction hash or address
+	Key        string
+	InvoiceIDs []string
+	Attempts   []PaymentAttempt
+}
+
+// PaymentAttemptsFromWebhook returns the payment attempts reported by a webhook, one per
+// transaction
+func PaymentAttemptsFromWebhook(payload *WebhookPayload) []PaymentAttempt {
+	address := ""
+	if payload.BlockchainDetails != nil {
+		address = payload.BlockchainDetails.BlockchainAddress
+	}
+
+	attempts := make([]PaymentAttempt, 0, len(payload.Transactions))
+	for _, tx := range payload.Transactions {
+		attempts = append(attempts, PaymentAttempt{
+			InvoiceID:  payload.InvoiceID,
+			OrderID:    payload.OrderID,
+			Currency:   payload.Currency,
+			TxHash:     tx.TxHash,
+			Address:    address,
+			Amount:     tx.Amount,
+			DetectedAt: tx.DetectedAt,
+		})
+	}
+	return attempts
+}
+
+// DuplicateDetector flags payments attributed to more than one invoice before orders
+// are fulfilled twice. Feed it every detected payment, e.g. from webhooks, and check
+// the result before fulfilling. A duplicate is reported once, when a payment brings a
+// new invoice into it; redeliveries are not reported again. It is safe for concurrent
+// use.
+type DuplicateDetector struct {
+	// Window is how long payments are remembered after being observed,
+	// DefaultDuplicateWindow if zero
+	Window time.Duration
+	// OnDuplicate, if set, is called for every duplicate found
+	OnDuplicate func(DuplicatePayment)
+	// Clock stamps observations and tells the time for pruning the window, SystemClock
+	// if nil
+	Clock Clock
+
+	mu       sync.Mutex
+	byTx     map[string][]observedAttempt
+	byAddr   map[string][]observedAttempt
+	prunedAt time.Time
+}
+
+// observedAttempt is a payment attempt and when the detector observed it
+type observedAttempt struct {
+	PaymentAttempt
+	observedAt time.Time
+}
+
+// NewDuplicateDetector returns a detector calling onDuplicate (which may be nil) for
+// every duplicate found
+func NewDuplicateDetector(onDuplicate func(DuplicatePayment)) *DuplicateDetector {
+	return &DuplicateDetector{OnDuplicate: onDuplicate}
+}
+
+// Observe records a payment attempt and returns the duplicates it completes
+func (d *DuplicateDetector) Observe(attempt PaymentAttempt) []DuplicatePayment {
+	d.mu.Lock()
+	if d.byTx == nil {
+		d.byTx = make(map[string][]observedAttempt)
+		d.byAddr = make(map[string][]observedAttempt)
+	}
+	now := clockOrSystem(d.Clock).Now()
+	d.prune(now)
+
+	var found []DuplicatePayment
+	if attempt.TxHash != "" {
+		if dup, ok := d.add(d.byTx, DuplicateTxHash, attempt.TxHash, attempt, now); ok {
+			found = append(found, dup)
+		}
+	}
+	if attempt.Address != "" {
+		if dup, ok := d.add(d.byAddr, DuplicateAddress, attempt.Address, attempt, now); ok {
+			found = append(found, dup)
+		}
+	}
+	d.mu.Unlock()
+
+	if d.OnDuplicate != nil {
+		for _, dup := range found {
+			d.OnDuplicate(dup)
+		}
+	}
+	return found
+}
+
+// add records attempt under the hash or address value and reports a duplicate if the
+// attempt brings a new invoice into a group spanning several; d.mu must be held
+func (d *DuplicateDetector) add(index map[string][]observedAttempt, kind, value string, attempt PaymentAttempt, now time.Time) (DuplicatePayment, bool) {
+	key := paymentKey(attempt.Currency, value)
+	group := index[key]
+	newInvoice := true
+	for _, existing := range group {
+		if existing.InvoiceID != attempt.InvoiceID {
+			continue
+		}
+		if existing.TxHash == attempt.TxHash {
+			// A redelivery
+			return DuplicatePayment{}, false
+		}
+		newInvoice = false
+	}
+	group = append(group, observedAttempt{PaymentAttempt: attempt, observedAt: now})
+	index[key] = group
+	if !newInvoice {
+		return DuplicatePayment{}, false
+	}
+
+	attempts := make([]PaymentAttempt, len(group))
+	for i, observed := range group {
+		attempts[i] = observed.PaymentAttempt
+	}
+	return duplicateOf(kind, value, attempts)
+}
+
+// ObserveWebhook records the payment attempts of a webhook and returns the duplicates
+func (d *DuplicateDetector) ObserveWebhook(payload *WebhookPayload) []DuplicatePayment {
+	var found []DuplicatePayment
+	for _, attempt := range PaymentAttemptsFromWebhook(payload) {
+		found = append(found, d.Observe(attempt)...)
+	}
+	return found
+}
+
+// prune forgets attempts observed before the window, at most once per
+// duplicatePruneInterval (or window, if shorter); d.mu must be held
+func (d *DuplicateDetector) prune(now time.Time) {
+	window := d.Window
+	if window <= 0 {
+		window = DefaultDuplicateWindow
+	}
+	interval := duplicatePruneInterval
+	if window < interval {
+		interval = window
+	}
+	if now.Sub(d.prunedAt) < interval {
+		return
+	}
+	d.prunedAt = now
+
+	cutoff := now.Add(-window)
+	for _, index := range []map[string][]observedAttempt{d.byTx, d.byAddr} {
+		for key, attempts := range index {
+			kept := attempts[:0]
+			for _, attempt := range attempts {
+				if attempt.observedAt.After(cutoff) {
+					kept = append(kept, attempt)
+				}
+			}
+			if len(kept) == 0 {
+				delete(index, key)
+			} else {
+				index[key] = kept
+			}
+		}
+	}
+}
+
+// DetectDuplicatePayments returns the transactions and addresses attributed to more than
+// one invoice among attempts, e.g. for a batch check over historical data
+func DetectDuplicatePayments(attempts []PaymentAttempt) []DuplicatePayment {
+	byTx := make(map[string][]PaymentAttempt)
+	byAddr := make(map[string][]PaymentAttempt)
+	for _, attempt := range attempts {
+		if attempt.TxHash != "" {
+			key := paymentKey(attempt.Currency, attempt.TxHash)
+			byTx[key] = appendAttempt(byTx[key], attempt)
+		}
+		if attempt.Address != "" {
+			key := paymentKey(attempt.Currency, attempt.Address)
+			byAddr[key] = appendAttempt(byAddr[key], attempt)
+		}
+	}
+
+	var found []DuplicatePayment
+	for _, group := range byTx {
+		if dup, ok := duplicateOf(DuplicateTxHash, group[0].TxHash, group); ok {
+			found = append(found, dup)
+		}
+	}
+	for _, group := range byAddr {
+		if dup, ok := duplicateOf(DuplicateAddress, group[0].Address, group); ok {
+			found = append(found, dup)
+		}
+	}
+	sort.Slice(found, func(i, j int) bool {
+		if found[i].Kind != found[j].Kind {
+			return found[i].Kind > found[j].Kind
+		}
+		return found[i].Key < found[j].Key
+	})
+	return found
+}
+
+// appendAttempt adds attempt unless the same invoice already has the same transaction,
+// so redelivered webhooks are not reported
+func appendAttempt(attempts []PaymentAttempt, attempt PaymentAttempt) []PaymentAttempt {
+	for _, existing := range attempts {
+		if existing.InvoiceID == attempt.InvoiceID && existing.TxHash == attempt.TxHash {
+			return attempts
+		}
+	}
+	return append(attempts, attempt)
+}
+
+// duplicateOf reports a duplicate if attempts span more than one invoice
+func duplicateOf(kind, key string, attempts []PaymentAttempt) (DuplicatePayment, bool) {
+	var invoiceIDs []string
+	seen := make(map[string]bool)
+	for _, attempt := range attempts {
+		if !seen[attempt.InvoiceID] {
+			seen[attempt.InvoiceID] = true
+			invoiceIDs = append(invoiceIDs, attempt.InvoiceID)
+		}
+	}
+	if len(invoiceIDs) < 2 {
+		return DuplicatePayment{}, false
+	}
+	return DuplicatePayment{
+		Kind:       kind,
+		Key:        key,
+		InvoiceIDs: invoiceIDs,
+		Attempts:   append([]PaymentAttempt(nil), attempts...),
+	}, true
+}
+
+// paymentKey scopes a hash or address to its currency
+func paymentKey(currency, value string) string {
+	return strings.ToUpper(currency) + ":" + value
+}
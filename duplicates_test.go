@@ -0,0 +1,65 @@
+package itispay_test
+
+import (
+	"testing"
+	"time"
+
+	itispay "github.com/ItIsPay/go-client"
+	"github.com/ItIsPay/go-client/itispaytest"
+)
+
+func TestDuplicateDetectorReportsNewInvoicesOnly(t *testing.T) {
+	var reported []itispay.DuplicatePayment
+	detector := itispay.NewDuplicateDetector(func(dup itispay.DuplicatePayment) { reported = append(reported, dup) })
+	attempt := func(invoiceID, txHash string) itispay.PaymentAttempt {
+		return itispay.PaymentAttempt{InvoiceID: invoiceID, Currency: "BTC", TxHash: txHash}
+	}
+
+	steps := []struct {
+		attempt itispay.PaymentAttempt
+		// want is the number of invoices of the duplicate reported, 0 if none
+		want int
+	}{
+		{attempt: attempt("inv-a", "tx-1")},
+		{attempt: attempt("inv-a", "tx-1")},
+		{attempt: attempt("inv-b", "tx-1"), want: 2},
+		// Redeliveries of either invoice are not reported again
+		{attempt: attempt("inv-a", "tx-1")},
+		{attempt: attempt("inv-b", "tx-1")},
+		{attempt: attempt("inv-c", "tx-1"), want: 3},
+	}
+	for i, step := range steps {
+		reported = nil
+		found := detector.Observe(step.attempt)
+		if len(found) != len(reported) {
+			t.Fatalf("step %d: %d duplicates returned, %d reported", i, len(found), len(reported))
+		}
+		switch {
+		case step.want == 0 && len(found) != 0:
+			t.Errorf("step %d: unexpected duplicate %+v", i, found)
+		case step.want != 0 && (len(found) != 1 || len(found[0].InvoiceIDs) != step.want):
+			t.Errorf("step %d: duplicates = %+v, want one spanning %d invoices", i, found, step.want)
+		}
+	}
+}
+
+func TestDuplicateDetectorWindow(t *testing.T) {
+	clock := itispaytest.NewFakeClock(time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC))
+	detector := &itispay.DuplicateDetector{Window: 24 * time.Hour, Clock: clock}
+
+	// Attempts without a detection time are forgotten too
+	detector.Observe(itispay.PaymentAttempt{InvoiceID: "inv-a", Currency: "BTC", TxHash: "tx-1"})
+	clock.Advance(12 * time.Hour)
+	detector.Observe(itispay.PaymentAttempt{InvoiceID: "inv-x", Currency: "BTC", TxHash: "tx-2"})
+	if found := detector.Observe(itispay.PaymentAttempt{InvoiceID: "inv-b", Currency: "BTC", TxHash: "tx-2"}); len(found) != 1 {
+		t.Fatalf("duplicate within the window not found: %+v", found)
+	}
+
+	clock.Advance(13 * time.Hour)
+	if found := detector.Observe(itispay.PaymentAttempt{InvoiceID: "inv-c", Currency: "BTC", TxHash: "tx-1"}); len(found) != 0 {
+		t.Errorf("attempt observed before the window reported: %+v", found)
+	}
+	if found := detector.Observe(itispay.PaymentAttempt{InvoiceID: "inv-d", Currency: "BTC", TxHash: "tx-2"}); len(found) != 1 {
+		t.Errorf("attempts observed within the window forgotten: %+v", found)
+	}
+}
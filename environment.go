@@ -0,0 +1,81 @@
+package itispay
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// Environment selects the ItIsPay environment the client talks to
+type Environment string
+
+// Environment constants
+const (
+	EnvProduction Environment = "production"
+	EnvSandbox    Environment = "sandbox"
+)
+
+// SandboxBaseURL is the ItIsPay sandbox API base URL
+const SandboxBaseURL = "https://sandbox-api.itispay.com/api/v1"
+
+// TestModeHeader marks requests made from the sandbox environment
+const TestModeHeader = "X-Test-Mode"
+
+// ErrSimulationInProduction is returned by SimulateWebhook when the client targets production
+var ErrSimulationInProduction = errors.New("itispay: webhook simulation is not allowed in production, use WithEnvironment(EnvSandbox)")
+
+// ErrResetInProduction is returned by ResetSandbox when the client targets production
+var ErrResetInProduction = errors.New("itispay: data reset is not allowed in production, use WithEnvironment(EnvSandbox)")
+
+// ErrUnknownEnvironment is returned by every request of a client configured with an
+// environment other than EnvProduction and EnvSandbox
+var ErrUnknownEnvironment = errors.New("itispay: unknown environment")
+
+// WithEnvironment selects the environment. Its base URL is used unless WithBaseURL or
+// WithBaseURLs sets one, whatever the order of the options. In the sandbox all requests
+// carry the X-Test-Mode header so created resources are marked as test data.
+func WithEnvironment(env Environment) Option {
+	return func(c *Client) {
+		c.environment = env
+	}
+}
+
+// resolveEnvironment checks the environment once all options have run and defaults the
+// base URL to the environment's
+func (c *Client) resolveEnvironment() {
+	var baseURL string
+	switch c.environment {
+	case EnvProduction:
+		baseURL = DefaultBaseURL
+	case EnvSandbox:
+		baseURL = SandboxBaseURL
+	default:
+		c.configErr = fmt.Errorf("%w %q", ErrUnknownEnvironment, c.environment)
+	}
+	if c.baseURL == "" {
+		c.baseURL = baseURL
+	}
+}
+
+// WithBaseURL overrides the API base URL, e.g. to target a proxy or a local test server
+func WithBaseURL(baseURL string) Option {
+	return func(c *Client) {
+		c.baseURL = baseURL
+		c.failover = nil
+	}
+}
+
+// Environment returns the environment the client is configured for
+func (c *Client) Environment() Environment {
+	return c.environment
+}
+
+// ResetSandbox deletes all test invoices and resets the balances of the sandbox account,
+// e.g. at the start of a CI run. It is only available in the sandbox environment.
+func (c *Client) ResetSandbox(ctx context.Context, opts ...RequestOption) error {
+	if c.environment != EnvSandbox {
+		return ErrResetInProduction
+	}
+	_, err := c.doRequest(ctx, "POST", "/sandbox/reset", nil, opts...)
+	return err
+}
@@ -0,0 +1,59 @@
+package itispay_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	itispay "github.com/ItIsPay/go-client"
+)
+
+func TestEnvironmentBaseURL(t *testing.T) {
+	const proxy = "https://proxy.example.com/api/v1"
+	tests := []struct {
+		name    string
+		opts    []itispay.Option
+		wantURL string
+		wantEnv itispay.Environment
+	}{
+		{name: "default", wantURL: itispay.DefaultBaseURL, wantEnv: itispay.EnvProduction},
+		{name: "sandbox", opts: []itispay.Option{itispay.WithEnvironment(itispay.EnvSandbox)}, wantURL: itispay.SandboxBaseURL, wantEnv: itispay.EnvSandbox},
+		{
+			name:    "base URL before environment",
+			opts:    []itispay.Option{itispay.WithBaseURL(proxy), itispay.WithEnvironment(itispay.EnvSandbox)},
+			wantURL: proxy,
+			wantEnv: itispay.EnvSandbox,
+		},
+		{
+			name:    "base URL after environment",
+			opts:    []itispay.Option{itispay.WithEnvironment(itispay.EnvSandbox), itispay.WithBaseURL(proxy)},
+			wantURL: proxy,
+			wantEnv: itispay.EnvSandbox,
+		},
+		{
+			name:    "base URLs before environment",
+			opts:    []itispay.Option{itispay.WithBaseURLs([]string{proxy, "https://backup.example.com"}), itispay.WithEnvironment(itispay.EnvProduction)},
+			wantURL: proxy,
+			wantEnv: itispay.EnvProduction,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := itispay.NewClient("key", tt.opts...)
+			if got := client.BaseURL(); got != tt.wantURL {
+				t.Errorf("BaseURL() = %s, want %s", got, tt.wantURL)
+			}
+			if got := client.Environment(); got != tt.wantEnv {
+				t.Errorf("Environment() = %s, want %s", got, tt.wantEnv)
+			}
+		})
+	}
+}
+
+func TestUnknownEnvironment(t *testing.T) {
+	client := itispay.NewClient("key", itispay.WithEnvironment("staging"))
+	if _, err := client.GetRates(context.Background()); !errors.Is(err, itispay.ErrUnknownEnvironment) {
+		t.Errorf("err = %v, want ErrUnknownEnvironment", err)
+	}
+}
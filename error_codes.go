@@ -0,0 +1,51 @@
+package itispay
+
+import (
+	"errors"
+
+	"github.com/ItIsPay/go-client/types"
+)
+
+// ErrorCode is a machine-readable API error code
+type ErrorCode = types.ErrorCode
+
+// API error codes
+const (
+	ErrCodeInvalidRequest      = types.ErrCodeInvalidRequest
+	ErrCodeUnauthorized        = types.ErrCodeUnauthorized
+	ErrCodeForbidden           = types.ErrCodeForbidden
+	ErrCodeNotFound            = types.ErrCodeNotFound
+	ErrCodeRateLimited         = types.ErrCodeRateLimited
+	ErrCodeInternal            = types.ErrCodeInternal
+	ErrCodeServiceUnavailable  = types.ErrCodeServiceUnavailable
+	ErrCodeInvalidStatus       = types.ErrCodeInvalidStatus
+	ErrCodeInvalidCurrency     = types.ErrCodeInvalidCurrency
+	ErrCodeUnsupportedCurrency = types.ErrCodeUnsupportedCurrency
+	ErrCodeInvalidNetwork      = types.ErrCodeInvalidNetwork
+	ErrCodeInvalidAddress      = types.ErrCodeInvalidAddress
+	ErrCodeAmountTooSmall      = types.ErrCodeAmountTooSmall
+	ErrCodeAmountTooLarge      = types.ErrCodeAmountTooLarge
+	ErrCodeDuplicateOrderID    = types.ErrCodeDuplicateOrderID
+	ErrCodeInvoiceExpired      = types.ErrCodeInvoiceExpired
+	ErrCodeInsufficientBalance = types.ErrCodeInsufficientBalance
+	ErrCodeIdempotencyConflict = types.ErrCodeIdempotencyConflict
+	ErrCodeQuoteExpired        = types.ErrCodeQuoteExpired
+	ErrCodeSessionRequired     = types.ErrCodeSessionRequired
+	ErrCodeSessionExpired      = types.ErrCodeSessionExpired
+	ErrCodeStepUpRequired      = types.ErrCodeStepUpRequired
+)
+
+// NewAPIError returns the error for a response with the given status code
+func NewAPIError(statusCode int, response ErrorResponse) *APIError {
+	return types.NewAPIError(statusCode, response)
+}
+
+// ErrorCodeOf returns the code of the API error in err's chain, or "" if err is not an
+// API error
+func ErrorCodeOf(err error) ErrorCode {
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.Code
+	}
+	return ""
+}
@@ -0,0 +1,286 @@
+package itispay
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/ItIsPay/go-client/types"
+)
+
+// Event type constants
+const (
+	EventInvoiceStatusChanged = types.EventInvoiceStatusChanged
+	EventInvoiceCreated       = types.EventInvoiceCreated
+	EventInvoiceCompleted     = types.EventInvoiceCompleted
+	EventPayoutSent           = types.EventPayoutSent
+	EventFiatPayoutUpdated    = types.EventFiatPayoutUpdated
+)
+
+// DefaultEventWait is how long StreamEvents asks the server to hold a request open
+// while waiting for new events
+const DefaultEventWait = 25 * time.Second
+
+// eventIdleDelay is how long StreamEvents pauses after an empty response, in case the
+// server answered without holding the request open
+const eventIdleDelay = time.Second
+
+// Event is an account event, carrying the same data as the corresponding webhook
+type Event struct {
+	ID        string          `json:"id"`
+	Type      string          `json:"type"`
+	CreatedAt time.Time       `json:"created_at"`
+	Data      json.RawMessage `json:"data"`
+}
+
+// WebhookPayload decodes the event data of an EventInvoiceStatusChanged event
+func (e *Event) WebhookPayload() (*WebhookPayload, error) {
+	var payload WebhookPayload
+	if err := json.Unmarshal(e.Data, &payload); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal event %s data: %w", e.ID, err)
+	}
+	if payload.EventID == "" {
+		payload.EventID = e.ID
+	}
+	return &payload, nil
+}
+
+// Invoice decodes the event data of an invoice.* event
+func (e *Event) Invoice() (*Invoice, error) {
+	var invoice Invoice
+	if err := json.Unmarshal(e.Data, &invoice); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal event %s data: %w", e.ID, err)
+	}
+	return &invoice, nil
+}
+
+// Payout decodes the event data of a payout.* event
+func (e *Event) Payout() (*Payout, error) {
+	var payout Payout
+	if err := json.Unmarshal(e.Data, &payout); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal event %s data: %w", e.ID, err)
+	}
+	return &payout, nil
+}
+
+// FiatPayout decodes the event data of a fiat_payout.* event
+func (e *Event) FiatPayout() (*FiatPayout, error) {
+	var payout FiatPayout
+	if err := json.Unmarshal(e.Data, &payout); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal event %s data: %w", e.ID, err)
+	}
+	return &payout, nil
+}
+
+// PullEventsResponse represents the response from pulling events
+type PullEventsResponse struct {
+	Events []Event `json:"events"`
+	// NextCursor is passed to the next PullEvents call
+	NextCursor string `json:"next_cursor"`
+	HasMore    bool   `json:"has_more"`
+}
+
+// ackEventsRequest represents the request to acknowledge events
+type ackEventsRequest struct {
+	EventIDs []string `json:"event_ids"`
+}
+
+// PullEvents fetches up to limit unacknowledged events after cursor (empty for the
+// oldest). It is an alternative to webhooks for deployments that cannot accept inbound
+// connections. Events are redelivered until acknowledged with AckEvents, so process
+// them idempotently.
+func (c *Client) PullEvents(ctx context.Context, cursor string, limit int, opts ...RequestOption) (*PullEventsResponse, error) {
+	queryParams := url.Values{}
+	if cursor != "" {
+		queryParams.Set("cursor", cursor)
+	}
+	if limit > 0 {
+		queryParams.Set("limit", strconv.Itoa(limit))
+	}
+
+	path := "/events/pull"
+	if len(queryParams) > 0 {
+		path += "?" + queryParams.Encode()
+	}
+
+	return do[PullEventsResponse](ctx, c, "GET", path, nil, opts...)
+}
+
+// AckEvents acknowledges processed events so they are not delivered again
+func (c *Client) AckEvents(ctx context.Context, eventIDs []string, opts ...RequestOption) error {
+	_, err := c.doRequest(ctx, "POST", "/events/ack", ackEventsRequest{EventIDs: eventIDs}, opts...)
+	return err
+}
+
+// ListEventsParams represents parameters for listing events
+type ListEventsParams struct {
+	// Types restricts the events to the given types, all types if empty
+	Types []string
+	Limit int
+	// Wait makes the server hold the request open for up to Wait when there are no
+	// new events (long polling)
+	Wait time.Duration
+}
+
+// ListEventsResponse represents the response from listing events
+type ListEventsResponse struct {
+	Events  []Event `json:"events"`
+	HasMore bool    `json:"has_more"`
+}
+
+// ListEvents returns the account's events after the event with ID since (empty for the
+// oldest retained event), oldest first. Unlike PullEvents, nothing is acknowledged: the
+// caller keeps its own cursor, the ID of the last event processed.
+func (c *Client) ListEvents(ctx context.Context, since string, params *ListEventsParams, opts ...RequestOption) (*ListEventsResponse, error) {
+	queryParams := url.Values{}
+	if since != "" {
+		queryParams.Set("since", since)
+	}
+	if params != nil {
+		for _, eventType := range params.Types {
+			queryParams.Add("type", eventType)
+		}
+		if params.Limit > 0 {
+			queryParams.Set("limit", strconv.Itoa(params.Limit))
+		}
+		if params.Wait > 0 {
+			queryParams.Set("wait", strconv.Itoa(int(params.Wait/time.Second)))
+		}
+	}
+
+	path := "/events"
+	if len(queryParams) > 0 {
+		path += "?" + queryParams.Encode()
+	}
+
+	return do[ListEventsResponse](ctx, c, "GET", path, nil, opts...)
+}
+
+// EventStream delivers events from StreamEvents
+type EventStream struct {
+	// C receives events in order; it is closed when the stream ends
+	C <-chan Event
+
+	mu     sync.Mutex
+	cursor string
+	done   chan struct{}
+	err    error
+}
+
+// Cursor returns the ID of the last event delivered on C, to resume from after a restart
+func (s *EventStream) Cursor() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.cursor
+}
+
+// Err returns the error that ended the stream, once C is closed. It is the context's
+// error when the stream was cancelled.
+func (s *EventStream) Err() error {
+	<-s.done
+	return s.err
+}
+
+// StreamEvents long-polls ListEvents and delivers events after since on the returned
+// stream until ctx is cancelled. Network and server errors are retried with backoff;
+// the stream ends on errors that retrying cannot fix, such as an invalid API key.
+// It is an alternative to webhooks for backends that cannot accept inbound connections:
+//
+//	stream := client.StreamEvents(ctx, lastEventID, nil)
+//	for event := range stream.C {
+//		handle(event)
+//	}
+//	err := stream.Err()
+func (c *Client) StreamEvents(ctx context.Context, since string, params *ListEventsParams, opts ...RequestOption) *EventStream {
+	listParams := ListEventsParams{}
+	if params != nil {
+		listParams = *params
+	}
+	if listParams.Wait <= 0 {
+		listParams.Wait = DefaultEventWait
+	}
+	// Leave room for the server to answer after holding the request for Wait
+	opts = append([]RequestOption{WithRequestTimeout(listParams.Wait + 10*time.Second)}, opts...)
+
+	events := make(chan Event)
+	stream := &EventStream{
+		C:      events,
+		cursor: since,
+		done:   make(chan struct{}),
+	}
+
+	go func() {
+		defer close(stream.done)
+		defer close(events)
+
+		failures := 0
+		for {
+			resp, err := c.ListEvents(ctx, since, &listParams, opts...)
+			if err != nil {
+				if ctx.Err() != nil {
+					stream.err = ctx.Err()
+					return
+				}
+				if !retryableEventError(err) {
+					stream.err = err
+					return
+				}
+				failures++
+				if err := sleep(ctx, c.clock, eventBackoff(failures)); err != nil {
+					stream.err = err
+					return
+				}
+				continue
+			}
+			failures = 0
+			if len(resp.Events) == 0 && !resp.HasMore {
+				if err := sleep(ctx, c.clock, eventIdleDelay); err != nil {
+					stream.err = err
+					return
+				}
+				continue
+			}
+
+			for _, event := range resp.Events {
+				select {
+				case events <- event:
+				case <-ctx.Done():
+					stream.err = ctx.Err()
+					return
+				}
+				since = event.ID
+				stream.mu.Lock()
+				stream.cursor = since
+				stream.mu.Unlock()
+			}
+		}
+	}()
+
+	return stream
+}
+
+// retryableEventError reports whether a failed ListEvents call should be retried
+func retryableEventError(err error) bool {
+	statusCode, ok := errorStatusCode(err)
+	if !ok {
+		return true
+	}
+	return statusCode == http.StatusTooManyRequests || statusCode >= 500
+}
+
+// eventBackoff returns the delay after the given consecutive failure
+func eventBackoff(failures int) time.Duration {
+	delay := time.Second
+	for i := 1; i < failures && delay < time.Minute; i++ {
+		delay *= 2
+	}
+	if delay > time.Minute {
+		delay = time.Minute
+	}
+	return delay
+}
@@ -3,3 +3,5 @@ module example
 go 1.21
 
 require github.com/ItIsPay/go-client v1.0.1
+
+replace github.com/ItIsPay/go-client => ../
@@ -2,7 +2,6 @@ package main
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
 	"log"
 	"net/http"
@@ -15,8 +14,8 @@ func main() {
 	// Replace with your actual API key
 	apiKey := "your-api-key-here"
 
-	// Create a new client
-	client := itispay.NewClient(apiKey)
+	// Create a new sandbox client (webhook simulation is not available in production)
+	client := itispay.NewClient(apiKey, itispay.WithEnvironment(itispay.EnvSandbox))
 	ctx := context.Background()
 
 	fmt.Println("=== ItIsPay Go Client Example ===\n")
@@ -153,21 +152,9 @@ func webhookHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	var webhook struct {
-		InvoiceID                     string    `json:"invoice_id"`
-		Status                        string    `json:"status"`
-		OrderID                       string    `json:"order_id"`
-		Currency                      string    `json:"currency"`
-		CryptoAmount                  float64   `json:"crypto_amount"`
-		FiatAmount                    float64   `json:"fiat_amount"`
-		FiatCurrency                  string    `json:"fiat_currency"`
-		ActualCryptoAmountPaid        float64   `json:"actual_crypto_amount_paid"`
-		ActualCryptoAmountPaidInUnits int64     `json:"actual_crypto_amount_paid_in_units"`
-		UpdatedAt                     time.Time `json:"updated_at"`
-	}
-
-	if err := json.NewDecoder(r.Body).Decode(&webhook); err != nil {
-		http.Error(w, "Invalid JSON", http.StatusBadRequest)
+	webhook, err := itispay.ParseWebhook(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
 
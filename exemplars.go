@@ -0,0 +1,44 @@
+package itispay
+
+import (
+	"context"
+	"time"
+)
+
+// ExemplarMetrics is an optional extension of Metrics recording the trace of each
+// request as an exemplar, so a latency spike on a dashboard links to the exact trace
+type ExemplarMetrics interface {
+	// ObserveRequestWithTrace is called instead of ObserveRequest when the request
+	// context carries a trace ID
+	ObserveRequestWithTrace(endpoint string, statusCode int, duration time.Duration, traceID string)
+}
+
+// WithTraceIDFunc sets the function extracting the trace ID from a request context,
+// e.g. from an OpenTelemetry span:
+//
+//	itispay.WithTraceIDFunc(func(ctx context.Context) string {
+//		if sc := trace.SpanContextFromContext(ctx); sc.HasTraceID() {
+//			return sc.TraceID().String()
+//		}
+//		return ""
+//	})
+//
+// Metrics implementing ExemplarMetrics then attach the trace ID to request latencies.
+func WithTraceIDFunc(fn func(ctx context.Context) string) Option {
+	return func(c *Client) {
+		c.traceID = fn
+	}
+}
+
+// observeRequest records a completed HTTP call, with its trace ID if available
+func (c *Client) observeRequest(ctx context.Context, endpoint string, statusCode int, duration time.Duration) {
+	if c.traceID != nil {
+		if m, ok := c.metrics.(ExemplarMetrics); ok {
+			if traceID := c.traceID(ctx); traceID != "" {
+				m.ObserveRequestWithTrace(endpoint, statusCode, duration, traceID)
+				return
+			}
+		}
+	}
+	c.metrics.ObserveRequest(endpoint, statusCode, duration)
+}
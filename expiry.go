@@ -0,0 +1,62 @@
+package itispay
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"time"
+)
+
+// ErrInvalidExtension is returned when an invoice expiry extension is not positive
+var ErrInvalidExtension = errors.New("itispay: invalid expiry extension")
+
+// extendInvoiceRequest represents the request to extend an invoice's expiry
+type extendInvoiceRequest struct {
+	AdditionalMinutes int `json:"additional_minutes"`
+}
+
+// ExtendInvoiceExpiry postpones the expiry of an open invoice by additionalMinutes, e.g.
+// when a customer's transfer is in flight but the invoice is about to expire. The returned
+// invoice carries the new ExpiresAt; expired or completed invoices cannot be extended.
+func (c *Client) ExtendInvoiceExpiry(ctx context.Context, invoiceID string, additionalMinutes int, opts ...RequestOption) (*Invoice, error) {
+	if additionalMinutes <= 0 {
+		return nil, fmt.Errorf("%w: %d minutes", ErrInvalidExtension, additionalMinutes)
+	}
+	path := "/invoices/" + url.PathEscape(invoiceID) + "/extend"
+	return do[Invoice](ctx, c, "POST", path, extendInvoiceRequest{AdditionalMinutes: additionalMinutes}, opts...)
+}
+
+// ReissueInvoice creates a replacement for an expired or cancelled invoice, e.g. when a
+// customer missed the payment window. The replacement carries over the order ID, fiat
+// amount, currencies, callback URL, external references and metadata at the current
+// rate, and the two are linked through ReissuedFrom and ReissuedAs. Reissuing an
+// invoice again returns the same replacement.
+func (c *Client) ReissueInvoice(ctx context.Context, invoiceID string, opts ...RequestOption) (*Invoice, error) {
+	path := "/invoices/" + url.PathEscape(invoiceID) + "/reissue"
+	return do[Invoice](ctx, c, "POST", path, nil, opts...)
+}
+
+// TimeRemaining returns how long the invoice can still be paid at now, zero once it
+// expired or if it no longer awaits payment. The grace period is not included; see
+// GraceDeadline.
+func (i *Invoice) TimeRemaining(now time.Time) time.Duration {
+	if !i.awaitingPayment() || !now.Before(i.ExpiresAt) {
+		return 0
+	}
+	return i.ExpiresAt.Sub(now)
+}
+
+// IsExpired reports whether the invoice is expired at now: its status is expired, or it
+// still awaits payment and ExpiresAt has passed, which the status may not reflect yet
+func (i *Invoice) IsExpired(now time.Time) bool {
+	if i.Status == StatusExpired {
+		return true
+	}
+	return i.awaitingPayment() && !i.ExpiresAt.IsZero() && !now.Before(i.ExpiresAt)
+}
+
+// awaitingPayment reports whether the invoice is new or partially paid
+func (i *Invoice) awaitingPayment() bool {
+	return i.Status == StatusNew || i.Status == StatusPaidPartial
+}
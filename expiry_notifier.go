@@ -0,0 +1,143 @@
+package itispay
+
+import (
+	"sync"
+	"time"
+)
+
+// ExpiryNotifier calls a function shortly before open invoices expire, e.g. to warn the
+// buyer in a checkout UI or to hold back reserved stock:
+//
+//	notifier := itispay.NewExpiryNotifier(5*time.Minute, func(invoice *itispay.Invoice, remaining time.Duration) {
+//		remindBuyer(invoice.OrderID, remaining)
+//	})
+//	defer notifier.Stop()
+//	notifier.Watch(invoice)
+//
+// Feed it webhooks with ObserveWebhook so paid invoices are forgotten and extended ones
+// rescheduled. It is safe for concurrent use.
+type ExpiryNotifier struct {
+	// Clock tells the time and schedules notifications, SystemClock if nil
+	Clock Clock
+
+	before     time.Duration
+	onExpiring func(invoice *Invoice, remaining time.Duration)
+
+	mu      sync.Mutex
+	watches map[string]*expiryWatch
+	stopped bool
+}
+
+// expiryWatch is the pending notification of an invoice
+type expiryWatch struct {
+	invoice Invoice
+	cancel  chan struct{}
+}
+
+// NewExpiryNotifier returns a notifier calling onExpiring, from its own goroutine, when
+// a watched invoice has before left until it expires
+func NewExpiryNotifier(before time.Duration, onExpiring func(invoice *Invoice, remaining time.Duration)) *ExpiryNotifier {
+	return &ExpiryNotifier{
+		before:     before,
+		onExpiring: onExpiring,
+		watches:    make(map[string]*expiryWatch),
+	}
+}
+
+// Watch schedules the notification of an invoice, replacing any earlier one for the
+// same invoice. Invoices no longer awaiting payment or already expired are ignored;
+// invoices expiring within the notice period are notified immediately.
+func (n *ExpiryNotifier) Watch(invoice *Invoice) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.cancel(invoice.InvoiceID)
+	clock := clockOrSystem(n.Clock)
+	now := clock.Now()
+	if n.stopped || invoice.TimeRemaining(now) <= 0 {
+		return
+	}
+
+	w := &expiryWatch{invoice: *invoice, cancel: make(chan struct{})}
+	n.watches[invoice.InvoiceID] = w
+	timer := clock.NewTimer(invoice.ExpiresAt.Add(-n.before).Sub(now))
+	go n.wait(w, timer)
+}
+
+// Forget cancels the notification of an invoice, e.g. once it was paid
+func (n *ExpiryNotifier) Forget(invoiceID string) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.cancel(invoiceID)
+}
+
+// ObserveWebhook updates a watched invoice from a webhook: it is forgotten once it no
+// longer awaits payment and rescheduled if its expiry changed
+func (n *ExpiryNotifier) ObserveWebhook(payload *WebhookPayload) {
+	n.mu.Lock()
+	w, ok := n.watches[payload.InvoiceID]
+	if !ok {
+		n.mu.Unlock()
+		return
+	}
+	invoice := w.invoice
+	n.mu.Unlock()
+
+	invoice.Status = payload.Status
+	if !payload.ExpiresAt.IsZero() {
+		invoice.ExpiresAt = payload.ExpiresAt
+	}
+	if !invoice.awaitingPayment() {
+		n.Forget(invoice.InvoiceID)
+		return
+	}
+	if !invoice.ExpiresAt.Equal(w.invoice.ExpiresAt) {
+		n.Watch(&invoice)
+	}
+}
+
+// Pending returns the number of invoices waiting to be notified
+func (n *ExpiryNotifier) Pending() int {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	return len(n.watches)
+}
+
+// Stop cancels all pending notifications; later calls to Watch are ignored
+func (n *ExpiryNotifier) Stop() {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.stopped = true
+	for id := range n.watches {
+		n.cancel(id)
+	}
+}
+
+// cancel stops the pending notification of an invoice, if any; n.mu must be held
+func (n *ExpiryNotifier) cancel(invoiceID string) {
+	if w, ok := n.watches[invoiceID]; ok {
+		close(w.cancel)
+		delete(n.watches, invoiceID)
+	}
+}
+
+// wait notifies the invoice of w when timer fires, unless it is cancelled first
+func (n *ExpiryNotifier) wait(w *expiryWatch, timer Timer) {
+	select {
+	case <-w.cancel:
+		timer.Stop()
+		return
+	case <-timer.C():
+	}
+
+	n.mu.Lock()
+	if n.watches[w.invoice.InvoiceID] != w {
+		// Cancelled or replaced while firing
+		n.mu.Unlock()
+		return
+	}
+	delete(n.watches, w.invoice.InvoiceID)
+	n.mu.Unlock()
+
+	invoice := w.invoice
+	n.onExpiring(&invoice, invoice.TimeRemaining(clockOrSystem(n.Clock).Now()))
+}
@@ -0,0 +1,148 @@
+package itispay
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+)
+
+// ExportFormat selects the output format of ExportInvoices
+type ExportFormat string
+
+// Export formats
+const (
+	ExportCSV   ExportFormat = "csv"
+	ExportJSONL ExportFormat = "jsonl"
+)
+
+// DefaultExportColumns are the columns exported when none are selected
+var DefaultExportColumns = []string{
+	"invoice_id",
+	"order_id",
+	"order_name",
+	"status",
+	"currency",
+	"crypto_amount",
+	"actual_crypto_amount_paid",
+	"fiat_amount",
+	"fiat_currency",
+	"created_at",
+	"updated_at",
+}
+
+// ExportInvoices streams all invoices matching params to w as CSV (with a header row) or
+// JSON lines, e.g. for monthly finance statements. Pages are decoded incrementally (see
+// ListInvoicesStream), so memory stays flat for large exports. Columns are Invoice JSON field names;
+// nil selects DefaultExportColumns. It returns the number of invoices written.
+func (c *Client) ExportInvoices(ctx context.Context, params ListInvoicesParams, w io.Writer, format ExportFormat, columns []string, opts ...RequestOption) (int, error) {
+	if columns == nil {
+		columns = DefaultExportColumns
+	}
+	for _, column := range columns {
+		if !invoiceFields[column] {
+			return 0, fmt.Errorf("unknown export column %q", column)
+		}
+	}
+
+	var write func(fields map[string]json.RawMessage) error
+	var flush func() error
+	switch format {
+	case ExportCSV:
+		cw := csv.NewWriter(w)
+		if err := cw.Write(columns); err != nil {
+			return 0, err
+		}
+		record := make([]string, len(columns))
+		write = func(fields map[string]json.RawMessage) error {
+			for i, column := range columns {
+				record[i] = csvValue(fields[column])
+			}
+			return cw.Write(record)
+		}
+		flush = func() error {
+			cw.Flush()
+			return cw.Error()
+		}
+	case ExportJSONL:
+		var line bytes.Buffer
+		write = func(fields map[string]json.RawMessage) error {
+			line.Reset()
+			line.WriteByte('{')
+			for i, column := range columns {
+				if i > 0 {
+					line.WriteByte(',')
+				}
+				name, _ := json.Marshal(column)
+				line.Write(name)
+				line.WriteByte(':')
+				if value, ok := fields[column]; ok {
+					line.Write(value)
+				} else {
+					line.WriteString("null")
+				}
+			}
+			line.WriteString("}\n")
+			_, err := w.Write(line.Bytes())
+			return err
+		}
+		flush = func() error { return nil }
+	default:
+		return 0, fmt.Errorf("unsupported export format %q", format)
+	}
+
+	count := 0
+	err := c.ListInvoicesStream(ctx, params, func(invoice *Invoice) error {
+		data, err := json.Marshal(invoice)
+		if err != nil {
+			return err
+		}
+		var fields map[string]json.RawMessage
+		if err := json.Unmarshal(data, &fields); err != nil {
+			return err
+		}
+		if err := write(fields); err != nil {
+			return fmt.Errorf("failed to write invoice export: %w", err)
+		}
+		count++
+		return nil
+	}, opts...)
+	if flushErr := flush(); flushErr != nil && err == nil {
+		err = fmt.Errorf("failed to write invoice export: %w", flushErr)
+	}
+	return count, err
+}
+
+// csvValue formats a JSON value as a CSV cell: strings unquoted, null empty, numbers,
+// booleans and objects as JSON
+func csvValue(value json.RawMessage) string {
+	if len(value) == 0 || string(value) == "null" {
+		return ""
+	}
+	if value[0] == '"' {
+		var s string
+		if err := json.Unmarshal(value, &s); err == nil {
+			return s
+		}
+	}
+	return string(value)
+}
+
+// invoiceFields is the set of Invoice JSON field names
+var invoiceFields = jsonFieldNames(reflect.TypeOf(Invoice{}))
+
+// jsonFieldNames returns the JSON names of the fields of a struct type
+func jsonFieldNames(t reflect.Type) map[string]bool {
+	names := make(map[string]bool, t.NumField())
+	for i := 0; i < t.NumField(); i++ {
+		name, _, _ := strings.Cut(t.Field(i).Tag.Get("json"), ",")
+		if name != "" && name != "-" {
+			names[name] = true
+		}
+	}
+	return names
+}
@@ -0,0 +1,19 @@
+package itispay
+
+import "context"
+
+// FindByExternalRef returns all invoices whose external reference key equals value,
+// e.g. FindByExternalRef(ctx, "customer_id", "c-42"), so merchants do not need their
+// own invoice-to-entity join tables
+func (c *Client) FindByExternalRef(ctx context.Context, key, value string, opts ...RequestOption) ([]Invoice, error) {
+	pager := c.NewInvoicePager(ListInvoicesParams{ExternalRefs: map[string]string{key: value}}, opts...)
+
+	var invoices []Invoice
+	for pager.Next(ctx) {
+		invoices = append(invoices, *pager.Invoice())
+	}
+	if err := pager.Err(); err != nil {
+		return nil, err
+	}
+	return invoices, nil
+}
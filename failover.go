@@ -0,0 +1,122 @@
+package itispay
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+)
+
+// failoverCooldown is how long a base URL that failed is avoided
+const failoverCooldown = 30 * time.Second
+
+// WithBaseURLs sets several API base URLs, e.g. the primary region followed by
+// secondary ones. The client sticks to one base URL until a request to it fails with
+// a network error or a 5xx response, then switches to the next base URL that has not
+// failed recently. The failed request is repeated against the next base URL if it is
+// safe to do so: a GET request or one carrying an idempotency key.
+func WithBaseURLs(baseURLs []string) Option {
+	return func(c *Client) {
+		if len(baseURLs) == 0 {
+			return
+		}
+		c.baseURL = baseURLs[0]
+		c.failover = nil
+		if len(baseURLs) > 1 {
+			c.failover = &failover{
+				baseURLs: append([]string(nil), baseURLs...),
+				failedAt: make([]time.Time, len(baseURLs)),
+			}
+		}
+	}
+}
+
+// BaseURL returns the base URL requests are currently sent to
+func (c *Client) BaseURL() string {
+	if c.failover != nil {
+		_, baseURL := c.failover.active()
+		return baseURL
+	}
+	return c.baseURL
+}
+
+// failover selects among several base URLs, sticking to one until it fails
+type failover struct {
+	baseURLs []string
+	clock    Clock
+
+	mu       sync.Mutex
+	current  int
+	failedAt []time.Time
+}
+
+// active returns the index and value of the base URL in use
+func (f *failover) active() (int, string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.current, f.baseURLs[f.current]
+}
+
+// markFailed records a failure of the base URL at index i and, if it is in use,
+// switches to the next one that has not failed within failoverCooldown, or else the
+// one that failed longest ago
+func (f *failover) markFailed(i int) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	now := f.clock.Now()
+	f.failedAt[i] = now
+	if i != f.current {
+		return
+	}
+	oldest := i
+	for n := 1; n < len(f.baseURLs); n++ {
+		j := (i + n) % len(f.baseURLs)
+		if now.Sub(f.failedAt[j]) >= failoverCooldown {
+			f.current = j
+			return
+		}
+		if f.failedAt[j].Before(f.failedAt[oldest]) {
+			oldest = j
+		}
+	}
+	f.current = oldest
+}
+
+// send performs attempt against the active base URL, failing over to the next ones
+// while attempts fail and repeating the request is safe
+func (f *failover) send(ctx context.Context, method, path string, options *requestOptions, attempt func(baseURL string) (*apiResponse, error), metrics Metrics) (*apiResponse, error) {
+	repeatable := method == http.MethodGet || method == http.MethodHead || options.headers.Get(IdempotencyKeyHeader) != ""
+	// A streamed response may have been partly consumed before failing
+	repeatable = repeatable && options.stream == nil
+
+	i, baseURL := f.active()
+	for tried := 1; ; tried++ {
+		resp, err := attempt(baseURL)
+		if err == nil || ctx.Err() != nil || !isRegionFailure(err) {
+			return resp, err
+		}
+		f.markFailed(i)
+		if !repeatable || tried == len(f.baseURLs) {
+			return nil, err
+		}
+		next, nextURL := f.active()
+		if next == i {
+			return nil, err
+		}
+		i, baseURL = next, nextURL
+		metrics.IncRetry(endpointLabel(method, path))
+	}
+}
+
+// isRegionFailure reports whether err suggests the base URL is unavailable: a network
+// error or a 5xx response
+func isRegionFailure(err error) bool {
+	if statusCode, ok := errorStatusCode(err); ok {
+		return statusCode >= 500
+	}
+	var urlErr *url.Error
+	return errors.As(err, &urlErr)
+}
@@ -0,0 +1,78 @@
+package itispay
+
+import (
+	"testing"
+	"time"
+)
+
+// fixedClock is a Clock stopped at one instant
+type fixedClock time.Time
+
+func (c fixedClock) Now() time.Time                 { return time.Time(c) }
+func (c fixedClock) NewTimer(d time.Duration) Timer { return SystemClock.NewTimer(d) }
+
+func TestFailoverMarkFailed(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	ago := func(d time.Duration) time.Time { return now.Add(-d) }
+
+	tests := []struct {
+		name     string
+		current  int
+		failedAt []time.Time
+		failed   int
+		want     int
+	}{
+		{
+			name:     "switches to the next base URL",
+			failedAt: make([]time.Time, 3),
+			want:     1,
+		},
+		{
+			name:     "skips a base URL in cooldown",
+			failedAt: []time.Time{{}, ago(10 * time.Second), {}},
+			want:     2,
+		},
+		{
+			name:     "uses a base URL again once its cooldown is over",
+			failedAt: []time.Time{{}, ago(failoverCooldown), {}},
+			want:     1,
+		},
+		{
+			name:     "wraps around",
+			current:  2,
+			failed:   2,
+			failedAt: make([]time.Time, 3),
+			want:     0,
+		},
+		{
+			name:     "falls back to the one that failed longest ago",
+			failedAt: []time.Time{{}, ago(10 * time.Second), ago(20 * time.Second)},
+			want:     2,
+		},
+		{
+			name:     "failure of a base URL not in use is only recorded",
+			current:  1,
+			failed:   0,
+			failedAt: make([]time.Time, 3),
+			want:     1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := &failover{
+				baseURLs: []string{"https://eu.example.com", "https://us.example.com", "https://ap.example.com"},
+				clock:    fixedClock(now),
+				current:  tt.current,
+				failedAt: tt.failedAt,
+			}
+			f.markFailed(tt.failed)
+			if got, _ := f.active(); got != tt.want {
+				t.Errorf("active base URL = %d, want %d", got, tt.want)
+			}
+			if !f.failedAt[tt.failed].Equal(now) {
+				t.Errorf("failure of base URL %d recorded at %v, want %v", tt.failed, f.failedAt[tt.failed], now)
+			}
+		})
+	}
+}
@@ -0,0 +1,219 @@
+package itispay_test
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	itispay "github.com/ItIsPay/go-client"
+	"github.com/ItIsPay/go-client/itispaytest"
+)
+
+// hostRecorder records the host of every request sent
+type hostRecorder struct {
+	mu    sync.Mutex
+	hosts []string
+}
+
+func (r *hostRecorder) intercept(next itispay.RoundTripFunc) itispay.RoundTripFunc {
+	return func(req *http.Request) (*http.Response, error) {
+		r.mu.Lock()
+		r.hosts = append(r.hosts, req.URL.Host)
+		r.mu.Unlock()
+		return next(req)
+	}
+}
+
+func (r *hostRecorder) take() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	hosts := r.hosts
+	r.hosts = nil
+	return hosts
+}
+
+// regions starts n fake APIs and returns them with a client failing over between them
+func regions(t *testing.T, n int, opts ...itispay.Option) ([]*itispaytest.Server, *itispay.Client, *hostRecorder) {
+	t.Helper()
+	servers := make([]*itispaytest.Server, n)
+	baseURLs := make([]string, n)
+	for i := range servers {
+		servers[i] = itispaytest.NewServer()
+		t.Cleanup(servers[i].Close)
+		baseURLs[i] = servers[i].URL
+	}
+	recorder := &hostRecorder{}
+	opts = append([]itispay.Option{itispay.WithBaseURLs(baseURLs), itispay.WithInterceptor(recorder.intercept)}, opts...)
+	return servers, servers[0].Client(opts...), recorder
+}
+
+// host returns the host of a server's URL
+func host(srv *itispaytest.Server) string {
+	return strings.TrimPrefix(srv.URL, "http://")
+}
+
+func TestFailover(t *testing.T) {
+	createInvoice := func(opts ...itispay.RequestOption) func(*itispay.Client) error {
+		return func(client *itispay.Client) error {
+			amount := 10.0
+			_, err := client.CreateInvoice(context.Background(), itispay.CreateInvoiceRequest{
+				OrderID:      "ORDER-1",
+				FiatAmount:   &amount,
+				FiatCurrency: "EUR",
+				Currency:     "BTC",
+			}, opts...)
+			return err
+		}
+	}
+	getRates := func(client *itispay.Client) error {
+		_, err := client.GetRates(context.Background())
+		return err
+	}
+
+	tests := []struct {
+		name  string
+		fault func(primary *itispaytest.Server)
+		call  func(*itispay.Client) error
+		// sentTo lists the servers the request reached, by index
+		sentTo     []int
+		wantErr    bool
+		wantActive int
+	}{
+		{
+			name:       "healthy primary",
+			fault:      func(*itispaytest.Server) {},
+			call:       getRates,
+			sentTo:     []int{0},
+			wantActive: 0,
+		},
+		{
+			name:       "server error repeats a read on the secondary",
+			fault:      func(srv *itispaytest.Server) { srv.SetDown("/rates", http.StatusServiceUnavailable) },
+			call:       getRates,
+			sentTo:     []int{0, 1},
+			wantActive: 1,
+		},
+		{
+			name:       "dropped connection repeats a read on the secondary",
+			fault:      func(srv *itispaytest.Server) { srv.SetDropRate("/rates", 1) },
+			call:       getRates,
+			sentTo:     []int{0, 1},
+			wantActive: 1,
+		},
+		{
+			name:       "client error does not fail over",
+			fault:      func(srv *itispaytest.Server) { srv.SetDown("/rates", http.StatusBadRequest) },
+			call:       getRates,
+			sentTo:     []int{0},
+			wantErr:    true,
+			wantActive: 0,
+		},
+		{
+			name:       "create without idempotency key is not repeated",
+			fault:      func(srv *itispaytest.Server) { srv.SetDown("POST /invoices", http.StatusServiceUnavailable) },
+			call:       createInvoice(),
+			sentTo:     []int{0},
+			wantErr:    true,
+			wantActive: 1,
+		},
+		{
+			name:       "create with idempotency key is repeated",
+			fault:      func(srv *itispaytest.Server) { srv.SetDown("POST /invoices", http.StatusServiceUnavailable) },
+			call:       createInvoice(itispay.WithIdempotencyKey("order-1")),
+			sentTo:     []int{0, 1},
+			wantActive: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			servers, client, recorder := regions(t, 2)
+			tt.fault(servers[0])
+
+			err := tt.call(client)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, want error: %t", err, tt.wantErr)
+			}
+			hosts := recorder.take()
+			if len(hosts) != len(tt.sentTo) {
+				t.Fatalf("request sent to %v, want %d attempts", hosts, len(tt.sentTo))
+			}
+			for i, server := range tt.sentTo {
+				if hosts[i] != host(servers[server]) {
+					t.Errorf("attempt %d sent to %s, want server %d", i, hosts[i], server)
+				}
+			}
+			if got := client.BaseURL(); got != servers[tt.wantActive].URL {
+				t.Errorf("BaseURL() = %s, want server %d", got, tt.wantActive)
+			}
+		})
+	}
+}
+
+func TestFailoverIsSticky(t *testing.T) {
+	servers, client, recorder := regions(t, 2)
+	ctx := context.Background()
+
+	servers[0].SetDown("/rates", http.StatusServiceUnavailable)
+	if _, err := client.GetRates(ctx); err != nil {
+		t.Fatal(err)
+	}
+	recorder.take()
+
+	// The primary recovering does not move requests back
+	servers[0].SetHealthy("/rates")
+	for i := 0; i < 3; i++ {
+		if _, err := client.GetRates(ctx); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, h := range recorder.take() {
+		if h != host(servers[1]) {
+			t.Errorf("request sent to %s after failing over, want the secondary", h)
+		}
+	}
+}
+
+func TestFailoverCooldown(t *testing.T) {
+	clock := itispaytest.NewFakeClock(time.Now())
+	servers, client, _ := regions(t, 3, itispay.WithClock(clock))
+	ctx := context.Background()
+	active := func() int {
+		for i, srv := range servers {
+			if client.BaseURL() == srv.URL {
+				return i
+			}
+		}
+		return -1
+	}
+
+	steps := []struct {
+		advance time.Duration
+		down    int
+		want    int
+	}{
+		// The first region fails and the second takes over
+		{down: 0, want: 1},
+		// The second fails 10s later and the third takes over
+		{advance: 10 * time.Second, down: 1, want: 2},
+		// The third fails 25s later, once the first has cooled down
+		{advance: 25 * time.Second, down: 2, want: 0},
+	}
+	for i, step := range steps {
+		clock.Advance(step.advance)
+		for _, srv := range servers {
+			srv.SetHealthy("/rates")
+		}
+		servers[step.down].SetDown("/rates", http.StatusServiceUnavailable)
+
+		if _, err := client.GetRates(ctx); err != nil {
+			t.Fatalf("step %d: %v", i, err)
+		}
+		if got := active(); got != step.want {
+			t.Fatalf("step %d: active region = %d, want %d", i, got, step.want)
+		}
+	}
+}
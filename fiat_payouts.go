@@ -0,0 +1,250 @@
+package itispay
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrInvalidBeneficiary is returned for beneficiaries missing the bank details their
+// payment scheme needs
+var ErrInvalidBeneficiary = errors.New("itispay: invalid beneficiary")
+
+// ErrInvalidFiatPayout is returned for fiat payouts without a beneficiary or with a
+// non-positive amount
+var ErrInvalidFiatPayout = errors.New("itispay: invalid fiat payout")
+
+// Bank payment schemes
+const (
+	SchemeSEPA  = "sepa"
+	SchemeSWIFT = "swift"
+)
+
+// Beneficiary status constants
+const (
+	BeneficiaryStatusPending  = "pending_verification"
+	BeneficiaryStatusVerified = "verified"
+	BeneficiaryStatusRejected = "rejected"
+)
+
+// FiatPayoutStatus is the status of a withdrawal to a bank account
+type FiatPayoutStatus string
+
+// Fiat payout status constants
+const (
+	FiatPayoutPending    FiatPayoutStatus = "pending"
+	FiatPayoutProcessing FiatPayoutStatus = "processing"
+	FiatPayoutSent       FiatPayoutStatus = "sent"
+	FiatPayoutCompleted  FiatPayoutStatus = "completed"
+	// FiatPayoutReturned means the receiving bank sent the funds back, e.g. because the
+	// account was closed
+	FiatPayoutReturned FiatPayoutStatus = "returned"
+	FiatPayoutFailed   FiatPayoutStatus = "failed"
+)
+
+// IsFinal reports whether the payout will not change status anymore
+func (s FiatPayoutStatus) IsFinal() bool {
+	return s == FiatPayoutCompleted || s == FiatPayoutReturned || s == FiatPayoutFailed
+}
+
+// BankAccount holds the bank details of a beneficiary. SEPA transfers need the IBAN;
+// SWIFT transfers need the BIC and either the IBAN or the account number.
+type BankAccount struct {
+	Scheme        string `json:"scheme"`
+	IBAN          string `json:"iban,omitempty"`
+	BIC           string `json:"bic,omitempty"`
+	AccountNumber string `json:"account_number,omitempty"`
+	BankName      string `json:"bank_name,omitempty"`
+	// Country is the ISO 3166-1 alpha-2 code of the bank's country
+	Country string `json:"country,omitempty"`
+}
+
+// Beneficiary is a bank account settled balances can be withdrawn to
+type Beneficiary struct {
+	BeneficiaryID string      `json:"beneficiary_id"`
+	Name          string      `json:"name"`
+	Currency      string      `json:"currency"`
+	BankAccount   BankAccount `json:"bank_account"`
+	// Status is one of the BeneficiaryStatus* constants; only verified beneficiaries
+	// can receive payouts
+	Status    string    `json:"status"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// CreateBeneficiaryRequest represents the request to add a beneficiary
+type CreateBeneficiaryRequest struct {
+	// Name is the account holder's name as known to the bank
+	Name        string      `json:"name"`
+	Currency    string      `json:"currency"`
+	BankAccount BankAccount `json:"bank_account"`
+}
+
+// ListBeneficiariesResponse represents the response from listing beneficiaries
+type ListBeneficiariesResponse struct {
+	Items []Beneficiary `json:"items"`
+}
+
+// FiatPayout is a withdrawal of settled balance to a beneficiary's bank account
+type FiatPayout struct {
+	FiatPayoutID  string           `json:"fiat_payout_id"`
+	BeneficiaryID string           `json:"beneficiary_id"`
+	Currency      string           `json:"currency"`
+	Amount        float64          `json:"amount"`
+	Fee           float64          `json:"fee"`
+	Reference     string           `json:"reference,omitempty"`
+	Status        FiatPayoutStatus `json:"status"`
+	// FailureReason explains a failed or returned payout
+	FailureReason     string     `json:"failure_reason,omitempty"`
+	ExpectedArrivalAt *time.Time `json:"expected_arrival_at,omitempty"`
+	CreatedAt         time.Time  `json:"created_at"`
+	UpdatedAt         time.Time  `json:"updated_at"`
+}
+
+// CreateFiatPayoutRequest represents the request to withdraw to a bank account
+type CreateFiatPayoutRequest struct {
+	BeneficiaryID string  `json:"beneficiary_id"`
+	Currency      string  `json:"currency"`
+	Amount        float64 `json:"amount"`
+	// Reference appears on the beneficiary's bank statement
+	Reference string `json:"reference,omitempty"`
+}
+
+// ListFiatPayoutsParams represents parameters for listing fiat payouts
+type ListFiatPayoutsParams struct {
+	BeneficiaryID string
+	Status        FiatPayoutStatus
+	Page          int
+	PageSize      int
+}
+
+// ListFiatPayoutsResponse represents the response from listing fiat payouts
+type ListFiatPayoutsResponse struct {
+	Items      []FiatPayout   `json:"items"`
+	Pagination PaginationInfo `json:"pagination"`
+}
+
+// CreateBeneficiary adds a bank account to withdraw to. Bank details are checked
+// locally first, including the IBAN checksum; the beneficiary must then be verified
+// before it can receive payouts.
+func (c *Client) CreateBeneficiary(ctx context.Context, req CreateBeneficiaryRequest, opts ...RequestOption) (*Beneficiary, error) {
+	if err := validateBeneficiary(&req); err != nil {
+		return nil, err
+	}
+	return do[Beneficiary](ctx, c, "POST", "/beneficiaries", req, opts...)
+}
+
+// ListBeneficiaries retrieves the beneficiaries of the account
+func (c *Client) ListBeneficiaries(ctx context.Context, opts ...RequestOption) (*ListBeneficiariesResponse, error) {
+	return do[ListBeneficiariesResponse](ctx, c, "GET", "/beneficiaries", nil, opts...)
+}
+
+// DeleteBeneficiary removes a beneficiary; payouts already made to it are kept
+func (c *Client) DeleteBeneficiary(ctx context.Context, beneficiaryID string, opts ...RequestOption) error {
+	_, err := c.doRequest(ctx, "DELETE", "/beneficiaries/"+url.PathEscape(beneficiaryID), nil, opts...)
+	return err
+}
+
+// CreateFiatPayout withdraws settled balance to a verified beneficiary. Use
+// WithIdempotencyKey so a retried request does not pay out twice. Status changes are
+// delivered as EventFiatPayoutUpdated events.
+func (c *Client) CreateFiatPayout(ctx context.Context, req CreateFiatPayoutRequest, opts ...RequestOption) (*FiatPayout, error) {
+	switch {
+	case req.BeneficiaryID == "":
+		return nil, fmt.Errorf("%w: beneficiary ID is required", ErrInvalidFiatPayout)
+	case req.Amount <= 0:
+		return nil, fmt.Errorf("%w: amount must be positive, got %v", ErrInvalidFiatPayout, req.Amount)
+	}
+	return do[FiatPayout](ctx, c, "POST", "/fiat-payouts", req, opts...)
+}
+
+// GetFiatPayout retrieves a specific fiat payout by ID
+func (c *Client) GetFiatPayout(ctx context.Context, fiatPayoutID string, opts ...RequestOption) (*FiatPayout, error) {
+	return do[FiatPayout](ctx, c, "GET", "/fiat-payouts/"+url.PathEscape(fiatPayoutID), nil, opts...)
+}
+
+// ListFiatPayouts retrieves a paginated list of fiat payouts, newest first
+func (c *Client) ListFiatPayouts(ctx context.Context, params ListFiatPayoutsParams, opts ...RequestOption) (*ListFiatPayoutsResponse, error) {
+	queryParams := url.Values{}
+	if params.BeneficiaryID != "" {
+		queryParams.Set("beneficiary_id", params.BeneficiaryID)
+	}
+	if params.Status != "" {
+		queryParams.Set("status", string(params.Status))
+	}
+	if params.Page > 0 {
+		queryParams.Set("page", strconv.Itoa(params.Page))
+	}
+	if params.PageSize > 0 {
+		queryParams.Set("page_size", strconv.Itoa(params.PageSize))
+	}
+
+	path := "/fiat-payouts"
+	if len(queryParams) > 0 {
+		path += "?" + queryParams.Encode()
+	}
+
+	return do[ListFiatPayoutsResponse](ctx, c, "GET", path, nil, opts...)
+}
+
+// validateBeneficiary checks that the bank details match the payment scheme,
+// normalizing IBAN and BIC to their compact upper-case form
+func validateBeneficiary(req *CreateBeneficiaryRequest) error {
+	account := &req.BankAccount
+	account.IBAN = strings.ToUpper(strings.ReplaceAll(account.IBAN, " ", ""))
+	account.BIC = strings.ToUpper(strings.TrimSpace(account.BIC))
+
+	switch {
+	case req.Name == "":
+		return fmt.Errorf("%w: name is required", ErrInvalidBeneficiary)
+	case req.Currency == "":
+		return fmt.Errorf("%w: currency is required", ErrInvalidBeneficiary)
+	}
+	switch account.Scheme {
+	case SchemeSEPA:
+		if account.IBAN == "" {
+			return fmt.Errorf("%w: SEPA transfers require an IBAN", ErrInvalidBeneficiary)
+		}
+	case SchemeSWIFT:
+		if account.BIC == "" {
+			return fmt.Errorf("%w: SWIFT transfers require a BIC", ErrInvalidBeneficiary)
+		}
+		if account.IBAN == "" && account.AccountNumber == "" {
+			return fmt.Errorf("%w: SWIFT transfers require an IBAN or account number", ErrInvalidBeneficiary)
+		}
+	default:
+		return fmt.Errorf("%w: unknown scheme %q", ErrInvalidBeneficiary, account.Scheme)
+	}
+	if account.IBAN != "" && !validIBAN(account.IBAN) {
+		return fmt.Errorf("%w: invalid IBAN %s", ErrInvalidBeneficiary, account.IBAN)
+	}
+	if n := len(account.BIC); n != 0 && n != 8 && n != 11 {
+		return fmt.Errorf("%w: BIC must have 8 or 11 characters", ErrInvalidBeneficiary)
+	}
+	return nil
+}
+
+// validIBAN verifies the format and mod-97 checksum of a compact IBAN
+func validIBAN(iban string) bool {
+	if len(iban) < 15 || len(iban) > 34 {
+		return false
+	}
+	// Move the country code and check digits to the end and map letters to 10..35
+	var digits strings.Builder
+	for _, r := range iban[4:] + iban[:4] {
+		switch {
+		case r >= '0' && r <= '9':
+			digits.WriteRune(r)
+		case r >= 'A' && r <= 'Z':
+			digits.WriteString(strconv.Itoa(int(r-'A') + 10))
+		default:
+			return false
+		}
+	}
+	n, ok := new(big.Int).SetString(digits.String(), 10)
+	return ok && new(big.Int).Mod(n, big.NewInt(97)).Int64() == 1
+}
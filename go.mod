@@ -1,3 +1,3 @@
 module github.com/ItIsPay/go-client
 
-go 1.21 
+go 1.21
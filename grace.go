@@ -0,0 +1,45 @@
+package itispay
+
+import (
+	"context"
+	"time"
+)
+
+// AccountSettings holds account-wide defaults
+type AccountSettings struct {
+	// GracePeriodMin is the default number of minutes after expiry during which detected
+	// payments still complete an invoice. CreateInvoiceRequest.GracePeriodMin overrides it.
+	GracePeriodMin int `json:"grace_period_min"`
+}
+
+// UpdateAccountSettingsRequest represents the request to update account settings
+type UpdateAccountSettingsRequest struct {
+	GracePeriodMin *int `json:"grace_period_min,omitempty"`
+}
+
+// GracePeriod returns the invoice's grace period after expiry
+func (i *Invoice) GracePeriod() time.Duration {
+	return time.Duration(i.GracePeriodMin) * time.Minute
+}
+
+// GraceDeadline returns the time after which payments no longer complete the invoice:
+// ExpiresAt plus the grace period. Merchant-side timers should wait for it rather than
+// ExpiresAt before releasing reserved stock.
+func (i *Invoice) GraceDeadline() time.Time {
+	return i.ExpiresAt.Add(i.GracePeriod())
+}
+
+// InGracePeriod reports whether t is after the invoice expired but within its grace period
+func (i *Invoice) InGracePeriod(t time.Time) bool {
+	return t.After(i.ExpiresAt) && !t.After(i.GraceDeadline())
+}
+
+// GetAccountSettings retrieves the account-wide defaults
+func (c *Client) GetAccountSettings(ctx context.Context, opts ...RequestOption) (*AccountSettings, error) {
+	return do[AccountSettings](ctx, c, "GET", "/account/settings", nil, opts...)
+}
+
+// UpdateAccountSettings updates the account-wide defaults, e.g. the grace period
+func (c *Client) UpdateAccountSettings(ctx context.Context, req UpdateAccountSettingsRequest, opts ...RequestOption) (*AccountSettings, error) {
+	return do[AccountSettings](ctx, c, "PATCH", "/account/settings", req, opts...)
+}
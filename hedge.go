@@ -0,0 +1,129 @@
+package itispay
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"time"
+)
+
+// WithHedging sends a second, identical request if the first has not answered after
+// delay and uses whichever response arrives first, cancelling the other. It trades a
+// little extra load for lower tail latency on latency-sensitive reads such as GetInvoice
+// and GetRates during checkout. Only GET requests are hedged; the option is ignored for
+// calls that modify data. With WithRequestSigning, the second request is signed with its
+// own nonce.
+func WithHedging(delay time.Duration) RequestOption {
+	return func(o *requestOptions) {
+		o.hedgeDelay = delay
+	}
+}
+
+// hedgeResult is the outcome of one attempt of a hedged request
+type hedgeResult struct {
+	attempt int
+	resp    *http.Response
+	err     error
+}
+
+// hedgedRoundTrip sends req with next, and again after delay if no response arrived yet.
+// The first response below 500 wins and the other attempt is cancelled. If every attempt
+// fails, or the first one fails before delay, the first failure is returned as is.
+// prepare, if set, is applied to the copy sent by the second attempt, e.g. to sign it
+// with a fresh nonce. clock times the delay.
+func hedgedRoundTrip(clock Clock, next RoundTripFunc, req *http.Request, delay time.Duration, prepare func(*http.Request) error) (*http.Response, error) {
+	results := make(chan hedgeResult, 2)
+	var cancels []context.CancelFunc
+	launch := func() {
+		ctx, cancel := context.WithCancel(req.Context())
+		attempt := len(cancels)
+		cancels = append(cancels, cancel)
+		go func() {
+			clone := req.Clone(ctx)
+			if attempt > 0 && prepare != nil {
+				if err := prepare(clone); err != nil {
+					results <- hedgeResult{attempt: attempt, err: err}
+					return
+				}
+			}
+			resp, err := next(clone)
+			results <- hedgeResult{attempt: attempt, resp: resp, err: err}
+		}()
+	}
+
+	launch()
+	inFlight := 1
+	timer := clock.NewTimer(delay)
+	defer timer.Stop()
+
+	var first *hedgeResult
+	for {
+		select {
+		case <-timer.C():
+			launch()
+			inFlight++
+		case result := <-results:
+			inFlight--
+			if result.err == nil && result.resp.StatusCode < http.StatusInternalServerError {
+				for attempt, cancel := range cancels {
+					if attempt != result.attempt {
+						cancel()
+					}
+				}
+				if first != nil {
+					closeHedge(*first, cancels)
+				}
+				go discardHedges(results, inFlight)
+				return keepAlive(result, cancels), nil
+			}
+			if first == nil {
+				first = &result
+			} else {
+				closeHedge(result, cancels)
+			}
+			if inFlight == 0 {
+				if first.err != nil {
+					cancels[first.attempt]()
+					return nil, first.err
+				}
+				return keepAlive(*first, cancels), nil
+			}
+		}
+	}
+}
+
+// keepAlive keeps the context of a returned attempt alive until its body is closed
+func keepAlive(result hedgeResult, cancels []context.CancelFunc) *http.Response {
+	result.resp.Body = &cancelOnClose{ReadCloser: result.resp.Body, cancel: cancels[result.attempt]}
+	return result.resp
+}
+
+// closeHedge releases an attempt that is not returned
+func closeHedge(result hedgeResult, cancels []context.CancelFunc) {
+	if result.err == nil {
+		result.resp.Body.Close()
+	}
+	cancels[result.attempt]()
+}
+
+// discardHedges closes the responses of the remaining n losing attempts
+func discardHedges(results <-chan hedgeResult, n int) {
+	for i := 0; i < n; i++ {
+		if result := <-results; result.err == nil {
+			result.resp.Body.Close()
+		}
+	}
+}
+
+// cancelOnClose cancels a request's context once its response body is closed
+type cancelOnClose struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+// Close closes the body and cancels the context
+func (c *cancelOnClose) Close() error {
+	err := c.ReadCloser.Close()
+	c.cancel()
+	return err
+}
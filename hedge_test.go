@@ -0,0 +1,291 @@
+package itispay
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// trackedBody is a response body that reports when it is closed
+type trackedBody struct {
+	io.Reader
+	once   sync.Once
+	closed chan struct{}
+}
+
+func newTrackedBody() *trackedBody {
+	return &trackedBody{Reader: strings.NewReader("{}"), closed: make(chan struct{})}
+}
+
+func (b *trackedBody) Close() error {
+	b.once.Do(func() { close(b.closed) })
+	return nil
+}
+
+func (b *trackedBody) isClosed() bool {
+	select {
+	case <-b.closed:
+		return true
+	default:
+		return false
+	}
+}
+
+// hedgeAttempt scripts one attempt of a hedged request
+type hedgeAttempt struct {
+	// delay holds the attempt for a while once sent; wait, if set, holds it until
+	// wait is closed. Either is cut short when the request is cancelled unless
+	// ignoreCancel is set.
+	delay        time.Duration
+	wait         chan struct{}
+	ignoreCancel bool
+	status       int
+	err          error
+
+	req       *http.Request
+	body      *trackedBody
+	cancelled chan struct{}
+}
+
+// hedgeScript is a RoundTripFunc answering each attempt as scripted
+type hedgeScript struct {
+	attempts []*hedgeAttempt
+	calls    atomic.Int32
+}
+
+func newHedgeScript(attempts ...*hedgeAttempt) *hedgeScript {
+	for _, a := range attempts {
+		a.cancelled = make(chan struct{})
+		a.body = newTrackedBody()
+	}
+	return &hedgeScript{attempts: attempts}
+}
+
+func (s *hedgeScript) roundTrip(req *http.Request) (*http.Response, error) {
+	a := s.attempts[s.calls.Add(1)-1]
+	a.req = req
+	go func() {
+		<-req.Context().Done()
+		close(a.cancelled)
+	}()
+	wait := a.wait
+	if a.delay > 0 {
+		wait = make(chan struct{})
+		time.AfterFunc(a.delay, func() { close(wait) })
+	}
+	if wait != nil {
+		if a.ignoreCancel {
+			<-wait
+		} else {
+			select {
+			case <-wait:
+			case <-req.Context().Done():
+				return nil, req.Context().Err()
+			}
+		}
+	}
+	if a.err != nil {
+		return nil, a.err
+	}
+	return &http.Response{StatusCode: a.status, Body: a.body, Header: http.Header{}}, nil
+}
+
+// waitClosed waits for ch to be closed
+func waitClosed(t *testing.T, ch <-chan struct{}, what string) {
+	t.Helper()
+	select {
+	case <-ch:
+	case <-time.After(time.Second):
+		t.Fatalf("%s did not happen", what)
+	}
+}
+
+func TestHedgedRoundTrip(t *testing.T) {
+	errFirst := errors.New("first attempt failed")
+	never := make(chan struct{})
+
+	tests := []struct {
+		name      string
+		attempts  []*hedgeAttempt
+		wantCalls int
+		// winner is the attempt whose response is returned, or -1 if an error is
+		winner     int
+		wantErr    error
+		wantStatus int
+	}{
+		{
+			name:       "fast response launches no hedge",
+			attempts:   []*hedgeAttempt{{status: http.StatusOK}},
+			wantCalls:  1,
+			winner:     0,
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:      "failure before the delay launches no hedge",
+			attempts:  []*hedgeAttempt{{err: errFirst}},
+			wantCalls: 1,
+			winner:    -1,
+			wantErr:   errFirst,
+		},
+		{
+			name:       "client error before the delay is returned as is",
+			attempts:   []*hedgeAttempt{{status: http.StatusNotFound}},
+			wantCalls:  1,
+			winner:     0,
+			wantStatus: http.StatusNotFound,
+		},
+		{
+			name:       "hedge beats a slow first attempt",
+			attempts:   []*hedgeAttempt{{wait: never, status: http.StatusOK}, {status: http.StatusOK}},
+			wantCalls:  2,
+			winner:     1,
+			wantStatus: http.StatusOK,
+		},
+		{
+			name: "server error does not win",
+			attempts: []*hedgeAttempt{
+				{delay: 30 * time.Millisecond, status: http.StatusServiceUnavailable},
+				{delay: 50 * time.Millisecond, status: http.StatusOK},
+			},
+			wantCalls:  2,
+			winner:     1,
+			wantStatus: http.StatusOK,
+		},
+		{
+			name: "network error does not win",
+			attempts: []*hedgeAttempt{
+				{delay: 30 * time.Millisecond, err: errFirst},
+				{delay: 50 * time.Millisecond, status: http.StatusOK},
+			},
+			wantCalls:  2,
+			winner:     1,
+			wantStatus: http.StatusOK,
+		},
+		{
+			name: "first failure is returned when both fail",
+			attempts: []*hedgeAttempt{
+				{delay: 30 * time.Millisecond, err: errFirst},
+				{delay: 50 * time.Millisecond, status: http.StatusBadGateway},
+			},
+			wantCalls: 2,
+			winner:    -1,
+			wantErr:   errFirst,
+		},
+		{
+			name: "first server error is returned when both fail",
+			attempts: []*hedgeAttempt{
+				{delay: 30 * time.Millisecond, status: http.StatusServiceUnavailable},
+				{delay: 50 * time.Millisecond, status: http.StatusBadGateway},
+			},
+			wantCalls:  2,
+			winner:     0,
+			wantStatus: http.StatusServiceUnavailable,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			script := newHedgeScript(tt.attempts...)
+			req, _ := http.NewRequest(http.MethodGet, "https://api.example.com/rates", nil)
+
+			resp, err := hedgedRoundTrip(SystemClock, script.roundTrip, req, 10*time.Millisecond, nil)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("err = %v, want %v", err, tt.wantErr)
+			}
+			if got := int(script.calls.Load()); got != tt.wantCalls {
+				t.Errorf("%d attempts, want %d", got, tt.wantCalls)
+			}
+			if tt.winner >= 0 {
+				if resp.StatusCode != tt.wantStatus {
+					t.Errorf("status = %d, want %d", resp.StatusCode, tt.wantStatus)
+				}
+				winner := tt.attempts[tt.winner]
+				if winner.body.isClosed() {
+					t.Error("returned body is closed")
+				}
+				if winner.req.Context().Err() != nil {
+					t.Error("returned attempt was cancelled before its body was closed")
+				}
+				resp.Body.Close()
+				waitClosed(t, winner.body.closed, "closing the returned body")
+			}
+
+			// Every attempt is released: losers are cancelled and their bodies closed
+			for i, a := range tt.attempts[:tt.wantCalls] {
+				waitClosed(t, a.cancelled, "cancelling attempt")
+				if i != tt.winner && a.err == nil && a.wait != never {
+					waitClosed(t, a.body.closed, "closing the losing body")
+				}
+			}
+		})
+	}
+}
+
+func TestHedgedRoundTripClosesLateLoser(t *testing.T) {
+	release := make(chan struct{})
+	first := &hedgeAttempt{wait: release, ignoreCancel: true, status: http.StatusOK}
+	script := newHedgeScript(first, &hedgeAttempt{status: http.StatusOK})
+	req, _ := http.NewRequest(http.MethodGet, "https://api.example.com/rates", nil)
+
+	resp, err := hedgedRoundTrip(SystemClock, script.roundTrip, req, 10*time.Millisecond, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	waitClosed(t, first.cancelled, "cancelling the losing attempt")
+
+	// The loser answers anyway, after the winner was returned
+	close(release)
+	waitClosed(t, first.body.closed, "closing the late losing body")
+}
+
+func TestHedgedRoundTripPreparesHedge(t *testing.T) {
+	never := make(chan struct{})
+	first := &hedgeAttempt{wait: never, status: http.StatusOK}
+	hedge := &hedgeAttempt{status: http.StatusOK}
+	script := newHedgeScript(first, hedge)
+	req, _ := http.NewRequest(http.MethodGet, "https://api.example.com/rates", nil)
+	req.Header.Set("X-Nonce", "original")
+
+	prepare := func(r *http.Request) error {
+		r.Header.Set("X-Nonce", "fresh")
+		return nil
+	}
+	resp, err := hedgedRoundTrip(SystemClock, script.roundTrip, req, 10*time.Millisecond, prepare)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	waitClosed(t, first.cancelled, "cancelling the first attempt")
+
+	if got := first.req.Header.Get("X-Nonce"); got != "original" {
+		t.Errorf("first attempt nonce = %q, want original", got)
+	}
+	if got := hedge.req.Header.Get("X-Nonce"); got != "fresh" {
+		t.Errorf("hedge nonce = %q, want fresh", got)
+	}
+	if got := req.Header.Get("X-Nonce"); got != "original" {
+		t.Errorf("caller's request was modified: nonce = %q", got)
+	}
+}
+
+func TestHedgedRoundTripPrepareFailure(t *testing.T) {
+	errSign := errors.New("signing failed")
+	first := &hedgeAttempt{delay: 30 * time.Millisecond, status: http.StatusOK}
+	script := newHedgeScript(first)
+	req, _ := http.NewRequest(http.MethodGet, "https://api.example.com/rates", nil)
+
+	resp, err := hedgedRoundTrip(SystemClock, script.roundTrip, req, 10*time.Millisecond, func(*http.Request) error { return errSign })
+	if err != nil {
+		t.Fatalf("a hedge that could not be prepared must not fail the request: %v", err)
+	}
+	resp.Body.Close()
+	if got := script.calls.Load(); got != 1 {
+		t.Errorf("%d attempts sent, want 1", got)
+	}
+}
@@ -0,0 +1,68 @@
+package itispay
+
+import (
+	"context"
+	"net/http"
+)
+
+// RoundTripFunc sends a single HTTP request and returns its response
+type RoundTripFunc func(req *http.Request) (*http.Response, error)
+
+// Interceptor wraps the request pipeline. It may inspect or modify the request, call next
+// (or short-circuit it), and inspect the response.
+type Interceptor func(next RoundTripFunc) RoundTripFunc
+
+// WithInterceptor registers interceptors on the client. Interceptors run in the order they
+// are registered: the first one sees the request first and the response last.
+func WithInterceptor(interceptors ...Interceptor) Option {
+	return func(c *Client) {
+		c.interceptors = append(c.interceptors, interceptors...)
+	}
+}
+
+// roundTripper builds the interceptor chain around httpClient
+func (c *Client) roundTripper(httpClient *http.Client) RoundTripFunc {
+	next := RoundTripFunc(httpClient.Do)
+	if c.compression.enabled() {
+		next = c.compression.intercept(next)
+	}
+	if c.journal != nil {
+		// Innermost, so the journal records the request exactly as sent
+		next = c.journal.intercept(next)
+	}
+	if c.debug != nil {
+		// Inside the interceptors, so the dump shows the request exactly as sent
+		next = c.debug.intercept(next)
+	}
+	if c.conditional != nil {
+		// Outside the journal and debug dumps, so they show the 304 actually received
+		next = c.conditional.intercept(next)
+	}
+	for i := len(c.interceptors) - 1; i >= 0; i-- {
+		next = c.interceptors[i](next)
+	}
+	return next
+}
+
+// metadataKey is the context key for request metadata
+type metadataKey struct{}
+
+// ContextWithMetadata returns a context carrying the key/value pair as request metadata.
+// Interceptors can read it via MetadataFromContext(req.Context()) to propagate tenant IDs,
+// trace IDs or audit information.
+func ContextWithMetadata(ctx context.Context, key, value string) context.Context {
+	existing := MetadataFromContext(ctx)
+	metadata := make(map[string]string, len(existing)+1)
+	for k, v := range existing {
+		metadata[k] = v
+	}
+	metadata[key] = value
+	return context.WithValue(ctx, metadataKey{}, metadata)
+}
+
+// MetadataFromContext returns the request metadata stored in ctx, or nil if there is none.
+// The returned map must not be modified.
+func MetadataFromContext(ctx context.Context) map[string]string {
+	metadata, _ := ctx.Value(metadataKey{}).(map[string]string)
+	return metadata
+}
@@ -0,0 +1,90 @@
+package invoicesync
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"sync"
+
+	itispay "github.com/ItIsPay/go-client"
+)
+
+// MemoryStore is an in-process Store, e.g. for tests or short-lived reporting jobs
+type MemoryStore struct {
+	mu       sync.RWMutex
+	invoices map[string]itispay.Invoice
+	cursor   Cursor
+}
+
+// NewMemoryStore returns an empty in-memory store
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{invoices: make(map[string]itispay.Invoice)}
+}
+
+// UpsertInvoices implements Store
+func (m *MemoryStore) UpsertInvoices(ctx context.Context, invoices []itispay.Invoice) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, invoice := range invoices {
+		if stored, ok := m.invoices[invoice.InvoiceID]; ok && stored.UpdatedAt.After(invoice.UpdatedAt) {
+			continue
+		}
+		m.invoices[invoice.InvoiceID] = invoice
+	}
+	return nil
+}
+
+// LoadCursor implements Store
+func (m *MemoryStore) LoadCursor(ctx context.Context) (Cursor, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.cursor, nil
+}
+
+// SaveCursor implements Store
+func (m *MemoryStore) SaveCursor(ctx context.Context, cursor Cursor) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.cursor = cursor
+	return nil
+}
+
+// Query implements Store
+func (m *MemoryStore) Query(ctx context.Context, q Query) ([]itispay.Invoice, error) {
+	m.mu.RLock()
+	var matched []itispay.Invoice
+	for _, invoice := range m.invoices {
+		if q.matches(&invoice) {
+			matched = append(matched, invoice)
+		}
+	}
+	m.mu.RUnlock()
+
+	sort.Slice(matched, func(i, j int) bool {
+		if !matched[i].CreatedAt.Equal(matched[j].CreatedAt) {
+			return matched[i].CreatedAt.After(matched[j].CreatedAt)
+		}
+		return matched[i].InvoiceID > matched[j].InvoiceID
+	})
+	if q.Limit > 0 && len(matched) > q.Limit {
+		matched = matched[:q.Limit]
+	}
+	return matched, nil
+}
+
+// matches reports whether invoice is selected by q
+func (q *Query) matches(invoice *itispay.Invoice) bool {
+	switch {
+	case q.Status != "" && string(invoice.Status) != q.Status:
+		return false
+	case q.Currency != "" && !strings.EqualFold(invoice.Currency, q.Currency):
+		return false
+	case q.OrderID != "" && invoice.OrderID != q.OrderID:
+		return false
+	case !q.CreatedAfter.IsZero() && !invoice.CreatedAt.After(q.CreatedAfter):
+		return false
+	case !q.CreatedBefore.IsZero() && !invoice.CreatedAt.Before(q.CreatedBefore):
+		return false
+	}
+	return true
+}
@@ -0,0 +1,174 @@
+package invoicesync
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	itispay "github.com/ItIsPay/go-client"
+)
+
+// sqlSchema creates the tables of SQLStore
+const sqlSchema = `
+CREATE TABLE IF NOT EXISTS itispay_invoices (
+	invoice_id TEXT PRIMARY KEY,
+	order_id   TEXT NOT NULL,
+	status     TEXT NOT NULL,
+	currency   TEXT NOT NULL,
+	created_at INTEGER NOT NULL,
+	updated_at INTEGER NOT NULL,
+	data       TEXT NOT NULL
+);
+CREATE INDEX IF NOT EXISTS itispay_invoices_created_at ON itispay_invoices (created_at);
+CREATE INDEX IF NOT EXISTS itispay_invoices_order_id ON itispay_invoices (order_id);
+CREATE TABLE IF NOT EXISTS itispay_sync_cursor (
+	id   INTEGER PRIMARY KEY CHECK (id = 1),
+	data TEXT NOT NULL
+);`
+
+// SQLStore is the reference Store on SQLite through database/sql. Open db with any
+// SQLite driver, e.g. modernc.org/sqlite or github.com/mattn/go-sqlite3. Invoices are
+// stored as JSON alongside indexed columns for the Query filters.
+type SQLStore struct {
+	db *sql.DB
+}
+
+// NewSQLStore returns a store on db, creating its tables if needed
+func NewSQLStore(ctx context.Context, db *sql.DB) (*SQLStore, error) {
+	for _, statement := range strings.Split(sqlSchema, ";") {
+		if strings.TrimSpace(statement) == "" {
+			continue
+		}
+		if _, err := db.ExecContext(ctx, statement); err != nil {
+			return nil, fmt.Errorf("invoicesync: creating schema: %w", err)
+		}
+	}
+	return &SQLStore{db: db}, nil
+}
+
+// UpsertInvoices implements Store
+func (s *SQLStore) UpsertInvoices(ctx context.Context, invoices []itispay.Invoice) error {
+	if len(invoices) == 0 {
+		return nil
+	}
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	stmt, err := tx.PrepareContext(ctx, `
+INSERT INTO itispay_invoices (invoice_id, order_id, status, currency, created_at, updated_at, data)
+VALUES (?, ?, ?, ?, ?, ?, ?)
+ON CONFLICT (invoice_id) DO UPDATE SET
+	order_id = excluded.order_id,
+	status = excluded.status,
+	currency = excluded.currency,
+	created_at = excluded.created_at,
+	updated_at = excluded.updated_at,
+	data = excluded.data
+WHERE excluded.updated_at >= itispay_invoices.updated_at`)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	for _, invoice := range invoices {
+		data, err := json.Marshal(invoice)
+		if err != nil {
+			return err
+		}
+		_, err = stmt.ExecContext(ctx, invoice.InvoiceID, invoice.OrderID, string(invoice.Status),
+			strings.ToUpper(invoice.Currency), invoice.CreatedAt.UnixNano(), invoice.UpdatedAt.UnixNano(), string(data))
+		if err != nil {
+			return fmt.Errorf("invoicesync: storing invoice %s: %w", invoice.InvoiceID, err)
+		}
+	}
+	return tx.Commit()
+}
+
+// LoadCursor implements Store
+func (s *SQLStore) LoadCursor(ctx context.Context) (Cursor, error) {
+	var data string
+	err := s.db.QueryRowContext(ctx, `SELECT data FROM itispay_sync_cursor WHERE id = 1`).Scan(&data)
+	if err == sql.ErrNoRows {
+		return Cursor{}, nil
+	}
+	if err != nil {
+		return Cursor{}, err
+	}
+	var cursor Cursor
+	if err := json.Unmarshal([]byte(data), &cursor); err != nil {
+		return Cursor{}, fmt.Errorf("invoicesync: decoding cursor: %w", err)
+	}
+	return cursor, nil
+}
+
+// SaveCursor implements Store
+func (s *SQLStore) SaveCursor(ctx context.Context, cursor Cursor) error {
+	data, err := json.Marshal(cursor)
+	if err != nil {
+		return err
+	}
+	_, err = s.db.ExecContext(ctx, `
+INSERT INTO itispay_sync_cursor (id, data) VALUES (1, ?)
+ON CONFLICT (id) DO UPDATE SET data = excluded.data`, string(data))
+	return err
+}
+
+// Query implements Store
+func (s *SQLStore) Query(ctx context.Context, q Query) ([]itispay.Invoice, error) {
+	var conditions []string
+	var args []interface{}
+	add := func(condition string, arg interface{}) {
+		conditions = append(conditions, condition)
+		args = append(args, arg)
+	}
+	if q.Status != "" {
+		add("status = ?", q.Status)
+	}
+	if q.Currency != "" {
+		add("currency = ?", strings.ToUpper(q.Currency))
+	}
+	if q.OrderID != "" {
+		add("order_id = ?", q.OrderID)
+	}
+	if !q.CreatedAfter.IsZero() {
+		add("created_at > ?", q.CreatedAfter.UnixNano())
+	}
+	if !q.CreatedBefore.IsZero() {
+		add("created_at < ?", q.CreatedBefore.UnixNano())
+	}
+
+	query := "SELECT data FROM itispay_invoices"
+	if len(conditions) > 0 {
+		query += " WHERE " + strings.Join(conditions, " AND ")
+	}
+	query += " ORDER BY created_at DESC, invoice_id DESC"
+	if q.Limit > 0 {
+		query += " LIMIT ?"
+		args = append(args, q.Limit)
+	}
+
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var invoices []itispay.Invoice
+	for rows.Next() {
+		var data string
+		if err := rows.Scan(&data); err != nil {
+			return nil, err
+		}
+		var invoice itispay.Invoice
+		if err := json.Unmarshal([]byte(data), &invoice); err != nil {
+			return nil, fmt.Errorf("invoicesync: decoding invoice: %w", err)
+		}
+		invoices = append(invoices, invoice)
+	}
+	return invoices, rows.Err()
+}
@@ -0,0 +1,259 @@
+// Package invoicesync incrementally mirrors invoices into a local store, for fast local
+// queries and offline reporting without paging through the API:
+//
+//	db, _ := sql.Open("sqlite", "invoices.db") // e.g. with modernc.org/sqlite
+//	local, _ := invoicesync.NewSQLStore(ctx, db)
+//	syncer := invoicesync.New(client, local)
+//	go syncer.Run(ctx)
+//
+//	paid, err := local.Query(ctx, invoicesync.Query{Status: itispay.StatusCompleted})
+//
+// The first sync backfills invoices in creation order from a created_after cursor and
+// can be resumed after an interruption. Later syncs walk invoices by descending
+// updated_at until they reach the last change already mirrored, starting over if the
+// number of invoices changes meanwhile.
+package invoicesync
+
+import (
+	"context"
+	"time"
+
+	itispay "github.com/ItIsPay/go-client"
+)
+
+// Syncer defaults
+const (
+	DefaultPageSize = 100
+	DefaultInterval = time.Minute
+)
+
+// Cursor records the progress of the sync
+type Cursor struct {
+	// Backfilled is set once the initial backfill completed
+	Backfilled bool `json:"backfilled"`
+	// CreatedAfter is the creation time of the last invoice backfilled
+	CreatedAfter time.Time `json:"created_after"`
+	// UpdatedAt is the latest update time mirrored
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// Query selects mirrored invoices; zero fields match everything
+type Query struct {
+	Status        string
+	Currency      string
+	OrderID       string
+	CreatedAfter  time.Time
+	CreatedBefore time.Time
+	// Limit caps the number of invoices returned, all if zero
+	Limit int
+}
+
+// Store persists mirrored invoices and the sync cursor. Implementations must be safe for
+// concurrent use.
+type Store interface {
+	// UpsertInvoices inserts or replaces invoices, keeping the stored copy if it is newer
+	UpsertInvoices(ctx context.Context, invoices []itispay.Invoice) error
+	// LoadCursor returns the saved cursor, the zero Cursor if none was saved
+	LoadCursor(ctx context.Context) (Cursor, error)
+	SaveCursor(ctx context.Context, cursor Cursor) error
+	// Query returns the invoices matching q, newest first
+	Query(ctx context.Context, q Query) ([]itispay.Invoice, error)
+}
+
+// Result summarizes a sync
+type Result struct {
+	// Upserted is the number of invoices written to the store
+	Upserted int
+	Cursor   Cursor
+}
+
+// Syncer mirrors the invoices of an account into a Store
+type Syncer struct {
+	client *itispay.Client
+	store  Store
+
+	// Since limits the backfill to invoices created after it, all invoices if zero
+	Since time.Time
+	// PageSize is the number of invoices fetched per request, DefaultPageSize if zero
+	PageSize int
+	// Interval is the time between syncs in Run, DefaultInterval if zero
+	Interval time.Duration
+	// OnError, if set, is called with the errors of syncs started by Run
+	OnError func(err error)
+	// Clock schedules the syncs of Run, itispay.SystemClock if nil
+	Clock itispay.Clock
+}
+
+// New returns a syncer mirroring the invoices visible to client into store
+func New(client *itispay.Client, store Store) *Syncer {
+	return &Syncer{client: client, store: store}
+}
+
+// Run syncs every Interval, counted from the end of the previous sync, until ctx is
+// done. Failed syncs are retried on the next one.
+func (s *Syncer) Run(ctx context.Context) error {
+	interval := s.Interval
+	if interval <= 0 {
+		interval = DefaultInterval
+	}
+	clock := s.Clock
+	if clock == nil {
+		clock = itispay.SystemClock
+	}
+
+	for {
+		if _, err := s.Sync(ctx); err != nil && ctx.Err() == nil && s.OnError != nil {
+			s.OnError(err)
+		}
+		timer := clock.NewTimer(interval)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C():
+		}
+	}
+}
+
+// Sync mirrors the invoices created or updated since the last sync
+func (s *Syncer) Sync(ctx context.Context) (*Result, error) {
+	cursor, err := s.store.LoadCursor(ctx)
+	if err != nil {
+		return nil, err
+	}
+	result := &Result{}
+	if !cursor.Backfilled {
+		if err := s.backfill(ctx, &cursor, result); err != nil {
+			result.Cursor = cursor
+			return result, err
+		}
+	}
+	err = s.catchUp(ctx, &cursor, result)
+	result.Cursor = cursor
+	return result, err
+}
+
+// backfill mirrors invoices in creation order, saving the cursor after every page so an
+// interrupted backfill resumes where it stopped
+func (s *Syncer) backfill(ctx context.Context, cursor *Cursor, result *Result) error {
+	createdAfter := cursor.CreatedAfter
+	if createdAfter.IsZero() {
+		createdAfter = s.Since
+	}
+	params := itispay.ListInvoicesParams{
+		Page:         1,
+		PageSize:     s.pageSize(),
+		CreatedAfter: createdAfter,
+		SortBy:       itispay.SortByCreatedAt,
+		SortOrder:    itispay.SortOrderAsc,
+	}
+	for {
+		response, err := s.client.ListInvoices(ctx, params)
+		if err != nil {
+			return err
+		}
+		if err := s.store.UpsertInvoices(ctx, response.Items); err != nil {
+			return err
+		}
+		result.Upserted += len(response.Items)
+		for _, invoice := range response.Items {
+			if invoice.CreatedAt.After(cursor.CreatedAfter) {
+				cursor.CreatedAfter = invoice.CreatedAt
+			}
+			if invoice.UpdatedAt.After(cursor.UpdatedAt) {
+				cursor.UpdatedAt = invoice.UpdatedAt
+			}
+		}
+		done := !response.Pagination.HasNext || len(response.Items) == 0
+		cursor.Backfilled = done
+		if err := s.store.SaveCursor(ctx, *cursor); err != nil {
+			return err
+		}
+		if done {
+			return nil
+		}
+		params.Page++
+	}
+}
+
+// maxCatchUpWalks bounds the walks of one catch-up while the number of invoices keeps
+// changing
+const maxCatchUpWalks = 3
+
+// catchUp mirrors invoices updated since the cursor, walking them by descending update
+// time until it reaches changes already mirrored. Pages are offsets, so invoices
+// appearing or disappearing during the walk shift the later pages and the walk starts
+// over. If it cannot complete, the cursor is left for the next sync to walk again.
+func (s *Syncer) catchUp(ctx context.Context, cursor *Cursor, result *Result) error {
+	latest := cursor.UpdatedAt
+	for walk := 1; ; walk++ {
+		complete, err := s.walkUpdates(ctx, cursor.UpdatedAt, &latest, result)
+		if err != nil {
+			return err
+		}
+		if complete {
+			break
+		}
+		if walk == maxCatchUpWalks {
+			return nil
+		}
+	}
+
+	// The cursor only advances once a walk completed, as it runs newest first
+	cursor.UpdatedAt = latest
+	return s.store.SaveCursor(ctx, *cursor)
+}
+
+// walkUpdates upserts the invoices updated at or after since, page by page, raising
+// latest to the newest update seen. It reports false if the number of invoices changed
+// during the walk, since pages may then have been skipped.
+func (s *Syncer) walkUpdates(ctx context.Context, since time.Time, latest *time.Time, result *Result) (bool, error) {
+	params := itispay.ListInvoicesParams{
+		Page:      1,
+		PageSize:  s.pageSize(),
+		SortBy:    itispay.SortByUpdatedAt,
+		SortOrder: itispay.SortOrderDesc,
+	}
+	var total int64
+	for {
+		response, err := s.client.ListInvoices(ctx, params)
+		if err != nil {
+			return false, err
+		}
+		if params.Page == 1 {
+			total = response.Pagination.TotalRecords
+		} else if response.Pagination.TotalRecords != total {
+			return false, nil
+		}
+		changed := response.Items
+		for i, invoice := range response.Items {
+			// Invoices updated at exactly the cursor are upserted again, since others
+			// may share the timestamp
+			if invoice.UpdatedAt.Before(since) {
+				changed = response.Items[:i]
+				break
+			}
+		}
+		if err := s.store.UpsertInvoices(ctx, changed); err != nil {
+			return false, err
+		}
+		result.Upserted += len(changed)
+		for _, invoice := range changed {
+			if invoice.UpdatedAt.After(*latest) {
+				*latest = invoice.UpdatedAt
+			}
+		}
+		if len(changed) < len(response.Items) || !response.Pagination.HasNext || len(response.Items) == 0 {
+			return true, nil
+		}
+		params.Page++
+	}
+}
+
+// pageSize returns the configured page size
+func (s *Syncer) pageSize() int {
+	if s.PageSize > 0 {
+		return s.PageSize
+	}
+	return DefaultPageSize
+}
@@ -0,0 +1,125 @@
+package itispaytest
+
+import (
+	"math"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"time"
+
+	itispay "github.com/ItIsPay/go-client"
+)
+
+// malformedBodies are the broken payloads SetMalformedRate responds with
+var malformedBodies = []string{
+	`{"invoice_id": "inv_`,
+	`<html><body><h1>502 Bad Gateway</h1></body></html>`,
+	`{"status": "ok"}}`,
+	``,
+}
+
+// NormalLatency delays requests by a normally distributed duration, never negative
+func NormalLatency(mean, stddev time.Duration) Latency {
+	return LatencyFunc(func() time.Duration {
+		d := time.Duration(rand.NormFloat64()*float64(stddev)) + mean
+		if d < 0 {
+			return 0
+		}
+		return d
+	})
+}
+
+// SetErrorRate makes a fraction (0-1) of the requests to an endpoint (see SetDown) fail
+// with statusCode, 503 if zero; a rate of zero removes the fault
+func (s *Server) SetErrorRate(endpoint string, statusCode int, rate float64) {
+	if statusCode == 0 {
+		statusCode = http.StatusServiceUnavailable
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	state := s.endpoint(endpoint)
+	state.errorStatus, state.errorRate = statusCode, rate
+}
+
+// SetMalformedRate makes a fraction (0-1) of the requests to an endpoint succeed with a
+// malformed JSON body, such as a truncated object or an HTML error page from a proxy
+func (s *Server) SetMalformedRate(endpoint string, rate float64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.endpoint(endpoint).malformedRate = rate
+}
+
+// SetDropRate makes a fraction (0-1) of the requests to an endpoint fail with a closed
+// connection and no response, as with a crashed load balancer
+func (s *Server) SetDropRate(endpoint string, rate float64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.endpoint(endpoint).dropRate = rate
+}
+
+// RateLimitStorm makes every request to an endpoint fail with 429 Too Many Requests and
+// the given Retry-After for duration, e.g. to check that retries back off and that the
+// circuit breaker does not open on rate limiting
+func (s *Server) RateLimitStorm(endpoint string, duration, retryAfter time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	state := s.endpoint(endpoint)
+	state.rateLimitedUntil = time.Now().Add(duration)
+	state.retryAfter = retryAfter
+}
+
+// InjectedFaults returns the number of requests that received an injected fault:
+// errors, malformed bodies, dropped connections and rate limiting, but not SetDown
+func (s *Server) InjectedFaults() int {
+	return int(s.faults.Load())
+}
+
+// injectFault applies the chaos settings of state to a request, reporting whether it
+// was answered
+func (s *Server) injectFault(w http.ResponseWriter, state endpointState) bool {
+	switch {
+	case time.Now().Before(state.rateLimitedUntil):
+		if state.retryAfter > 0 {
+			w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(state.retryAfter.Seconds()))))
+		}
+		writeError(w, http.StatusTooManyRequests, itispay.ErrCodeRateLimited, "rate limit exceeded")
+	case state.dropRate > 0 && rand.Float64() < state.dropRate:
+		hijacker, ok := w.(http.Hijacker)
+		if !ok {
+			return false
+		}
+		conn, _, err := hijacker.Hijack()
+		if err != nil {
+			return false
+		}
+		conn.Close()
+	case state.errorRate > 0 && rand.Float64() < state.errorRate:
+		writeError(w, state.errorStatus, errorCodeForStatus(state.errorStatus), "injected fault")
+	case state.malformedRate > 0 && rand.Float64() < state.malformedRate:
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte(malformedBodies[rand.Intn(len(malformedBodies))]))
+	default:
+		return false
+	}
+	s.faults.Add(1)
+	return true
+}
+
+// errorCodeForStatus returns the error code the API uses for an HTTP status
+func errorCodeForStatus(statusCode int) itispay.ErrorCode {
+	switch {
+	case statusCode == http.StatusTooManyRequests:
+		return itispay.ErrCodeRateLimited
+	case statusCode == http.StatusServiceUnavailable:
+		return itispay.ErrCodeServiceUnavailable
+	case statusCode == http.StatusUnauthorized:
+		return itispay.ErrCodeUnauthorized
+	case statusCode == http.StatusNotFound:
+		return itispay.ErrCodeNotFound
+	case statusCode >= 500:
+		return itispay.ErrCodeInternal
+	default:
+		return itispay.ErrCodeInvalidRequest
+	}
+}
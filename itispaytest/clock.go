@@ -0,0 +1,142 @@
+package itispaytest
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	itispay "github.com/ItIsPay/go-client"
+)
+
+// FakeClock is an itispay.Clock whose time only moves when advanced, so polling and
+// expiry logic can be tested without sleeping:
+//
+//	clock := itispaytest.NewFakeClock(time.Now())
+//	client := srv.Client(itispay.WithClock(clock))
+//	go client.CreateInvoice(ctx, req, itispay.WithReadBack())
+//	clock.BlockUntil(ctx, 1) // the read-back is waiting
+//	clock.Advance(time.Second)
+type FakeClock struct {
+	mu      sync.Mutex
+	now     time.Time
+	timers  []*fakeTimer
+	changed chan struct{}
+}
+
+// NewFakeClock returns a fake clock set to now
+func NewFakeClock(now time.Time) *FakeClock {
+	return &FakeClock{now: now, changed: make(chan struct{})}
+}
+
+// Now implements itispay.Clock
+func (c *FakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+// NewTimer implements itispay.Clock. The timer fires once the clock is advanced to its
+// deadline; timers of zero or negative duration fire immediately.
+func (c *FakeClock) NewTimer(d time.Duration) itispay.Timer {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	t := &fakeTimer{clock: c, deadline: c.now.Add(d), ch: make(chan time.Time, 1)}
+	if d <= 0 {
+		t.ch <- c.now
+		return t
+	}
+	c.timers = append(c.timers, t)
+	c.notify()
+	return t
+}
+
+// Advance moves the clock forward by d, firing the timers that became due in deadline
+// order
+func (c *FakeClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.set(c.now.Add(d))
+}
+
+// Set moves the clock to now, firing the timers that became due. Moving it backwards
+// fires nothing.
+func (c *FakeClock) Set(now time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.set(now)
+}
+
+// Waiters returns the number of pending timers
+func (c *FakeClock) Waiters() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.timers)
+}
+
+// BlockUntil waits until at least n timers are pending, i.e. the code under test is
+// waiting on the clock, or ctx is done
+func (c *FakeClock) BlockUntil(ctx context.Context, n int) error {
+	for {
+		c.mu.Lock()
+		pending, changed := len(c.timers), c.changed
+		c.mu.Unlock()
+		if pending >= n {
+			return nil
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-changed:
+		}
+	}
+}
+
+// set moves the clock to now and fires due timers; c.mu must be held
+func (c *FakeClock) set(now time.Time) {
+	c.now = now
+	sort.SliceStable(c.timers, func(i, j int) bool { return c.timers[i].deadline.Before(c.timers[j].deadline) })
+	pending := c.timers[:0]
+	for _, t := range c.timers {
+		if t.deadline.After(now) {
+			pending = append(pending, t)
+		} else {
+			t.ch <- now
+		}
+	}
+	c.timers = pending
+	c.notify()
+}
+
+// notify wakes up BlockUntil callers; c.mu must be held
+func (c *FakeClock) notify() {
+	close(c.changed)
+	c.changed = make(chan struct{})
+}
+
+// fakeTimer is a timer of a FakeClock
+type fakeTimer struct {
+	clock    *FakeClock
+	deadline time.Time
+	ch       chan time.Time
+}
+
+// C implements itispay.Timer
+func (t *fakeTimer) C() <-chan time.Time {
+	return t.ch
+}
+
+// Stop implements itispay.Timer
+func (t *fakeTimer) Stop() bool {
+	c := t.clock
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for i, pending := range c.timers {
+		if pending == t {
+			c.timers = append(c.timers[:i], c.timers[i+1:]...)
+			c.notify()
+			return true
+		}
+	}
+	return false
+}
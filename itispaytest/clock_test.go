@@ -0,0 +1,113 @@
+package itispaytest
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+// fired returns the time a timer fired at, and whether it fired
+func fired(ch <-chan time.Time) (time.Time, bool) {
+	select {
+	case t := <-ch:
+		return t, true
+	default:
+		return time.Time{}, false
+	}
+}
+
+func TestFakeClockTimers(t *testing.T) {
+	start := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	clock := NewFakeClock(start)
+
+	late := clock.NewTimer(2 * time.Second)
+	early := clock.NewTimer(time.Second)
+	stopped := clock.NewTimer(time.Second)
+	if got := clock.Waiters(); got != 3 {
+		t.Fatalf("Waiters() = %d, want 3", got)
+	}
+	if !stopped.Stop() {
+		t.Error("Stop() of a pending timer = false")
+	}
+	if stopped.Stop() {
+		t.Error("second Stop() = true")
+	}
+
+	clock.Advance(time.Second)
+	if at, ok := fired(early.C()); !ok || !at.Equal(start.Add(time.Second)) {
+		t.Errorf("early timer fired = %t at %v", ok, at)
+	}
+	if _, ok := fired(late.C()); ok {
+		t.Error("late timer fired before its deadline")
+	}
+	if _, ok := fired(stopped.C()); ok {
+		t.Error("stopped timer fired")
+	}
+
+	clock.Advance(time.Second)
+	if _, ok := fired(late.C()); !ok {
+		t.Error("late timer did not fire at its deadline")
+	}
+	if got := clock.Waiters(); got != 0 {
+		t.Errorf("Waiters() = %d after all timers fired", got)
+	}
+	if late.Stop() {
+		t.Error("Stop() of a fired timer = true")
+	}
+}
+
+func TestFakeClockNowAndSet(t *testing.T) {
+	start := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	clock := NewFakeClock(start)
+	if !clock.Now().Equal(start) {
+		t.Fatalf("Now() = %v, want %v", clock.Now(), start)
+	}
+
+	// Zero and negative durations fire immediately
+	for _, d := range []time.Duration{0, -time.Second} {
+		if _, ok := fired(clock.NewTimer(d).C()); !ok {
+			t.Errorf("timer of %v did not fire immediately", d)
+		}
+	}
+
+	timer := clock.NewTimer(time.Minute)
+	clock.Set(start.Add(-time.Hour))
+	if _, ok := fired(timer.C()); ok {
+		t.Error("moving the clock backwards fired a timer")
+	}
+	clock.Set(start.Add(time.Hour))
+	if _, ok := fired(timer.C()); !ok {
+		t.Error("Set past the deadline did not fire the timer")
+	}
+	if want := start.Add(time.Hour); !clock.Now().Equal(want) {
+		t.Errorf("Now() = %v, want %v", clock.Now(), want)
+	}
+}
+
+func TestFakeClockBlockUntil(t *testing.T) {
+	clock := NewFakeClock(time.Now())
+	done := make(chan time.Time)
+	go func() {
+		timer := clock.NewTimer(time.Second)
+		done <- <-timer.C()
+	}()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if err := clock.BlockUntil(ctx, 1); err != nil {
+		t.Fatalf("BlockUntil: %v", err)
+	}
+	clock.Advance(time.Second)
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("waiting goroutine was not woken up")
+	}
+
+	// BlockUntil gives up with its context
+	ctx, cancel = context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := clock.BlockUntil(ctx, 1); err != context.DeadlineExceeded {
+		t.Errorf("BlockUntil without waiters = %v, want context.DeadlineExceeded", err)
+	}
+}
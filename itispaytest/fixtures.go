@@ -0,0 +1,215 @@
+package itispaytest
+
+import (
+	"embed"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"path"
+	"sort"
+	"strings"
+	"time"
+
+	itispay "github.com/ItIsPay/go-client"
+)
+
+//go:embed fixtures/*.json
+var fixtures embed.FS
+
+// fixtureStatusCodes are the HTTP statuses of the error fixtures
+var fixtureStatusCodes = map[itispay.ErrorCode]int{
+	itispay.ErrCodeInvalidRequest:   http.StatusBadRequest,
+	itispay.ErrCodeAmountTooSmall:   http.StatusBadRequest,
+	itispay.ErrCodeUnauthorized:     http.StatusUnauthorized,
+	itispay.ErrCodeNotFound:         http.StatusNotFound,
+	itispay.ErrCodeDuplicateOrderID: http.StatusConflict,
+	itispay.ErrCodeRateLimited:      http.StatusTooManyRequests,
+	itispay.ErrCodeInternal:         http.StatusInternalServerError,
+}
+
+// Fixture returns a canonical API payload by name, e.g. "invoice_completed",
+// "webhook_completed", "currencies", "rates" or "error_not_found"; see FixtureNames.
+// It panics for unknown names.
+func Fixture(name string) []byte {
+	data, err := fixtures.ReadFile("fixtures/" + name + ".json")
+	if err != nil {
+		panic(fmt.Sprintf("itispaytest: unknown fixture %q", name))
+	}
+	return data
+}
+
+// FixtureNames returns the names of all fixtures
+func FixtureNames() []string {
+	entries, _ := fixtures.ReadDir("fixtures")
+	names := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		names = append(names, strings.TrimSuffix(entry.Name(), path.Ext(entry.Name())))
+	}
+	sort.Strings(names)
+	return names
+}
+
+// InvoiceOption customizes an invoice built by NewTestInvoice
+type InvoiceOption func(*invoiceSpec)
+
+// invoiceSpec collects the options of NewTestInvoice: the status picks the fixture,
+// the edits are applied to it in order
+type invoiceSpec struct {
+	status itispay.Status
+	edits  []func(*itispay.Invoice)
+}
+
+// WithStatus starts from the fixture of status, with payment fields consistent with it,
+// e.g. a transaction and full confirmations for StatusCompleted
+func WithStatus(status itispay.Status) InvoiceOption {
+	return func(s *invoiceSpec) {
+		s.status = status
+	}
+}
+
+// WithInvoiceID sets the invoice ID
+func WithInvoiceID(invoiceID string) InvoiceOption {
+	return edit(func(invoice *itispay.Invoice) {
+		invoice.InvoiceID = invoiceID
+	})
+}
+
+// WithOrderID sets the order ID
+func WithOrderID(orderID string) InvoiceOption {
+	return edit(func(invoice *itispay.Invoice) {
+		invoice.OrderID = orderID
+	})
+}
+
+// WithCustomerID sets the customer ID
+func WithCustomerID(customerID string) InvoiceOption {
+	return edit(func(invoice *itispay.Invoice) {
+		invoice.CustomerID = customerID
+	})
+}
+
+// WithMetadata adds a metadata entry
+func WithMetadata(key, value string) InvoiceOption {
+	return edit(func(invoice *itispay.Invoice) {
+		if invoice.Metadata == nil {
+			invoice.Metadata = make(map[string]string)
+		}
+		invoice.Metadata[key] = value
+	})
+}
+
+// WithCreatedAt moves all timestamps of the invoice, keeping their distances, so the
+// invoice is created at t
+func WithCreatedAt(t time.Time) InvoiceOption {
+	return edit(func(invoice *itispay.Invoice) {
+		shift := t.Sub(invoice.CreatedAt)
+		invoice.CreatedAt = invoice.CreatedAt.Add(shift)
+		invoice.UpdatedAt = invoice.UpdatedAt.Add(shift)
+		invoice.ExpiresAt = invoice.ExpiresAt.Add(shift)
+		for i := range invoice.Transactions {
+			tx := &invoice.Transactions[i]
+			tx.DetectedAt = tx.DetectedAt.Add(shift)
+			if !tx.BlockTime.IsZero() {
+				tx.BlockTime = tx.BlockTime.Add(shift)
+			}
+		}
+	})
+}
+
+// edit returns an option applying fn to the invoice
+func edit(fn func(*itispay.Invoice)) InvoiceOption {
+	return func(s *invoiceSpec) {
+		s.edits = append(s.edits, fn)
+	}
+}
+
+// NewTestInvoice returns a realistic invoice for 120 USD paid in BTC, in StatusNew
+// unless WithStatus is given:
+//
+//	invoice := itispaytest.NewTestInvoice(itispaytest.WithStatus(itispay.StatusCompleted),
+//		itispaytest.WithOrderID("order-42"))
+func NewTestInvoice(opts ...InvoiceOption) *itispay.Invoice {
+	spec := invoiceSpec{status: itispay.StatusNew}
+	for _, opt := range opts {
+		opt(&spec)
+	}
+	var invoice itispay.Invoice
+	mustDecodeFixture("invoice_"+string(spec.status), &invoice)
+	for _, edit := range spec.edits {
+		edit(&invoice)
+	}
+	return &invoice
+}
+
+// NewTestWebhookPayload returns the webhook payload the API sends for invoice
+func NewTestWebhookPayload(invoice *itispay.Invoice) *itispay.WebhookPayload {
+	payload := &itispay.WebhookPayload{
+		EventID:                       "evt_" + invoice.InvoiceID + "_" + string(invoice.Status),
+		InvoiceID:                     invoice.InvoiceID,
+		Status:                        invoice.Status,
+		OrderID:                       invoice.OrderID,
+		CustomerID:                    invoice.CustomerID,
+		Currency:                      invoice.Currency,
+		CryptoAmount:                  invoice.CryptoAmount,
+		FiatAmount:                    invoice.FiatAmount,
+		FiatCurrency:                  invoice.FiatCurrency,
+		ActualCryptoAmountPaid:        invoice.ActualCryptoAmountPaid,
+		ActualCryptoAmountPaidInUnits: invoice.ActualCryptoAmountPaidInUnits,
+		AllowedErrorPercent:           invoice.AllowedErrorPercent,
+		RequiredConfirmations:         invoice.RequiredConfirmations,
+		CurrentConfirmations:          invoice.CurrentConfirmations,
+		ExternalRefs:                  invoice.ExternalRefs,
+		Metadata:                      invoice.Metadata,
+		TestMode:                      invoice.TestMode,
+		CreatedAt:                     invoice.CreatedAt,
+		UpdatedAt:                     invoice.UpdatedAt,
+		ExpiresAt:                     invoice.ExpiresAt,
+		AutoConvertTo:                 invoice.AutoConvertTo,
+		Conversion:                    invoice.Conversion,
+		RiskScore:                     invoice.RiskScore,
+		RiskFlags:                     invoice.RiskFlags,
+		Transactions:                  invoice.Transactions,
+		BlockchainDetails:             invoice.BlockchainDetails,
+	}
+	if invoice.CryptoAmount > 0 {
+		payload.PaymentRate = invoice.FiatAmount / invoice.CryptoAmount
+		payload.ActualFiatAmountPaid = invoice.ActualCryptoAmountPaid * payload.PaymentRate
+	}
+	return payload
+}
+
+// NewTestCurrencies returns the currencies of the "currencies" fixture, the same ones
+// Server offers
+func NewTestCurrencies() []itispay.Currency {
+	var currencies []itispay.Currency
+	mustDecodeFixture("currencies", &currencies)
+	return currencies
+}
+
+// NewTestRates returns the rates of the "rates" fixture, the same ones Server starts with
+func NewTestRates() *itispay.RatesResponse {
+	var rates itispay.RatesResponse
+	mustDecodeFixture("rates", &rates)
+	return &rates
+}
+
+// NewTestAPIError returns the error the client reports for the error fixture of code:
+// ErrCodeInvalidRequest, ErrCodeAmountTooSmall, ErrCodeUnauthorized, ErrCodeNotFound,
+// ErrCodeDuplicateOrderID, ErrCodeRateLimited or ErrCodeInternal. It panics for other
+// codes.
+func NewTestAPIError(code itispay.ErrorCode) *itispay.APIError {
+	statusCode, ok := fixtureStatusCodes[code]
+	if !ok {
+		panic(fmt.Sprintf("itispaytest: no error fixture for %q", code))
+	}
+	var response itispay.ErrorResponse
+	mustDecodeFixture("error_"+string(code), &response)
+	return itispay.NewAPIError(statusCode, response)
+}
+
+// mustDecodeFixture decodes a fixture into v
+func mustDecodeFixture(name string, v interface{}) {
+	if err := json.Unmarshal(Fixture(name), v); err != nil {
+		panic(fmt.Sprintf("itispaytest: decoding fixture %q: %v", name, err))
+	}
+}
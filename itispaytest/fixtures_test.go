@@ -0,0 +1,86 @@
+package itispaytest
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	itispay "github.com/ItIsPay/go-client"
+)
+
+// fixtureType returns a pointer to a new value of the type a fixture decodes into, or
+// nil if the name follows no known scheme
+func fixtureType(name string) interface{} {
+	switch {
+	case strings.HasPrefix(name, "invoice_"):
+		return new(itispay.Invoice)
+	case strings.HasPrefix(name, "webhook_"):
+		return new(itispay.WebhookPayload)
+	case strings.HasPrefix(name, "error_"):
+		return new(itispay.ErrorResponse)
+	case name == "currencies":
+		return new([]itispay.Currency)
+	case name == "rates":
+		return new(itispay.RatesResponse)
+	}
+	return nil
+}
+
+func TestFixturesDecode(t *testing.T) {
+	names := FixtureNames()
+	if len(names) == 0 {
+		t.Fatal("no fixtures embedded")
+	}
+	for _, name := range names {
+		t.Run(name, func(t *testing.T) {
+			v := fixtureType(name)
+			if v == nil {
+				t.Fatalf("no type known for fixture %q", name)
+			}
+			// Fields the types do not know about mean the fixture and the types drifted apart
+			decoder := json.NewDecoder(bytes.NewReader(Fixture(name)))
+			decoder.DisallowUnknownFields()
+			if err := decoder.Decode(v); err != nil {
+				t.Fatalf("decoding: %v", err)
+			}
+
+			switch v := v.(type) {
+			case *itispay.Invoice:
+				if want := itispay.Status(strings.TrimPrefix(name, "invoice_")); v.Status != want {
+					t.Errorf("status = %q, want %q", v.Status, want)
+				}
+			case *itispay.ErrorResponse:
+				if v.Error == "" || v.Message == "" {
+					t.Errorf("error fixture without error or message: %+v", v)
+				}
+			}
+		})
+	}
+}
+
+func TestErrorFixturesCoverStatusCodes(t *testing.T) {
+	for code, statusCode := range fixtureStatusCodes {
+		t.Run(string(code), func(t *testing.T) {
+			if _, err := fixtures.ReadFile("fixtures/error_" + string(code) + ".json"); err != nil {
+				t.Fatalf("missing error fixture: %v", err)
+			}
+			err := NewTestAPIError(code)
+			if err.Code != code {
+				t.Errorf("code = %q, want %q", err.Code, code)
+			}
+			if err.StatusCode != statusCode {
+				t.Errorf("status = %d, want %d", err.StatusCode, statusCode)
+			}
+		})
+	}
+
+	// Every error fixture has a status code
+	for _, name := range FixtureNames() {
+		if code, ok := strings.CutPrefix(name, "error_"); ok {
+			if _, ok := fixtureStatusCodes[itispay.ErrorCode(code)]; !ok {
+				t.Errorf("fixture %q has no entry in fixtureStatusCodes", name)
+			}
+		}
+	}
+}
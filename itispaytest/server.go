@@ -0,0 +1,818 @@
+// Package itispaytest provides an in-memory fake of the ItIsPay API for tests.
+//
+// Endpoints can be taken down or slowed individually, so integrations can be tested
+// against partial outages, e.g. /rates failing while /invoices stays healthy:
+//
+//	srv := itispaytest.NewServer()
+//	defer srv.Close()
+//	srv.SetDown("/rates", http.StatusServiceUnavailable)
+//	srv.SetLatency("POST /invoices", itispaytest.UniformLatency(50*time.Millisecond, 2*time.Second))
+//	client := srv.Client()
+//
+// Faults can also be injected at random, to check that retries and circuit breakers
+// are configured correctly: see SetErrorRate, SetMalformedRate, SetDropRate and
+// RateLimitStorm.
+package itispaytest
+
+import (
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"math/rand"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	itispay "github.com/ItIsPay/go-client"
+)
+
+// APIKey is the API key accepted by the server and used by Server.Client
+const APIKey = "test-api-key"
+
+// Latency is a latency profile deciding how long each request is delayed
+type Latency interface {
+	Delay() time.Duration
+}
+
+// LatencyFunc adapts a function to the Latency interface
+type LatencyFunc func() time.Duration
+
+// Delay calls f
+func (f LatencyFunc) Delay() time.Duration {
+	return f()
+}
+
+// FixedLatency delays every request by d
+func FixedLatency(d time.Duration) Latency {
+	return LatencyFunc(func() time.Duration { return d })
+}
+
+// UniformLatency delays requests by a random duration between min and max
+func UniformLatency(min, max time.Duration) Latency {
+	return LatencyFunc(func() time.Duration {
+		if max <= min {
+			return min
+		}
+		return min + time.Duration(rand.Int63n(int64(max-min)))
+	})
+}
+
+// SpikyLatency delays requests by base, and by spike with the given probability (0-1),
+// modelling occasional slow responses
+func SpikyLatency(base, spike time.Duration, probability float64) Latency {
+	return LatencyFunc(func() time.Duration {
+		if rand.Float64() < probability {
+			return spike
+		}
+		return base
+	})
+}
+
+// endpointState is the configured behavior of an endpoint
+type endpointState struct {
+	downStatus int
+	latency    Latency
+
+	// Fault injection, see chaos.go
+	errorStatus      int
+	errorRate        float64
+	malformedRate    float64
+	dropRate         float64
+	rateLimitedUntil time.Time
+	retryAfter       time.Duration
+}
+
+// Server is a fake ItIsPay API backed by httptest.Server
+type Server struct {
+	// URL is the base URL of the fake API
+	URL string
+
+	srv *httptest.Server
+
+	mu         sync.Mutex
+	endpoints  map[string]*endpointState
+	invoices   map[string]*itispay.Invoice
+	customers  map[string]*itispay.Customer
+	order      []string
+	currencies []itispay.Currency
+	rates      map[string]float64
+	nextID     int
+
+	faults   atomic.Int64
+	requests atomic.Int64
+}
+
+// NewServer starts a fake API with BTC, ETH and USDT configured
+func NewServer() *Server {
+	s := &Server{
+		endpoints:  make(map[string]*endpointState),
+		invoices:   make(map[string]*itispay.Invoice),
+		customers:  make(map[string]*itispay.Customer),
+		currencies: NewTestCurrencies(),
+		rates:      NewTestRates().Rates,
+	}
+	s.srv = httptest.NewServer(http.HandlerFunc(s.serveHTTP))
+	s.URL = s.srv.URL
+	return s
+}
+
+// Close shuts down the server
+func (s *Server) Close() {
+	s.srv.Close()
+}
+
+// Client returns a sandbox client talking to the server. opts are applied after the
+// server settings.
+func (s *Server) Client(opts ...itispay.Option) *itispay.Client {
+	opts = append([]itispay.Option{
+		itispay.WithEnvironment(itispay.EnvSandbox),
+		itispay.WithBaseURL(s.URL),
+	}, opts...)
+	return itispay.NewClient(APIKey, opts...)
+}
+
+// SetDown makes an endpoint fail with statusCode (503 if zero). The endpoint is a path
+// prefix such as "/rates" or "/invoices", optionally preceded by a method, e.g.
+// "POST /invoices". The most specific configured endpoint applies.
+func (s *Server) SetDown(endpoint string, statusCode int) {
+	if statusCode == 0 {
+		statusCode = http.StatusServiceUnavailable
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.endpoint(endpoint).downStatus = statusCode
+}
+
+// SetHealthy restores an endpoint taken down with SetDown
+func (s *Server) SetHealthy(endpoint string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.endpoint(endpoint).downStatus = 0
+}
+
+// SetLatency delays responses of an endpoint (see SetDown) according to latency; nil
+// removes the delay
+func (s *Server) SetLatency(endpoint string, latency Latency) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.endpoint(endpoint).latency = latency
+}
+
+// Reset restores all endpoints to healthy with no latency or injected faults
+func (s *Server) Reset() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.endpoints = make(map[string]*endpointState)
+}
+
+// SetRate sets the exchange rate of a currency
+func (s *Server) SetRate(currency string, rate float64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.rates[currency] = rate
+}
+
+// AddInvoice stores invoice as if it had been created through the API
+func (s *Server) AddInvoice(invoice itispay.Invoice) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.storeInvoice(&invoice)
+}
+
+// Invoice returns a copy of a stored invoice
+func (s *Server) Invoice(invoiceID string) (itispay.Invoice, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	invoice, ok := s.invoices[invoiceID]
+	if !ok {
+		return itispay.Invoice{}, false
+	}
+	return *invoice, true
+}
+
+// endpoint returns the state of an endpoint, creating it; s.mu must be held
+func (s *Server) endpoint(endpoint string) *endpointState {
+	state, ok := s.endpoints[endpoint]
+	if !ok {
+		state = &endpointState{}
+		s.endpoints[endpoint] = state
+	}
+	return state
+}
+
+// lookupEndpoint returns the most specific endpoint state matching the request
+func (s *Server) lookupEndpoint(method, path string) endpointState {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var best endpointState
+	bestLen := -1
+	for key, state := range s.endpoints {
+		prefix, qualified := key, false
+		if m, p, ok := strings.Cut(key, " "); ok {
+			if m != method {
+				continue
+			}
+			prefix, qualified = p, true
+		}
+		if path != prefix && !strings.HasPrefix(path, strings.TrimSuffix(prefix, "/")+"/") {
+			continue
+		}
+		// Longer prefixes win; method-qualified keys win over plain ones of the same prefix
+		specificity := 2 * len(prefix)
+		if qualified {
+			specificity++
+		}
+		if specificity > bestLen {
+			best, bestLen = *state, specificity
+		}
+	}
+	return best
+}
+
+// serveHTTP applies the endpoint behavior and routes the request
+func (s *Server) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set(itispay.RequestIDHeader, fmt.Sprintf("req_test_%d", s.requests.Add(1)))
+	state := s.lookupEndpoint(r.Method, r.URL.Path)
+	if state.latency != nil {
+		timer := time.NewTimer(state.latency.Delay())
+		select {
+		case <-r.Context().Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+	}
+	if state.downStatus != 0 {
+		writeError(w, state.downStatus, itispay.ErrCodeServiceUnavailable, "endpoint is down")
+		return
+	}
+	if s.injectFault(w, state) {
+		return
+	}
+
+	// Webhook simulation and health checks do not require authentication
+	if r.URL.Path != "/webhooks/simulate" && r.URL.Path != "/health" && r.Header.Get("Api-key") != APIKey {
+		writeError(w, http.StatusUnauthorized, itispay.ErrCodeUnauthorized, "invalid API key")
+		return
+	}
+
+	// Accept bodies compressed with WithRequestCompression
+	if r.Header.Get("Content-Encoding") == "gzip" {
+		body, err := gzip.NewReader(r.Body)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, itispay.ErrCodeInvalidRequest, err.Error())
+			return
+		}
+		r.Body = body
+	}
+
+	// Dry runs validate without creating anything; only invoice creation supports them
+	dryRun := r.Header.Get(itispay.DryRunHeader) == "true" && r.Method != http.MethodGet
+	if dryRun && (r.URL.Path != "/invoices" || r.Method != http.MethodPost) {
+		writeError(w, http.StatusBadRequest, itispay.ErrCodeInvalidRequest, "dry run not supported for "+r.URL.Path)
+		return
+	}
+
+	switch {
+	case r.URL.Path == "/invoices" && r.Method == http.MethodPost:
+		s.createInvoice(w, r, dryRun)
+	case r.URL.Path == "/invoices" && r.Method == http.MethodGet:
+		s.listInvoices(w, r, "")
+	case strings.HasPrefix(r.URL.Path, "/invoices/") && strings.HasSuffix(r.URL.Path, "/extend") && r.Method == http.MethodPost:
+		s.extendInvoice(w, r, strings.TrimSuffix(strings.TrimPrefix(r.URL.Path, "/invoices/"), "/extend"))
+	case strings.HasPrefix(r.URL.Path, "/invoices/") && strings.HasSuffix(r.URL.Path, "/reissue") && r.Method == http.MethodPost:
+		s.reissueInvoice(w, strings.TrimSuffix(strings.TrimPrefix(r.URL.Path, "/invoices/"), "/reissue"))
+	case strings.HasPrefix(r.URL.Path, "/invoices/") && r.Method == http.MethodGet:
+		s.getInvoice(w, strings.TrimPrefix(r.URL.Path, "/invoices/"))
+	case strings.HasPrefix(r.URL.Path, "/invoices/") && r.Method == http.MethodPatch:
+		s.updateInvoice(w, r, strings.TrimPrefix(r.URL.Path, "/invoices/"))
+	case r.URL.Path == "/customers" && r.Method == http.MethodPost:
+		s.createCustomer(w, r)
+	case strings.HasPrefix(r.URL.Path, "/customers/") && strings.HasSuffix(r.URL.Path, "/invoices") && r.Method == http.MethodGet:
+		s.listInvoices(w, r, strings.TrimSuffix(strings.TrimPrefix(r.URL.Path, "/customers/"), "/invoices"))
+	case strings.HasPrefix(r.URL.Path, "/customers/") && r.Method == http.MethodGet:
+		s.getCustomer(w, strings.TrimPrefix(r.URL.Path, "/customers/"))
+	case r.URL.Path == "/currencies" && r.Method == http.MethodGet:
+		s.mu.Lock()
+		currencies := append([]itispay.Currency(nil), s.currencies...)
+		s.mu.Unlock()
+		writeCacheable(w, r, currencies)
+	case r.URL.Path == "/rates" && r.Method == http.MethodGet:
+		s.mu.Lock()
+		rates := make(map[string]float64, len(s.rates))
+		for currency, rate := range s.rates {
+			rates[currency] = rate
+		}
+		s.mu.Unlock()
+		writeCacheable(w, r, itispay.RatesResponse{Rates: rates})
+	case r.URL.Path == "/health" && r.Method == http.MethodGet:
+		writeJSON(w, http.StatusOK, itispay.PingResult{Status: "ok", Version: "itispaytest"})
+	case r.URL.Path == "/webhooks/simulate" && r.Method == http.MethodPost:
+		s.simulateWebhook(w, r)
+	case r.URL.Path == "/sandbox/reset" && r.Method == http.MethodPost:
+		s.mu.Lock()
+		s.invoices = make(map[string]*itispay.Invoice)
+		s.customers = make(map[string]*itispay.Customer)
+		s.order = nil
+		s.mu.Unlock()
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		writeError(w, http.StatusNotFound, itispay.ErrCodeNotFound, "endpoint not found")
+	}
+}
+
+func (s *Server) createInvoice(w http.ResponseWriter, r *http.Request, dryRun bool) {
+	var req itispay.CreateInvoiceRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, itispay.ErrCodeInvalidRequest, err.Error())
+		return
+	}
+	if len(req.Currencies) > 0 {
+		s.createMultiCurrencyInvoice(w, req, dryRun)
+		return
+	}
+	if req.OrderID == "" || req.Currency == "" {
+		writeError(w, http.StatusBadRequest, itispay.ErrCodeInvalidRequest, "order_id and currency are required")
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	rate, ok := s.rates[req.Currency]
+	if !ok {
+		writeError(w, http.StatusBadRequest, itispay.ErrCodeUnsupportedCurrency, "unsupported currency "+req.Currency)
+		return
+	}
+
+	now := time.Now().UTC()
+	id := s.nextID + 1
+	if !dryRun {
+		s.nextID = id
+	}
+	invoice := &itispay.Invoice{
+		InvoiceID:     fmt.Sprintf("invoice_test_%d", id),
+		OrderID:       req.OrderID,
+		CustomerID:    req.CustomerID,
+		AutoConvertTo: req.AutoConvertTo,
+		FiatCurrency:  req.FiatCurrency,
+		Currency:      req.Currency,
+		OrderName:     req.OrderName,
+		CallbackURL:   req.CallbackURL,
+		ExternalRefs:  req.ExternalRefs,
+		Metadata:      req.Metadata,
+		Status:        itispay.StatusNew,
+		TestMode:      true,
+		ExpireMin:     30,
+		CreatedAt:     now,
+		UpdatedAt:     now,
+		BlockchainDetails: &itispay.BlockchainDetails{
+			Currency:          req.Currency,
+			BlockchainAddress: fmt.Sprintf("test-address-%d", id),
+		},
+	}
+	if req.FiatAmount != nil {
+		invoice.FiatAmount = *req.FiatAmount
+		invoice.CryptoAmount = *req.FiatAmount / rate
+	}
+	if req.CryptoAmount != nil {
+		invoice.CryptoAmount = *req.CryptoAmount
+	}
+	if req.AllowedErrorPercent != nil {
+		invoice.AllowedErrorPercent = *req.AllowedErrorPercent
+	}
+	if req.ExpireMin != nil {
+		invoice.ExpireMin = *req.ExpireMin
+	}
+	if req.GracePeriodMin != nil {
+		invoice.GracePeriodMin = *req.GracePeriodMin
+	}
+	if req.RequiredConfirmations != nil {
+		invoice.RequiredConfirmations = *req.RequiredConfirmations
+	}
+	invoice.ExpiresAt = now.Add(time.Duration(invoice.ExpireMin) * time.Minute)
+	invoice.CheckoutURL = s.URL + "/checkout/" + invoice.InvoiceID
+
+	s.respondCreated(w, invoice, dryRun)
+}
+
+// createMultiCurrencyInvoice creates an invoice offering one payment option per currency
+func (s *Server) createMultiCurrencyInvoice(w http.ResponseWriter, req itispay.CreateInvoiceRequest, dryRun bool) {
+	if req.OrderID == "" || req.FiatAmount == nil {
+		writeError(w, http.StatusBadRequest, itispay.ErrCodeInvalidRequest, "order_id and fiat_amount are required")
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now().UTC()
+	id := s.nextID + 1
+	if !dryRun {
+		s.nextID = id
+	}
+	invoice := &itispay.Invoice{
+		InvoiceID:    fmt.Sprintf("invoice_test_%d", id),
+		OrderID:      req.OrderID,
+		CustomerID:   req.CustomerID,
+		FiatAmount:   *req.FiatAmount,
+		FiatCurrency: req.FiatCurrency,
+		OrderName:    req.OrderName,
+		CallbackURL:  req.CallbackURL,
+		ExternalRefs: req.ExternalRefs,
+		Metadata:     req.Metadata,
+		Status:       itispay.StatusNew,
+		TestMode:     true,
+		ExpireMin:    30,
+		CreatedAt:    now,
+		UpdatedAt:    now,
+	}
+	for _, currency := range req.Currencies {
+		rate, ok := s.rates[currency]
+		if !ok {
+			writeError(w, http.StatusBadRequest, itispay.ErrCodeUnsupportedCurrency, "unsupported currency "+currency)
+			return
+		}
+		invoice.PaymentOptions = append(invoice.PaymentOptions, itispay.PaymentOption{
+			Currency:     currency,
+			CryptoAmount: *req.FiatAmount / rate,
+			BlockchainDetails: &itispay.BlockchainDetails{
+				Currency:          currency,
+				BlockchainAddress: fmt.Sprintf("test-address-%d-%s", id, strings.ToLower(currency)),
+			},
+		})
+	}
+	if req.AllowedErrorPercent != nil {
+		invoice.AllowedErrorPercent = *req.AllowedErrorPercent
+	}
+	if req.ExpireMin != nil {
+		invoice.ExpireMin = *req.ExpireMin
+	}
+	if req.GracePeriodMin != nil {
+		invoice.GracePeriodMin = *req.GracePeriodMin
+	}
+	if req.RequiredConfirmations != nil {
+		invoice.RequiredConfirmations = *req.RequiredConfirmations
+	}
+	invoice.ExpiresAt = now.Add(time.Duration(invoice.ExpireMin) * time.Minute)
+	invoice.CheckoutURL = s.URL + "/checkout/" + invoice.InvoiceID
+
+	s.respondCreated(w, invoice, dryRun)
+}
+
+func (s *Server) getInvoice(w http.ResponseWriter, invoiceID string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	invoice, ok := s.invoices[invoiceID]
+	if !ok {
+		writeError(w, http.StatusNotFound, itispay.ErrCodeNotFound, "invoice not found")
+		return
+	}
+	writeJSON(w, http.StatusOK, invoice)
+}
+
+// listInvoices lists invoices, only those of customerID if it is not empty
+func (s *Server) listInvoices(w http.ResponseWriter, r *http.Request, customerID string) {
+	query := r.URL.Query()
+	page, _ := strconv.Atoi(query.Get("page"))
+	if page < 1 {
+		page = 1
+	}
+	pageSize, _ := strconv.Atoi(query.Get("page_size"))
+	if pageSize < 1 {
+		pageSize = 20
+	}
+
+	s.mu.Lock()
+	var items []itispay.Invoice
+	for _, id := range s.order {
+		invoice := s.invoices[id]
+		if customerID != "" && invoice.CustomerID != customerID {
+			continue
+		}
+		if status := query.Get("status"); status != "" && string(invoice.Status) != status {
+			continue
+		}
+		if currency := query.Get("currency"); currency != "" && invoice.Currency != currency {
+			continue
+		}
+		if after, err := time.Parse(time.RFC3339, query.Get("created_after")); err == nil && invoice.CreatedAt.Before(after) {
+			continue
+		}
+		if before, err := time.Parse(time.RFC3339, query.Get("created_before")); err == nil && !invoice.CreatedAt.Before(before) {
+			continue
+		}
+		if !matchesExternalRefs(invoice, query) {
+			continue
+		}
+		items = append(items, *invoice)
+	}
+	s.mu.Unlock()
+
+	sortKey := func(invoice *itispay.Invoice) time.Time { return invoice.CreatedAt }
+	if query.Get("sort_by") == itispay.SortByUpdatedAt {
+		sortKey = func(invoice *itispay.Invoice) time.Time { return invoice.UpdatedAt }
+	}
+	if query.Get("sort_order") == itispay.SortOrderDesc {
+		sort.SliceStable(items, func(i, j int) bool { return sortKey(&items[i]).After(sortKey(&items[j])) })
+	} else {
+		sort.SliceStable(items, func(i, j int) bool { return sortKey(&items[i]).Before(sortKey(&items[j])) })
+	}
+
+	total := len(items)
+	start := (page - 1) * pageSize
+	if start > total {
+		start = total
+	}
+	end := start + pageSize
+	if end > total {
+		end = total
+	}
+	totalPages := (total + pageSize - 1) / pageSize
+
+	writeJSON(w, http.StatusOK, itispay.ListInvoicesResponse{
+		Items: items[start:end],
+		Pagination: itispay.PaginationInfo{
+			CurrentPage:  page,
+			PageSize:     pageSize,
+			TotalPages:   totalPages,
+			TotalRecords: int64(total),
+			HasNext:      page < totalPages,
+			HasPrevious:  page > 1,
+		},
+	})
+}
+
+func (s *Server) createCustomer(w http.ResponseWriter, r *http.Request) {
+	var req itispay.CreateCustomerRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, itispay.ErrCodeInvalidRequest, err.Error())
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.nextID++
+	customer := &itispay.Customer{
+		ID:           fmt.Sprintf("customer_test_%d", s.nextID),
+		Email:        req.Email,
+		Name:         req.Name,
+		ExternalRefs: req.ExternalRefs,
+		Metadata:     req.Metadata,
+		CreatedAt:    time.Now().UTC(),
+	}
+	s.customers[customer.ID] = customer
+	writeJSON(w, http.StatusCreated, customer)
+}
+
+func (s *Server) getCustomer(w http.ResponseWriter, customerID string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	customer, ok := s.customers[customerID]
+	if !ok {
+		writeError(w, http.StatusNotFound, itispay.ErrCodeNotFound, "customer not found")
+		return
+	}
+	writeJSON(w, http.StatusOK, customer)
+}
+
+func (s *Server) updateInvoice(w http.ResponseWriter, r *http.Request, invoiceID string) {
+	var req itispay.UpdateInvoiceRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, itispay.ErrCodeInvalidRequest, err.Error())
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	invoice, ok := s.invoices[invoiceID]
+	if !ok {
+		writeError(w, http.StatusNotFound, itispay.ErrCodeNotFound, "invoice not found")
+		return
+	}
+	invoice.Status = itispay.Status(req.Status)
+	invoice.UpdatedAt = time.Now().UTC()
+	writeJSON(w, http.StatusOK, invoice)
+}
+
+func (s *Server) extendInvoice(w http.ResponseWriter, r *http.Request, invoiceID string) {
+	var req struct {
+		AdditionalMinutes int `json:"additional_minutes"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, itispay.ErrCodeInvalidRequest, err.Error())
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	invoice, ok := s.invoices[invoiceID]
+	if !ok {
+		writeError(w, http.StatusNotFound, itispay.ErrCodeNotFound, "invoice not found")
+		return
+	}
+	if invoice.Status != itispay.StatusNew && invoice.Status != itispay.StatusPending {
+		writeError(w, http.StatusConflict, itispay.ErrCodeInvalidStatus, "only open invoices can be extended")
+		return
+	}
+	invoice.ExpireMin += req.AdditionalMinutes
+	invoice.ExpiresAt = invoice.ExpiresAt.Add(time.Duration(req.AdditionalMinutes) * time.Minute)
+	invoice.UpdatedAt = time.Now().UTC()
+	writeJSON(w, http.StatusOK, invoice)
+}
+
+func (s *Server) reissueInvoice(w http.ResponseWriter, invoiceID string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	original, ok := s.invoices[invoiceID]
+	if !ok {
+		writeError(w, http.StatusNotFound, itispay.ErrCodeNotFound, "invoice not found")
+		return
+	}
+	if original.ReissuedAs != "" {
+		writeJSON(w, http.StatusOK, s.invoices[original.ReissuedAs])
+		return
+	}
+	if original.Status != itispay.StatusExpired && original.Status != itispay.StatusCancelled {
+		writeError(w, http.StatusConflict, itispay.ErrCodeInvalidStatus, "only expired or cancelled invoices can be reissued")
+		return
+	}
+
+	now := time.Now().UTC()
+	s.nextID++
+	id := s.nextID
+	invoice := &itispay.Invoice{
+		InvoiceID:             fmt.Sprintf("invoice_test_%d", id),
+		OrderID:               original.OrderID,
+		CustomerID:            original.CustomerID,
+		AutoConvertTo:         original.AutoConvertTo,
+		FiatAmount:            original.FiatAmount,
+		FiatCurrency:          original.FiatCurrency,
+		Currency:              original.Currency,
+		CryptoAmount:          original.CryptoAmount,
+		AllowedErrorPercent:   original.AllowedErrorPercent,
+		OrderName:             original.OrderName,
+		CallbackURL:           original.CallbackURL,
+		ExternalRefs:          original.ExternalRefs,
+		Metadata:              original.Metadata,
+		Status:                itispay.StatusNew,
+		TestMode:              true,
+		ExpireMin:             original.ExpireMin,
+		GracePeriodMin:        original.GracePeriodMin,
+		RequiredConfirmations: original.RequiredConfirmations,
+		CreatedAt:             now,
+		UpdatedAt:             now,
+		ExpiresAt:             now.Add(time.Duration(original.ExpireMin) * time.Minute),
+		ReissuedFrom:          original.InvoiceID,
+	}
+	if rate, ok := s.rates[invoice.Currency]; ok && invoice.FiatAmount > 0 {
+		invoice.CryptoAmount = invoice.FiatAmount / rate
+	}
+	if original.BlockchainDetails != nil {
+		invoice.BlockchainDetails = &itispay.BlockchainDetails{
+			Currency:          invoice.Currency,
+			BlockchainAddress: fmt.Sprintf("test-address-%d", id),
+		}
+	}
+	for _, option := range original.PaymentOptions {
+		if rate, ok := s.rates[option.Currency]; ok {
+			option.CryptoAmount = invoice.FiatAmount / rate
+		}
+		option.BlockchainDetails = &itispay.BlockchainDetails{
+			Currency:          option.Currency,
+			BlockchainAddress: fmt.Sprintf("test-address-%d-%s", id, strings.ToLower(option.Currency)),
+		}
+		invoice.PaymentOptions = append(invoice.PaymentOptions, option)
+	}
+	invoice.CheckoutURL = s.URL + "/checkout/" + invoice.InvoiceID
+
+	original.ReissuedAs = invoice.InvoiceID
+	original.UpdatedAt = now
+	s.storeInvoice(invoice)
+	writeJSON(w, http.StatusCreated, invoice)
+}
+
+func (s *Server) simulateWebhook(w http.ResponseWriter, r *http.Request) {
+	var req itispay.WebhookSimulateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, itispay.ErrCodeInvalidRequest, err.Error())
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	invoice, ok := s.invoices[req.InvoiceID]
+	if !ok {
+		writeError(w, http.StatusNotFound, itispay.ErrCodeNotFound, "invoice not found")
+		return
+	}
+	invoice.Status = itispay.Status(req.Status)
+	if invoice.Status == itispay.StatusCompleted && invoice.CurrentConfirmations < invoice.RequiredConfirmations {
+		invoice.CurrentConfirmations = invoice.RequiredConfirmations
+	}
+	invoice.UpdatedAt = time.Now().UTC()
+	if invoice.Status == itispay.StatusCompleted && invoice.AutoConvertTo != "" {
+		invoice.Conversion = s.convert(invoice)
+	}
+	writeJSON(w, http.StatusOK, itispay.WebhookSimulateResponse{Status: "ok", Message: "webhook simulated"})
+}
+
+// convert simulates the automatic conversion of a completed invoice's payment at the
+// current rates; currencies without a rate are treated as fiat; s.mu must be held
+func (s *Server) convert(invoice *itispay.Invoice) *itispay.Conversion {
+	amount := invoice.ActualCryptoAmountPaid
+	if amount == 0 {
+		amount = invoice.CryptoAmount
+	}
+	rate := s.rates[invoice.Currency]
+	if toRate, ok := s.rates[invoice.AutoConvertTo]; ok {
+		rate /= toRate
+	}
+	convertedAt := invoice.UpdatedAt
+	return &itispay.Conversion{
+		FromCurrency: invoice.Currency,
+		ToCurrency:   invoice.AutoConvertTo,
+		FromAmount:   amount,
+		ToAmount:     amount * rate,
+		Rate:         rate,
+		Status:       itispay.ConversionCompleted,
+		ConvertedAt:  &convertedAt,
+	}
+}
+
+// matchesExternalRefs reports whether invoice has all external_ref[key] values of query
+func matchesExternalRefs(invoice *itispay.Invoice, query url.Values) bool {
+	for param, values := range query {
+		if !strings.HasPrefix(param, "external_ref[") || !strings.HasSuffix(param, "]") {
+			continue
+		}
+		key := strings.TrimSuffix(strings.TrimPrefix(param, "external_ref["), "]")
+		if invoice.ExternalRefs[key] != values[0] {
+			return false
+		}
+	}
+	return true
+}
+
+// respondCreated stores a new invoice, or only echoes it for a dry run; s.mu must be held
+func (s *Server) respondCreated(w http.ResponseWriter, invoice *itispay.Invoice, dryRun bool) {
+	if dryRun {
+		w.Header().Set(itispay.DryRunHeader, "true")
+		writeJSON(w, http.StatusOK, invoice)
+		return
+	}
+	s.storeInvoice(invoice)
+	writeJSON(w, http.StatusCreated, invoice)
+}
+
+// storeInvoice saves an invoice; s.mu must be held
+func (s *Server) storeInvoice(invoice *itispay.Invoice) {
+	if _, exists := s.invoices[invoice.InvoiceID]; !exists {
+		s.order = append(s.order, invoice.InvoiceID)
+	}
+	s.invoices[invoice.InvoiceID] = invoice
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+// writeCacheable writes v with an ETag, answering 304 Not Modified if the client's
+// If-None-Match matches
+func writeCacheable(w http.ResponseWriter, r *http.Request, v interface{}) {
+	body, err := json.Marshal(v)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, itispay.ErrCodeInternal, err.Error())
+		return
+	}
+	etag := fmt.Sprintf(`"%x"`, sha256.Sum256(body))
+	w.Header().Set("ETag", etag)
+	if r.Header.Get("If-None-Match") == etag {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write(body)
+}
+
+func writeError(w http.ResponseWriter, status int, code itispay.ErrorCode, message string) {
+	writeJSON(w, status, itispay.ErrorResponse{Error: string(code), Message: message})
+}
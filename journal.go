@@ -0,0 +1,300 @@
+package itispay
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"os/signal"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultJournalSize is the number of exchanges WithJournal keeps per endpoint by default
+const DefaultJournalSize = 20
+
+// maxJournalBodySize limits the body bytes kept per journal entry
+const maxJournalBodySize = 64 << 10
+
+// JournalEntry is a recorded request and its response, with secrets redacted
+type JournalEntry struct {
+	Time     time.Time
+	Endpoint string
+	Method   string
+	URL      string
+	Duration time.Duration
+
+	RequestHeader http.Header
+	RequestBody   []byte
+	// StatusCode is 0 when no response was received; Err explains why
+	StatusCode     int
+	ResponseHeader http.Header
+	ResponseBody   []byte
+	Err            string
+}
+
+// journal keeps the most recent exchanges per endpoint in ring buffers
+type journal struct {
+	size int
+	// basePaths are the paths of the client's base URLs, e.g. "/api/v1", stripped from
+	// endpoint labels
+	basePaths []string
+
+	mu      sync.Mutex
+	entries map[string]*journalRing
+}
+
+// journalRing is a fixed-size ring buffer of entries
+type journalRing struct {
+	entries []JournalEntry
+	next    int
+}
+
+// WithJournal keeps the last size requests and responses per endpoint in memory, with
+// API keys and secrets redacted as in WithDebugTransport, so incident responders can see
+// exactly what the client sent with Client.DumpJournal. size is DefaultJournalSize if
+// zero or negative.
+func WithJournal(size int) Option {
+	return func(c *Client) {
+		if size <= 0 {
+			size = DefaultJournalSize
+		}
+		c.journal = &journal{size: size, entries: make(map[string]*journalRing)}
+	}
+}
+
+// intercept returns an Interceptor recording the exchanges passing through it. A
+// response is recorded once its body is closed, with at most maxJournalBodySize bytes
+// of it.
+func (j *journal) intercept(next RoundTripFunc) RoundTripFunc {
+	return func(req *http.Request) (*http.Response, error) {
+		entry := JournalEntry{
+			Time:          time.Now(),
+			Endpoint:      endpointLabel(req.Method, j.apiPath(req.URL.Path)),
+			Method:        req.Method,
+			URL:           redactURLQuery(req.URL.String()),
+			RequestHeader: redactHeaders(req.Header),
+		}
+		if req.GetBody != nil {
+			if body, err := req.GetBody(); err == nil {
+				raw, _ := io.ReadAll(body)
+				body.Close()
+				entry.RequestBody = journalBody(raw, false)
+			}
+		}
+
+		resp, err := next(req)
+		entry.Duration = time.Since(entry.Time)
+		if err != nil {
+			entry.Err = err.Error()
+			j.add(entry)
+			return resp, err
+		}
+
+		entry.StatusCode = resp.StatusCode
+		entry.ResponseHeader = redactHeaders(resp.Header)
+		if resp.Body == nil {
+			j.add(entry)
+			return resp, nil
+		}
+		recorder := &journalBodyRecorder{body: resp.Body, capture: cappedBuffer{limit: maxJournalBodySize}}
+		recorder.Reader = io.TeeReader(resp.Body, &recorder.capture)
+		recorder.finish = func() {
+			entry.ResponseBody = journalBody(recorder.capture.Bytes(), recorder.capture.truncated)
+			if recorder.err != nil {
+				entry.Err = recorder.err.Error()
+			}
+			j.add(entry)
+		}
+		resp.Body = recorder
+		return resp, nil
+	}
+}
+
+// apiPath returns path relative to the base URL it was sent to
+func (j *journal) apiPath(path string) string {
+	for _, base := range j.basePaths {
+		if base != "" && strings.HasPrefix(path, base+"/") {
+			return strings.TrimPrefix(path, base)
+		}
+	}
+	return path
+}
+
+// basePaths returns the paths of the client's base URLs
+func (c *Client) basePaths() []string {
+	baseURLs := []string{c.baseURL}
+	if c.failover != nil {
+		baseURLs = c.failover.baseURLs
+	}
+	var paths []string
+	for _, baseURL := range baseURLs {
+		if u, err := url.Parse(baseURL); err == nil {
+			paths = append(paths, strings.TrimSuffix(u.Path, "/"))
+		}
+	}
+	return paths
+}
+
+// journalBodyRecorder passes a response body through, keeping a copy of its beginning,
+// and records the exchange when closed
+type journalBodyRecorder struct {
+	io.Reader
+	body    io.ReadCloser
+	capture cappedBuffer
+	// err is the first read error other than io.EOF
+	err    error
+	once   sync.Once
+	finish func()
+}
+
+func (r *journalBodyRecorder) Read(p []byte) (int, error) {
+	n, err := r.Reader.Read(p)
+	if err != nil && err != io.EOF && r.err == nil {
+		r.err = err
+	}
+	return n, err
+}
+
+func (r *journalBodyRecorder) Close() error {
+	err := r.body.Close()
+	r.once.Do(r.finish)
+	return err
+}
+
+// cappedBuffer keeps the first limit bytes written to it and discards the rest
+type cappedBuffer struct {
+	bytes.Buffer
+	limit     int
+	truncated bool
+}
+
+func (b *cappedBuffer) Write(p []byte) (int, error) {
+	if room := b.limit - b.Len(); len(p) > room {
+		b.Buffer.Write(p[:room])
+		b.truncated = true
+		return len(p), nil
+	}
+	return b.Buffer.Write(p)
+}
+
+// add records an entry, evicting the oldest one of its endpoint when full
+func (j *journal) add(entry JournalEntry) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	ring, ok := j.entries[entry.Endpoint]
+	if !ok {
+		ring = &journalRing{entries: make([]JournalEntry, 0, j.size)}
+		j.entries[entry.Endpoint] = ring
+	}
+	if len(ring.entries) < j.size {
+		ring.entries = append(ring.entries, entry)
+		return
+	}
+	ring.entries[ring.next] = entry
+	ring.next = (ring.next + 1) % j.size
+}
+
+// snapshot returns all entries, oldest first
+func (j *journal) snapshot() []JournalEntry {
+	j.mu.Lock()
+	var entries []JournalEntry
+	for _, ring := range j.entries {
+		entries = append(entries, ring.entries...)
+	}
+	j.mu.Unlock()
+
+	sort.SliceStable(entries, func(a, b int) bool { return entries[a].Time.Before(entries[b].Time) })
+	return entries
+}
+
+// journalBody redacts a body and truncates it to maxJournalBodySize. truncated tells
+// that raw is only the beginning of the body: a JSON prefix cannot be redacted, so it is
+// left out.
+func journalBody(raw []byte, truncated bool) []byte {
+	if truncated {
+		if trimmed := bytes.TrimLeft(raw, " \t\r\n"); len(trimmed) > 0 && (trimmed[0] == '{' || trimmed[0] == '[') {
+			return []byte("(JSON body too large to redact)")
+		}
+		return append(raw[:len(raw):len(raw)], "...(truncated)"...)
+	}
+	body := redactBody(raw)
+	if len(body) > maxJournalBodySize {
+		body = append(body[:maxJournalBodySize:maxJournalBodySize], "...(truncated)"...)
+	}
+	return body
+}
+
+// Journal returns the recorded exchanges, oldest first. It returns nil unless the client
+// was created with WithJournal.
+func (c *Client) Journal() []JournalEntry {
+	if c.journal == nil {
+		return nil
+	}
+	return c.journal.snapshot()
+}
+
+// DumpJournal writes the recorded exchanges to w, oldest first
+func (c *Client) DumpJournal(w io.Writer) error {
+	bw := bufio.NewWriter(w)
+	for _, entry := range c.Journal() {
+		fmt.Fprintf(bw, "=== %s %s (%s)\n", entry.Time.UTC().Format(time.RFC3339Nano), entry.Endpoint, entry.Duration)
+		fmt.Fprintf(bw, "--> %s %s\n", entry.Method, entry.URL)
+		entry.RequestHeader.Write(bw)
+		writeJournalBody(bw, entry.RequestBody)
+		if entry.StatusCode != 0 {
+			fmt.Fprintf(bw, "<-- %d %s\n", entry.StatusCode, http.StatusText(entry.StatusCode))
+			entry.ResponseHeader.Write(bw)
+			writeJournalBody(bw, entry.ResponseBody)
+		}
+		if entry.Err != "" {
+			fmt.Fprintf(bw, "<-- error: %s\n", entry.Err)
+		}
+		bw.WriteString("\n")
+	}
+	return bw.Flush()
+}
+
+// writeJournalBody writes a body on its own lines
+func writeJournalBody(w *bufio.Writer, body []byte) {
+	if len(body) == 0 {
+		return
+	}
+	w.WriteString("\n")
+	w.Write(body)
+	w.WriteString("\n")
+}
+
+// DumpJournalOnSignal writes the journal to w every time one of sigs is received, e.g.
+// syscall.SIGUSR1, until stop is called:
+//
+//	stop := client.DumpJournalOnSignal(os.Stderr, syscall.SIGUSR1)
+//	defer stop()
+func (c *Client) DumpJournalOnSignal(w io.Writer, sigs ...os.Signal) (stop func()) {
+	ch := make(chan os.Signal, 1)
+	signal.Notify(ch, sigs...)
+	done := make(chan struct{})
+	go func() {
+		for {
+			select {
+			case <-ch:
+				_ = c.DumpJournal(w)
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			signal.Stop(ch)
+			close(done)
+		})
+	}
+}
@@ -0,0 +1,97 @@
+package itispay_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	itispay "github.com/ItIsPay/go-client"
+)
+
+// transportFunc adapts a function to http.RoundTripper
+type transportFunc func(*http.Request) (*http.Response, error)
+
+func (f transportFunc) RoundTrip(req *http.Request) (*http.Response, error) { return f(req) }
+
+// failingBody returns data and then err
+type failingBody struct {
+	io.Reader
+	err error
+}
+
+func (b *failingBody) Read(p []byte) (int, error) {
+	n, err := b.Reader.Read(p)
+	if err == io.EOF {
+		return n, b.err
+	}
+	return n, err
+}
+
+func (b *failingBody) Close() error { return nil }
+
+func TestJournal(t *testing.T) {
+	errReset := errors.New("connection reset")
+	large := strings.Repeat("x", 100<<10)
+	tests := []struct {
+		name     string
+		body     io.ReadCloser
+		wantErr  bool
+		wantBody func(string) bool
+	}{
+		{
+			name:     "complete body",
+			body:     io.NopCloser(strings.NewReader(`{"rates":{}}`)),
+			wantBody: func(body string) bool { return body == `{"rates":{}}` },
+		},
+		{
+			name: "numbers are kept as written",
+			body: io.NopCloser(strings.NewReader(`{"rates":{},"amount_in_units":9007199254740993,"rate":0.10}`)),
+			wantBody: func(body string) bool {
+				return strings.Contains(body, "9007199254740993") && strings.Contains(body, "0.10")
+			},
+		},
+		{
+			name:     "read error",
+			body:     &failingBody{Reader: strings.NewReader(`{"rates":`), err: errReset},
+			wantErr:  true,
+			wantBody: func(body string) bool { return body == `{"rates":` },
+		},
+		{
+			name:     "large body is capped",
+			body:     io.NopCloser(strings.NewReader(large)),
+			wantBody: func(body string) bool { return len(body) < len(large) && strings.HasSuffix(body, "(truncated)") },
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			transport := transportFunc(func(req *http.Request) (*http.Response, error) {
+				return &http.Response{StatusCode: http.StatusOK, Header: http.Header{}, Body: tt.body, Request: req}, nil
+			})
+			client := itispay.NewClient("key", itispay.WithTransport(transport), itispay.WithJournal(0))
+
+			_, err := client.GetRates(context.Background())
+			if tt.wantErr && !errors.Is(err, errReset) {
+				t.Errorf("err = %v, want the read error", err)
+			}
+
+			entries := client.Journal()
+			if len(entries) != 1 {
+				t.Fatalf("%d journal entries, want 1", len(entries))
+			}
+			entry := entries[0]
+			if entry.Endpoint != "GET /rates" {
+				t.Errorf("endpoint = %q, want it without the base path", entry.Endpoint)
+			}
+			if (entry.Err != "") != tt.wantErr {
+				t.Errorf("entry error = %q", entry.Err)
+			}
+			if !tt.wantBody(string(entry.ResponseBody)) {
+				t.Errorf("unexpected response body of %d bytes: %.40q", len(entry.ResponseBody), entry.ResponseBody)
+			}
+		})
+	}
+}
@@ -0,0 +1,23 @@
+package itispay
+
+import "strings"
+
+// AcceptLanguageHeader selects the language of localized API error messages
+const AcceptLanguageHeader = "Accept-Language"
+
+// WithLanguage requests localized error messages in the given languages, most preferred
+// first, e.g. WithLanguage("de-DE", "en"). The localized text is returned in
+// APIError.LocalizedMessage; Message stays in English for logs.
+func WithLanguage(languages ...string) Option {
+	return func(c *Client) {
+		c.acceptLanguage = strings.Join(languages, ", ")
+	}
+}
+
+// WithRequestLanguage overrides the languages set with WithLanguage for this call, e.g.
+// with the language of the admin user the error is displayed to
+func WithRequestLanguage(languages ...string) RequestOption {
+	return func(o *requestOptions) {
+		o.headers.Set(AcceptLanguageHeader, strings.Join(languages, ", "))
+	}
+}
@@ -0,0 +1,84 @@
+package itispay
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync/atomic"
+)
+
+// ErrConcurrencyLimit is returned when the request could not get a slot because the
+// limit set with WithMaxConcurrentRequests was reached and the wait queue is full
+var ErrConcurrencyLimit = errors.New("itispay: too many concurrent requests")
+
+// WithMaxConcurrentRequests bounds the number of requests in flight to n across the
+// client, protecting both your service and your API quota during traffic spikes.
+// Requests beyond the limit wait for a slot until their context is done; see
+// WithMaxQueuedRequests to bound the wait queue.
+func WithMaxConcurrentRequests(n int) Option {
+	return func(c *Client) {
+		c.limiterConfig.maxConcurrent = n
+	}
+}
+
+// WithMaxQueuedRequests bounds the number of requests waiting for a slot when the
+// WithMaxConcurrentRequests limit is reached; requests beyond it fail immediately with
+// ErrConcurrencyLimit. Zero disables queueing. It has no effect without
+// WithMaxConcurrentRequests.
+func WithMaxQueuedRequests(n int) Option {
+	return func(c *Client) {
+		c.limiterConfig.maxQueued = n
+		c.limiterConfig.queueLimited = true
+	}
+}
+
+// limiterConfig holds the settings of WithMaxConcurrentRequests and WithMaxQueuedRequests
+type limiterConfig struct {
+	maxConcurrent int
+	maxQueued     int
+	queueLimited  bool
+}
+
+// newConcurrencyLimiter returns the limiter for config, nil if there is no limit
+func newConcurrencyLimiter(config limiterConfig) *concurrencyLimiter {
+	if config.maxConcurrent <= 0 {
+		return nil
+	}
+	l := &concurrencyLimiter{slots: make(chan struct{}, config.maxConcurrent), maxQueued: -1}
+	if config.queueLimited {
+		l.maxQueued = int64(max(config.maxQueued, 0))
+	}
+	return l
+}
+
+// concurrencyLimiter is a semaphore with a bounded wait queue
+type concurrencyLimiter struct {
+	slots chan struct{}
+	// maxQueued is the wait queue size, unbounded if negative
+	maxQueued int64
+	queued    atomic.Int64
+}
+
+// acquire waits for a slot. The returned release function must be called once the
+// request is done.
+func (l *concurrencyLimiter) acquire(ctx context.Context) (release func(), err error) {
+	release = func() { <-l.slots }
+	select {
+	case l.slots <- struct{}{}:
+		return release, nil
+	default:
+	}
+
+	if queued := l.queued.Add(1); l.maxQueued >= 0 && queued > l.maxQueued {
+		l.queued.Add(-1)
+		return nil, fmt.Errorf("%w: %d in flight", ErrConcurrencyLimit, cap(l.slots))
+	}
+	defer l.queued.Add(-1)
+
+	select {
+	case l.slots <- struct{}{}:
+		return release, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
@@ -0,0 +1,113 @@
+package itispay
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+// waitQueued waits until n requests are queued on l
+func waitQueued(t *testing.T, l *concurrencyLimiter, n int64) {
+	t.Helper()
+	deadline := time.Now().Add(time.Second)
+	for l.queued.Load() != n {
+		if time.Now().After(deadline) {
+			t.Fatalf("%d requests queued, want %d", l.queued.Load(), n)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestConcurrencyLimiterQueueBound(t *testing.T) {
+	tests := []struct {
+		name   string
+		config limiterConfig
+		// waiting is the number of requests that can wait once the slots are taken
+		waiting int
+	}{
+		{name: "no queueing", config: limiterConfig{maxConcurrent: 2, queueLimited: true}, waiting: 0},
+		{name: "bounded queue", config: limiterConfig{maxConcurrent: 2, maxQueued: 3, queueLimited: true}, waiting: 3},
+		{name: "negative queue means no queueing", config: limiterConfig{maxConcurrent: 1, maxQueued: -1, queueLimited: true}, waiting: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l := newConcurrencyLimiter(tt.config)
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			var releases []func()
+			for i := 0; i < tt.config.maxConcurrent; i++ {
+				release, err := l.acquire(ctx)
+				if err != nil {
+					t.Fatalf("acquiring free slot %d: %v", i, err)
+				}
+				releases = append(releases, release)
+			}
+
+			acquired := make(chan func(), tt.waiting)
+			for i := 0; i < tt.waiting; i++ {
+				go func() {
+					release, err := l.acquire(ctx)
+					if err == nil {
+						acquired <- release
+					}
+				}()
+			}
+			waitQueued(t, l, int64(tt.waiting))
+
+			if _, err := l.acquire(ctx); !errors.Is(err, ErrConcurrencyLimit) {
+				t.Fatalf("request beyond the queue: err = %v, want ErrConcurrencyLimit", err)
+			}
+
+			// Freed slots go to the queued requests
+			for _, release := range releases {
+				release()
+			}
+			for i := 0; i < tt.waiting; i++ {
+				select {
+				case release := <-acquired:
+					release()
+				case <-time.After(time.Second):
+					t.Fatalf("queued request %d did not get a slot", i)
+				}
+			}
+			waitQueued(t, l, 0)
+		})
+	}
+}
+
+func TestConcurrencyLimiterUnboundedQueue(t *testing.T) {
+	l := newConcurrencyLimiter(limiterConfig{maxConcurrent: 1})
+	release, err := l.acquire(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer release()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	errs := make(chan error, 20)
+	for i := 0; i < 20; i++ {
+		go func() {
+			_, err := l.acquire(ctx)
+			errs <- err
+		}()
+	}
+	waitQueued(t, l, 20)
+
+	// Waiting requests give up with their context
+	cancel()
+	for i := 0; i < 20; i++ {
+		if err := <-errs; !errors.Is(err, context.Canceled) {
+			t.Errorf("err = %v, want context.Canceled", err)
+		}
+	}
+	waitQueued(t, l, 0)
+}
+
+func TestConcurrencyLimiterDisabled(t *testing.T) {
+	if l := newConcurrencyLimiter(limiterConfig{maxQueued: 5, queueLimited: true}); l != nil {
+		t.Error("limiter without WithMaxConcurrentRequests should be nil")
+	}
+}
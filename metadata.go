@@ -0,0 +1,37 @@
+package itispay
+
+import (
+	"errors"
+	"fmt"
+	"unicode/utf8"
+)
+
+// Invoice metadata limits
+const (
+	MaxMetadataKeys        = 20
+	MaxMetadataKeyLength   = 40
+	MaxMetadataValueLength = 500
+)
+
+// ErrInvalidMetadata is returned when invoice metadata exceeds the limits
+var ErrInvalidMetadata = errors.New("itispay: invalid metadata")
+
+// ValidateMetadata checks metadata against the limits enforced by the API, so invalid
+// requests fail before they are sent
+func ValidateMetadata(metadata map[string]string) error {
+	if len(metadata) > MaxMetadataKeys {
+		return fmt.Errorf("%w: %d keys, at most %d allowed", ErrInvalidMetadata, len(metadata), MaxMetadataKeys)
+	}
+	for key, value := range metadata {
+		if key == "" {
+			return fmt.Errorf("%w: empty key", ErrInvalidMetadata)
+		}
+		if n := utf8.RuneCountInString(key); n > MaxMetadataKeyLength {
+			return fmt.Errorf("%w: key %q has %d characters, at most %d allowed", ErrInvalidMetadata, key, n, MaxMetadataKeyLength)
+		}
+		if n := utf8.RuneCountInString(value); n > MaxMetadataValueLength {
+			return fmt.Errorf("%w: value of %q has %d characters, at most %d allowed", ErrInvalidMetadata, key, n, MaxMetadataValueLength)
+		}
+	}
+	return nil
+}
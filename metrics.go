@@ -0,0 +1,57 @@
+package itispay
+
+import (
+	"strings"
+	"time"
+)
+
+// Metrics receives observations about API calls made by the client. Implementations must
+// be safe for concurrent use. See the prometheus module for a ready-made implementation.
+type Metrics interface {
+	// ObserveRequest records a completed HTTP call. statusCode is 0 when no response was received.
+	ObserveRequest(endpoint string, statusCode int, duration time.Duration)
+	// IncRetry counts a request that is being retried
+	IncRetry(endpoint string)
+	// IncRateLimited counts a request rejected by the API rate limiter (HTTP 429)
+	IncRateLimited(endpoint string)
+}
+
+// noopMetrics discards all observations
+type noopMetrics struct{}
+
+func (noopMetrics) ObserveRequest(string, int, time.Duration) {}
+func (noopMetrics) IncRetry(string)                           {}
+func (noopMetrics) IncRateLimited(string)                     {}
+
+// WithMetrics configures the client to report request metrics
+func WithMetrics(metrics Metrics) Option {
+	return func(c *Client) {
+		c.metrics = metrics
+	}
+}
+
+// endpointLabel returns a low-cardinality label for a request, e.g. "GET /invoices/{id}".
+// Query strings are dropped and path segments that look like identifiers (anything
+// other than lowercase letters and dashes) are replaced with "{id}".
+func endpointLabel(method, path string) string {
+	if i := strings.IndexByte(path, '?'); i >= 0 {
+		path = path[:i]
+	}
+	segments := strings.Split(path, "/")
+	for i, segment := range segments {
+		if segment != "" && !isStaticSegment(segment) {
+			segments[i] = "{id}"
+		}
+	}
+	return method + " " + strings.Join(segments, "/")
+}
+
+// isStaticSegment reports whether a path segment is a fixed resource name
+func isStaticSegment(segment string) bool {
+	for _, r := range segment {
+		if (r < 'a' || r > 'z') && r != '-' {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,74 @@
+package itispay
+
+import (
+	"errors"
+	"strings"
+)
+
+// Errors returned for invalid multi-currency invoice requests
+var (
+	// ErrCurrencyConflict is returned when both Currency and Currencies are set
+	ErrCurrencyConflict = errors.New("itispay: set either Currency or Currencies, not both")
+	// ErrMultiCurrencyCryptoAmount is returned when a multi-currency invoice has a crypto
+	// amount, which only makes sense in a single currency
+	ErrMultiCurrencyCryptoAmount = errors.New("itispay: multi-currency invoices require a fiat amount")
+)
+
+// PaymentOption is one of the currencies a multi-currency invoice can be paid in
+type PaymentOption struct {
+	Currency            string             `json:"currency"`
+	CryptoAmount        float64            `json:"crypto_amount"`
+	CryptoAmountInUnits *int64             `json:"crypto_amount_in_units,omitempty"`
+	BlockchainDetails   *BlockchainDetails `json:"blockchain_details,omitempty"`
+}
+
+// PaymentOption returns the option of a multi-currency invoice for currency
+func (i *Invoice) PaymentOption(currency string) (*PaymentOption, bool) {
+	for k := range i.PaymentOptions {
+		if strings.EqualFold(i.PaymentOptions[k].Currency, currency) {
+			return &i.PaymentOptions[k], true
+		}
+	}
+	return nil, false
+}
+
+// ForCurrency returns a copy of a multi-currency invoice narrowed to one payment option,
+// with Currency, CryptoAmount and BlockchainDetails taken from it, so that PaymentURI
+// and QR codes can be rendered for the buyer's choice
+func (i *Invoice) ForCurrency(currency string) (*Invoice, bool) {
+	option, ok := i.PaymentOption(currency)
+	if !ok {
+		return nil, false
+	}
+	narrowed := *i
+	narrowed.Currency = option.Currency
+	narrowed.CryptoAmount = option.CryptoAmount
+	narrowed.CryptoAmountInUnits = option.CryptoAmountInUnits
+	narrowed.BlockchainDetails = option.BlockchainDetails
+	narrowed.PaymentOptions = nil
+	return &narrowed, true
+}
+
+// validate checks a create request before it is sent
+func (r *CreateInvoiceRequest) validate() error {
+	if len(r.Currencies) > 0 {
+		if r.Currency != "" {
+			return ErrCurrencyConflict
+		}
+		if r.CryptoAmount != nil {
+			return ErrMultiCurrencyCryptoAmount
+		}
+	}
+	if err := validateAutoConvert(r.Currency, r.AutoConvertTo); err != nil {
+		return err
+	}
+	if err := validateConfirmations(r.RequiredConfirmations); err != nil {
+		return err
+	}
+	if r.TravelRule != nil {
+		if err := r.TravelRule.Validate(); err != nil {
+			return err
+		}
+	}
+	return ValidateMetadata(r.Metadata)
+}
@@ -0,0 +1,66 @@
+package itispay
+
+import (
+	"io"
+	"net/http"
+	"time"
+)
+
+// IdempotencyKeyHeader is the header carrying the idempotency key of a request
+const IdempotencyKeyHeader = "Idempotency-Key"
+
+// Option configures a Client
+type Option func(*Client)
+
+// RequestOption configures a single API call
+type RequestOption func(*requestOptions)
+
+// requestOptions holds the per-call settings collected from RequestOptions
+type requestOptions struct {
+	timeout  time.Duration
+	headers  http.Header
+	readBack *readBackPolicy
+	// apiKey, if set, replaces the client's API key
+	apiKey string
+	dryRun bool
+
+	concurrency int
+	hedgeDelay  time.Duration
+	// cacheRefresh skips the response cache lookup
+	cacheRefresh bool
+	// stream, if set, consumes successful response bodies instead of buffering them
+	stream func(body io.Reader) error
+	// meta, if set, receives the response metadata
+	meta *ResponseMeta
+}
+
+// newRequestOptions applies opts on top of the defaults
+func newRequestOptions(opts []RequestOption) *requestOptions {
+	o := &requestOptions{headers: http.Header{}}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return o
+}
+
+// WithRequestTimeout sets the timeout for this call only, replacing the client-wide
+// HTTP timeout (it may be longer or shorter)
+func WithRequestTimeout(timeout time.Duration) RequestOption {
+	return func(o *requestOptions) {
+		o.timeout = timeout
+	}
+}
+
+// WithHeader adds an extra HTTP header to this call
+func WithHeader(key, value string) RequestOption {
+	return func(o *requestOptions) {
+		o.headers.Add(key, value)
+	}
+}
+
+// WithIdempotencyKey sets the Idempotency-Key header so that retried mutations are applied once
+func WithIdempotencyKey(key string) RequestOption {
+	return func(o *requestOptions) {
+		o.headers.Set(IdempotencyKeyHeader, key)
+	}
+}
@@ -0,0 +1,84 @@
+package itispay
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+)
+
+// Errors returned by OverpaymentRefund
+var (
+	// ErrNoOverpayment is returned for invoices not paid beyond their tolerance
+	ErrNoOverpayment = errors.New("itispay: invoice has no overpayment")
+	// ErrRefundAddressUnverified is returned for refund addresses that are not verified
+	ErrRefundAddressUnverified = errors.New("itispay: refund address is not verified")
+	// ErrRefundCurrencyMismatch is returned for refund addresses in another currency
+	ErrRefundCurrencyMismatch = errors.New("itispay: refund address currency does not match the invoice")
+)
+
+// Overpayment is the amount paid beyond an invoice's amount
+type Overpayment struct {
+	Currency     string
+	CryptoAmount float64
+	// CryptoAmountInUnits is set when the invoice reports amounts in base units
+	CryptoAmountInUnits *int64
+	FiatCurrency        string
+	// FiatAmount values CryptoAmount at the rate the invoice was quoted at, rounded to
+	// two decimals
+	FiatAmount float64
+}
+
+// Overpayment returns the amount paid beyond the invoice amount, with ok false unless
+// the payment exceeds the amount by more than AllowedErrorPercent. Payments within the
+// tolerance are not worth refunding; beyond it, the whole excess is returned.
+func (i *Invoice) Overpayment() (overpayment Overpayment, ok bool) {
+	overpayment = Overpayment{Currency: i.Currency, FiatCurrency: i.FiatCurrency}
+	tolerance := 1 + float64(i.AllowedErrorPercent)/100
+
+	if i.CryptoAmountInUnits != nil {
+		expected := *i.CryptoAmountInUnits
+		if float64(i.ActualCryptoAmountPaidInUnits) <= float64(expected)*tolerance {
+			return overpayment, false
+		}
+		units := i.ActualCryptoAmountPaidInUnits - expected
+		overpayment.CryptoAmountInUnits = &units
+		overpayment.CryptoAmount = i.CryptoAmount * float64(units) / float64(expected)
+	} else {
+		if i.ActualCryptoAmountPaid <= i.CryptoAmount*tolerance {
+			return overpayment, false
+		}
+		overpayment.CryptoAmount = i.ActualCryptoAmountPaid - i.CryptoAmount
+	}
+
+	if i.CryptoAmount > 0 {
+		rate := i.FiatAmount / i.CryptoAmount
+		overpayment.FiatAmount = math.Round(overpayment.CryptoAmount*rate*100) / 100
+	}
+	return overpayment, true
+}
+
+// OverpaymentRefund drafts a payout returning the overpayment of an invoice to a
+// verified refund address of the customer. Pass the result to CreatePayoutDraft; the
+// payout still goes through the account's approval policy.
+func (i *Invoice) OverpaymentRefund(to RefundAddress) (*CreatePayoutDraftRequest, error) {
+	overpayment, ok := i.Overpayment()
+	if !ok {
+		return nil, ErrNoOverpayment
+	}
+	if to.Status != RefundAddressStatusVerified {
+		return nil, fmt.Errorf("%w: %s is %s", ErrRefundAddressUnverified, to.ID, to.Status)
+	}
+	if !strings.EqualFold(to.Currency, i.Currency) {
+		return nil, fmt.Errorf("%w: %s address for a %s invoice", ErrRefundCurrencyMismatch, to.Currency, i.Currency)
+	}
+
+	return &CreatePayoutDraftRequest{
+		Currency:  i.Currency,
+		Network:   to.Network,
+		Amount:    overpayment.CryptoAmount,
+		Address:   to.Address,
+		Reference: "overpayment-" + i.InvoiceID,
+		Note:      fmt.Sprintf("Refund of overpayment on invoice %s (order %s)", i.InvoiceID, i.OrderID),
+	}, nil
+}
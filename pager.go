@@ -0,0 +1,69 @@
+package itispay
+
+import "context"
+
+// InvoicePager iterates over all invoices matching a filter, fetching pages on demand:
+//
+//	pager := client.NewInvoicePager(params)
+//	for pager.Next(ctx) {
+//		invoice := pager.Invoice()
+//		...
+//	}
+//	if err := pager.Err(); err != nil {
+//		...
+//	}
+type InvoicePager struct {
+	client *Client
+	params ListInvoicesParams
+	opts   []RequestOption
+
+	items   []Invoice
+	index   int
+	current *Invoice
+	done    bool
+	err     error
+}
+
+// NewInvoicePager returns a pager over the invoices matching params, starting at
+// params.Page (or the first page)
+func (c *Client) NewInvoicePager(params ListInvoicesParams, opts ...RequestOption) *InvoicePager {
+	if params.Page < 1 {
+		params.Page = 1
+	}
+	return &InvoicePager{client: c, params: params, opts: opts}
+}
+
+// Next advances to the next invoice, fetching the next page when needed. It returns
+// false when all invoices have been read or an error occurred (see Err).
+func (p *InvoicePager) Next(ctx context.Context) bool {
+	for p.index >= len(p.items) {
+		if p.done || p.err != nil {
+			p.current = nil
+			return false
+		}
+
+		response, err := p.client.ListInvoices(ctx, p.params, p.opts...)
+		if err != nil {
+			p.err = err
+			p.current = nil
+			return false
+		}
+		p.items, p.index = response.Items, 0
+		p.done = !response.Pagination.HasNext || len(response.Items) == 0
+		p.params.Page++
+	}
+
+	p.current = &p.items[p.index]
+	p.index++
+	return true
+}
+
+// Invoice returns the current invoice
+func (p *InvoicePager) Invoice() *Invoice {
+	return p.current
+}
+
+// Err returns the error that stopped the iteration, if any
+func (p *InvoicePager) Err() error {
+	return p.err
+}
@@ -0,0 +1,175 @@
+package itispay
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// ErrInvalidIssueResolution is returned for payment issue resolutions without an action,
+// or refunds without an address
+var ErrInvalidIssueResolution = errors.New("itispay: invalid payment issue resolution")
+
+// PaymentIssueType is the kind of problem flagged on a payment
+type PaymentIssueType string
+
+// Payment issue type constants
+const (
+	// PaymentIssueWrongAmount means the payment did not match the invoice amount
+	PaymentIssueWrongAmount PaymentIssueType = "wrong_amount"
+	// PaymentIssueWrongNetwork means funds were sent over a network the invoice did not
+	// accept, e.g. USDT on TRON for an ERC-20 invoice
+	PaymentIssueWrongNetwork PaymentIssueType = "wrong_network"
+	// PaymentIssueWrongCurrency means a different coin was sent to the invoice address
+	PaymentIssueWrongCurrency PaymentIssueType = "wrong_currency"
+	// PaymentIssueStuckTransaction means the transaction has not confirmed in time, e.g.
+	// because of a low fee
+	PaymentIssueStuckTransaction PaymentIssueType = "stuck_transaction"
+	// PaymentIssueLatePayment means the payment arrived after the invoice expired
+	PaymentIssueLatePayment PaymentIssueType = "late_payment"
+)
+
+// PaymentIssueStatus is the triage status of a payment issue
+type PaymentIssueStatus string
+
+// Payment issue status constants
+const (
+	PaymentIssueOpen        PaymentIssueStatus = "open"
+	PaymentIssueUnderReview PaymentIssueStatus = "under_review"
+	PaymentIssueResolved    PaymentIssueStatus = "resolved"
+	// PaymentIssueDismissed means the issue was closed without action
+	PaymentIssueDismissed PaymentIssueStatus = "dismissed"
+)
+
+// IsFinal reports whether the issue is closed
+func (s PaymentIssueStatus) IsFinal() bool {
+	return s == PaymentIssueResolved || s == PaymentIssueDismissed
+}
+
+// PaymentIssueAction is a way to resolve a payment issue
+type PaymentIssueAction string
+
+// Payment issue resolution actions
+const (
+	// IssueActionAccept credits the received amount to the invoice as is
+	IssueActionAccept PaymentIssueAction = "accept"
+	// IssueActionRefund returns the funds to RefundAddress
+	IssueActionRefund PaymentIssueAction = "refund"
+	// IssueActionReissue creates a new invoice for the outstanding amount
+	IssueActionReissue PaymentIssueAction = "reissue"
+	// IssueActionRecover asks support to recover funds sent over the wrong network
+	IssueActionRecover PaymentIssueAction = "recover"
+	// IssueActionDismiss closes the issue without action
+	IssueActionDismiss PaymentIssueAction = "dismiss"
+)
+
+// PaymentIssueResolution records how a payment issue was resolved
+type PaymentIssueResolution struct {
+	Action        PaymentIssueAction `json:"action"`
+	Note          string             `json:"note,omitempty"`
+	RefundAddress string             `json:"refund_address,omitempty"`
+	RefundTxHash  string             `json:"refund_tx_hash,omitempty"`
+	// ReissuedInvoiceID is set for IssueActionReissue
+	ReissuedInvoiceID string    `json:"reissued_invoice_id,omitempty"`
+	ResolvedBy        string    `json:"resolved_by,omitempty"`
+	ResolvedAt        time.Time `json:"resolved_at"`
+}
+
+// PaymentIssue is a payment flagged for attention
+type PaymentIssue struct {
+	IssueID        string             `json:"issue_id"`
+	InvoiceID      string             `json:"invoice_id"`
+	OrderID        string             `json:"order_id,omitempty"`
+	Type           PaymentIssueType   `json:"type"`
+	Status         PaymentIssueStatus `json:"status"`
+	Currency       string             `json:"currency"`
+	Network        string             `json:"network,omitempty"`
+	TxHash         string             `json:"tx_hash,omitempty"`
+	ExpectedAmount float64            `json:"expected_amount"`
+	ReceivedAmount float64            `json:"received_amount"`
+	Description    string             `json:"description,omitempty"`
+	// AvailableActions lists the actions ResolvePaymentIssue accepts for this issue
+	AvailableActions []PaymentIssueAction    `json:"available_actions,omitempty"`
+	Resolution       *PaymentIssueResolution `json:"resolution,omitempty"`
+	CreatedAt        time.Time               `json:"created_at"`
+	UpdatedAt        time.Time               `json:"updated_at"`
+}
+
+// CanResolve reports whether action is one of the issue's available actions
+func (i *PaymentIssue) CanResolve(action PaymentIssueAction) bool {
+	for _, available := range i.AvailableActions {
+		if available == action {
+			return true
+		}
+	}
+	return false
+}
+
+// ListPaymentIssuesParams represents parameters for listing payment issues
+type ListPaymentIssuesParams struct {
+	InvoiceID string
+	Type      PaymentIssueType
+	Status    PaymentIssueStatus
+	Page      int
+	PageSize  int
+}
+
+// ListPaymentIssuesResponse represents the response from listing payment issues
+type ListPaymentIssuesResponse struct {
+	Items      []PaymentIssue `json:"items"`
+	Pagination PaginationInfo `json:"pagination"`
+}
+
+// ResolvePaymentIssueRequest represents the request to resolve a payment issue
+type ResolvePaymentIssueRequest struct {
+	Action PaymentIssueAction `json:"action"`
+	// RefundAddress is required for IssueActionRefund
+	RefundAddress string `json:"refund_address,omitempty"`
+	Note          string `json:"note,omitempty"`
+}
+
+// ListPaymentIssues retrieves a paginated list of flagged payments, newest first
+func (c *Client) ListPaymentIssues(ctx context.Context, params ListPaymentIssuesParams, opts ...RequestOption) (*ListPaymentIssuesResponse, error) {
+	queryParams := url.Values{}
+	if params.InvoiceID != "" {
+		queryParams.Set("invoice_id", params.InvoiceID)
+	}
+	if params.Type != "" {
+		queryParams.Set("type", string(params.Type))
+	}
+	if params.Status != "" {
+		queryParams.Set("status", string(params.Status))
+	}
+	if params.Page > 0 {
+		queryParams.Set("page", strconv.Itoa(params.Page))
+	}
+	if params.PageSize > 0 {
+		queryParams.Set("page_size", strconv.Itoa(params.PageSize))
+	}
+
+	path := "/payment-issues"
+	if len(queryParams) > 0 {
+		path += "?" + queryParams.Encode()
+	}
+
+	return do[ListPaymentIssuesResponse](ctx, c, "GET", path, nil, opts...)
+}
+
+// GetPaymentIssue retrieves a specific payment issue by ID
+func (c *Client) GetPaymentIssue(ctx context.Context, issueID string, opts ...RequestOption) (*PaymentIssue, error) {
+	return do[PaymentIssue](ctx, c, "GET", "/payment-issues/"+url.PathEscape(issueID), nil, opts...)
+}
+
+// ResolvePaymentIssue closes a payment issue with one of its AvailableActions
+func (c *Client) ResolvePaymentIssue(ctx context.Context, issueID string, req ResolvePaymentIssueRequest, opts ...RequestOption) (*PaymentIssue, error) {
+	switch {
+	case req.Action == "":
+		return nil, fmt.Errorf("%w: action is required", ErrInvalidIssueResolution)
+	case req.Action == IssueActionRefund && req.RefundAddress == "":
+		return nil, fmt.Errorf("%w: refunds require a refund address", ErrInvalidIssueResolution)
+	}
+	return do[PaymentIssue](ctx, c, "POST", "/payment-issues/"+url.PathEscape(issueID)+"/resolve", req, opts...)
+}
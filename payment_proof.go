@@ -0,0 +1,146 @@
+package itispay
+
+import (
+	"bytes"
+	"context"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"time"
+)
+
+// SignatureAlgorithmEd25519 is the signature algorithm used for payment proofs
+const SignatureAlgorithmEd25519 = "ed25519"
+
+var (
+	// ErrInvalidProofSignature is returned when a payment proof signature does not verify
+	ErrInvalidProofSignature = errors.New("itispay: invalid payment proof signature")
+	// ErrNoMerkleProof is returned when a transaction has no merkle proof to verify
+	ErrNoMerkleProof = errors.New("itispay: transaction has no merkle proof")
+)
+
+// PaymentProof represents a provider-signed bundle proving payment of an invoice.
+// Archive RawProof together with Signature: the signature covers RawProof byte for byte.
+type PaymentProof struct {
+	RawProof           json.RawMessage `json:"proof"`
+	Signature          string          `json:"signature"`
+	SignatureAlgorithm string          `json:"signature_algorithm"`
+	KeyID              string          `json:"key_id"`
+
+	// Details is RawProof decoded
+	Details PaymentProofDetails `json:"-"`
+}
+
+// PaymentProofDetails represents the signed content of a payment proof
+type PaymentProofDetails struct {
+	InvoiceID    string             `json:"invoice_id"`
+	OrderID      string             `json:"order_id"`
+	Currency     string             `json:"currency"`
+	Network      string             `json:"network,omitempty"`
+	Address      string             `json:"address"`
+	AmountPaid   float64            `json:"amount_paid"`
+	Status       Status             `json:"status"`
+	Transactions []ProofTransaction `json:"transactions"`
+	IssuedAt     time.Time          `json:"issued_at"`
+}
+
+// ProofTransaction represents an on-chain transaction included in a payment proof
+type ProofTransaction struct {
+	TxHash      string    `json:"tx_hash"`
+	Amount      float64   `json:"amount"`
+	BlockHeight int64     `json:"block_height"`
+	BlockHash   string    `json:"block_hash"`
+	BlockTime   time.Time `json:"block_time"`
+	// MerkleRoot, MerkleBranch and TxIndex are only present for Bitcoin-style chains
+	MerkleRoot   string   `json:"merkle_root,omitempty"`
+	MerkleBranch []string `json:"merkle_branch,omitempty"`
+	TxIndex      int      `json:"tx_index,omitempty"`
+}
+
+// Verify checks the provider signature over RawProof with the provider's public key
+func (p *PaymentProof) Verify(publicKey ed25519.PublicKey) error {
+	if p.SignatureAlgorithm != SignatureAlgorithmEd25519 {
+		return fmt.Errorf("itispay: unsupported signature algorithm %q", p.SignatureAlgorithm)
+	}
+	// ed25519.Verify panics on a key of the wrong size
+	if len(publicKey) != ed25519.PublicKeySize {
+		return fmt.Errorf("itispay: invalid ed25519 public key length %d", len(publicKey))
+	}
+	signature, err := base64.StdEncoding.DecodeString(p.Signature)
+	if err != nil {
+		return fmt.Errorf("failed to decode payment proof signature: %w", err)
+	}
+	if !ed25519.Verify(publicKey, p.RawProof, signature) {
+		return ErrInvalidProofSignature
+	}
+	return nil
+}
+
+// VerifyMerkleProof checks that the transaction is included in the block's merkle tree.
+// Hashes use the Bitcoin convention: double SHA-256, displayed in reversed byte order.
+func (t *ProofTransaction) VerifyMerkleProof() (bool, error) {
+	if t.MerkleRoot == "" {
+		return false, ErrNoMerkleProof
+	}
+	hash, err := decodeDisplayHash(t.TxHash)
+	if err != nil {
+		return false, fmt.Errorf("invalid tx hash: %w", err)
+	}
+	root, err := decodeDisplayHash(t.MerkleRoot)
+	if err != nil {
+		return false, fmt.Errorf("invalid merkle root: %w", err)
+	}
+
+	index := t.TxIndex
+	for _, siblingHex := range t.MerkleBranch {
+		sibling, err := decodeDisplayHash(siblingHex)
+		if err != nil {
+			return false, fmt.Errorf("invalid merkle branch hash: %w", err)
+		}
+		if index&1 == 0 {
+			hash = doubleSHA256(append(hash, sibling...))
+		} else {
+			hash = doubleSHA256(append(sibling, hash...))
+		}
+		index >>= 1
+	}
+	return bytes.Equal(hash, root), nil
+}
+
+// decodeDisplayHash decodes a hex hash displayed in reversed byte order
+func decodeDisplayHash(s string) ([]byte, error) {
+	b, err := hex.DecodeString(s)
+	if err != nil {
+		return nil, err
+	}
+	for i, j := 0, len(b)-1; i < j; i, j = i+1, j-1 {
+		b[i], b[j] = b[j], b[i]
+	}
+	return b, nil
+}
+
+// doubleSHA256 returns SHA-256(SHA-256(b))
+func doubleSHA256(b []byte) []byte {
+	first := sha256.Sum256(b)
+	second := sha256.Sum256(first[:])
+	return second[:]
+}
+
+// GetPaymentProof retrieves a signed proof of payment for a completed invoice
+func (c *Client) GetPaymentProof(ctx context.Context, invoiceID string, opts ...RequestOption) (*PaymentProof, error) {
+	path := "/invoices/" + url.PathEscape(invoiceID) + "/proof"
+	proof, err := do[PaymentProof](ctx, c, "GET", path, nil, opts...)
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(proof.RawProof, &proof.Details); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal payment proof details: %w", newDecodeError(endpointLabel("GET", path), proof.RawProof, err))
+	}
+
+	return proof, nil
+}
@@ -0,0 +1,56 @@
+package itispay_test
+
+import (
+	"crypto/ed25519"
+	"encoding/base64"
+	"errors"
+	"testing"
+
+	itispay "github.com/ItIsPay/go-client"
+)
+
+func TestPaymentProofVerify(t *testing.T) {
+	publicKey, privateKey, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	otherKey, _, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	raw := []byte(`{"invoice_id":"inv-1","status":"completed"}`)
+	signed := func(algorithm string) *itispay.PaymentProof {
+		return &itispay.PaymentProof{
+			RawProof:           raw,
+			Signature:          base64.StdEncoding.EncodeToString(ed25519.Sign(privateKey, raw)),
+			SignatureAlgorithm: algorithm,
+		}
+	}
+
+	tests := []struct {
+		name    string
+		proof   *itispay.PaymentProof
+		key     ed25519.PublicKey
+		wantErr bool
+		is      error
+	}{
+		{name: "valid", proof: signed(itispay.SignatureAlgorithmEd25519), key: publicKey},
+		{name: "other key", proof: signed(itispay.SignatureAlgorithmEd25519), key: otherKey, wantErr: true, is: itispay.ErrInvalidProofSignature},
+		{name: "short key", proof: signed(itispay.SignatureAlgorithmEd25519), key: publicKey[:16], wantErr: true},
+		{name: "no key", proof: signed(itispay.SignatureAlgorithmEd25519), wantErr: true},
+		{name: "missing algorithm", proof: signed(""), key: publicKey, wantErr: true},
+		{name: "unsupported algorithm", proof: signed("rsa"), key: publicKey, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.proof.Verify(tt.key)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, want error: %t", err, tt.wantErr)
+			}
+			if tt.is != nil && !errors.Is(err, tt.is) {
+				t.Errorf("err = %v, want %v", err, tt.is)
+			}
+		})
+	}
+}
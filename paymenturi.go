@@ -0,0 +1,87 @@
+package itispay
+
+import (
+	"errors"
+	"math/big"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// ErrNoPaymentAddress is returned when an invoice has no blockchain address to pay to
+var ErrNoPaymentAddress = errors.New("itispay: invoice has no payment address")
+
+// paymentURISchemes maps currency codes to their wallet URI schemes (BIP-21 style)
+var paymentURISchemes = map[string]string{
+	"BTC":  "bitcoin",
+	"LTC":  "litecoin",
+	"BCH":  "bitcoincash",
+	"DOGE": "dogecoin",
+	"DASH": "dash",
+	"TRX":  "tron",
+	"SOL":  "solana",
+}
+
+// weiPerEther is the number of decimals of the ETH base unit used by EIP-681
+const weiPerEther = 18
+
+// PaymentURI returns a wallet payment URI for the invoice, suitable for rendering as a QR code.
+// Bitcoin-like currencies use BIP-21 ("bitcoin:<address>?amount=..."), ETH uses EIP-681
+// ("ethereum:<address>?value=<wei>"). For currencies without a known URI scheme the bare
+// blockchain address is returned, which wallets accept as well.
+func (i *Invoice) PaymentURI() (string, error) {
+	if i.BlockchainDetails == nil || i.BlockchainDetails.BlockchainAddress == "" {
+		return "", ErrNoPaymentAddress
+	}
+	address := i.BlockchainDetails.BlockchainAddress
+	currency := strings.ToUpper(i.Currency)
+	amount := strconv.FormatFloat(i.CryptoAmount, 'f', -1, 64)
+
+	if currency == "ETH" {
+		query := url.Values{}
+		if i.CryptoAmount > 0 {
+			query.Set("value", decimalToUnits(amount, weiPerEther))
+		}
+		return buildPaymentURI("ethereum", address, query), nil
+	}
+
+	scheme, ok := paymentURISchemes[currency]
+	if !ok {
+		return address, nil
+	}
+
+	query := url.Values{}
+	if i.CryptoAmount > 0 {
+		query.Set("amount", amount)
+	}
+	if i.OrderName != "" {
+		query.Set("label", i.OrderName)
+	}
+	return buildPaymentURI(scheme, address, query), nil
+}
+
+// buildPaymentURI assembles a "<scheme>:<address>?<query>" URI. Addresses may already
+// carry the scheme, as CashAddr ones do ("bitcoincash:qq..."), so it is not repeated.
+func buildPaymentURI(scheme, address string, query url.Values) string {
+	if prefix := scheme + ":"; len(address) > len(prefix) && strings.EqualFold(address[:len(prefix)], prefix) {
+		address = address[len(prefix):]
+	}
+	uri := scheme + ":" + address
+	if len(query) > 0 {
+		// BIP-21 expects %20 rather than + for spaces
+		uri += "?" + strings.ReplaceAll(query.Encode(), "+", "%20")
+	}
+	return uri
+}
+
+// decimalToUnits converts a decimal amount string to an integer count of base units,
+// truncating any precision beyond the given number of decimals
+func decimalToUnits(amount string, decimals int) string {
+	r, ok := new(big.Rat).SetString(amount)
+	if !ok {
+		return "0"
+	}
+	scale := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(decimals)), nil)
+	r.Mul(r, new(big.Rat).SetInt(scale))
+	return new(big.Int).Quo(r.Num(), r.Denom()).String()
+}
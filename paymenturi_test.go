@@ -0,0 +1,48 @@
+package itispay_test
+
+import (
+	"errors"
+	"testing"
+
+	itispay "github.com/ItIsPay/go-client"
+)
+
+func TestPaymentURI(t *testing.T) {
+	invoice := func(currency, address string, amount float64) *itispay.Invoice {
+		return &itispay.Invoice{
+			Currency:          currency,
+			CryptoAmount:      amount,
+			BlockchainDetails: &itispay.BlockchainDetails{BlockchainAddress: address},
+		}
+	}
+
+	tests := []struct {
+		name    string
+		invoice *itispay.Invoice
+		want    string
+	}{
+		{name: "bitcoin", invoice: invoice("BTC", "bc1qaddress", 0.0015), want: "bitcoin:bc1qaddress?amount=0.0015"},
+		{name: "cashaddr with scheme", invoice: invoice("BCH", "bitcoincash:qqaddress", 0.5), want: "bitcoincash:qqaddress?amount=0.5"},
+		{name: "cashaddr with upper case scheme", invoice: invoice("BCH", "BITCOINCASH:QQADDRESS", 0.5), want: "bitcoincash:QQADDRESS?amount=0.5"},
+		{name: "cashaddr without scheme", invoice: invoice("BCH", "qqaddress", 0.5), want: "bitcoincash:qqaddress?amount=0.5"},
+		{name: "ethereum", invoice: invoice("ETH", "0xaddress", 1.5), want: "ethereum:0xaddress?value=1500000000000000000"},
+		{name: "ethereum with scheme", invoice: invoice("ETH", "ethereum:0xaddress", 0), want: "ethereum:0xaddress"},
+		{name: "unknown scheme", invoice: invoice("XMR", "4address", 1), want: "4address"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.invoice.PaymentURI()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("PaymentURI() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	if _, err := (&itispay.Invoice{Currency: "BTC"}).PaymentURI(); !errors.Is(err, itispay.ErrNoPaymentAddress) {
+		t.Errorf("err = %v, want ErrNoPaymentAddress", err)
+	}
+}
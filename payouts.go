@@ -0,0 +1,114 @@
+package itispay
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"time"
+)
+
+// Payout status constants
+const (
+	PayoutStatusDraft           = "draft"
+	PayoutStatusPendingApproval = "pending_approval"
+	PayoutStatusApproved        = "approved"
+	PayoutStatusRejected        = "rejected"
+	PayoutStatusSent            = "sent"
+	PayoutStatusFailed          = "failed"
+)
+
+// ErrApproverTokenRequired is returned when approving or rejecting a payout without an approver token
+var ErrApproverTokenRequired = errors.New("itispay: approver token is required")
+
+// CreatePayoutDraftRequest represents the request to create a payout draft
+type CreatePayoutDraftRequest struct {
+	Currency  string  `json:"currency"`
+	Network   string  `json:"network,omitempty"`
+	Amount    float64 `json:"amount"`
+	Address   string  `json:"address"`
+	Reference string  `json:"reference,omitempty"`
+	Note      string  `json:"note,omitempty"`
+	// TravelRule carries originator and beneficiary information for regulated transfers;
+	// see GetPayoutCompliance
+	TravelRule *TravelRuleInfo `json:"travel_rule,omitempty"`
+}
+
+// PayoutApproval represents a single approval or rejection recorded on a payout
+type PayoutApproval struct {
+	ApproverID string    `json:"approver_id"`
+	Decision   string    `json:"decision"`
+	Reason     string    `json:"reason,omitempty"`
+	DecidedAt  time.Time `json:"decided_at"`
+}
+
+// Payout represents an outgoing cryptocurrency transfer
+type Payout struct {
+	PayoutID          string           `json:"payout_id"`
+	Currency          string           `json:"currency"`
+	Network           string           `json:"network,omitempty"`
+	Amount            float64          `json:"amount"`
+	Address           string           `json:"address"`
+	Reference         string           `json:"reference,omitempty"`
+	Note              string           `json:"note,omitempty"`
+	Status            string           `json:"status"`
+	CreatedBy         string           `json:"created_by"`
+	RequiredApprovals int              `json:"required_approvals"`
+	Approvals         []PayoutApproval `json:"approvals,omitempty"`
+	TxHash            string           `json:"tx_hash,omitempty"`
+	CreatedAt         time.Time        `json:"created_at"`
+	UpdatedAt         time.Time        `json:"updated_at"`
+}
+
+// ListPayoutsResponse represents the response from listing payouts
+type ListPayoutsResponse struct {
+	Items      []Payout       `json:"items"`
+	Pagination PaginationInfo `json:"pagination"`
+}
+
+// payoutDecisionRequest represents an approve or reject decision on a payout
+type payoutDecisionRequest struct {
+	ApproverToken string `json:"approver_token"`
+	Reason        string `json:"reason,omitempty"`
+}
+
+// CreatePayoutDraft creates a payout in draft state. The payout is not sent until it has
+// collected the number of approvals required by the account's treasury policy.
+func (c *Client) CreatePayoutDraft(ctx context.Context, req CreatePayoutDraftRequest, opts ...RequestOption) (*Payout, error) {
+	if req.TravelRule != nil {
+		if err := req.TravelRule.Validate(); err != nil {
+			return nil, err
+		}
+	}
+	return do[Payout](ctx, c, "POST", "/payouts", req, opts...)
+}
+
+// GetPayout retrieves a specific payout by ID
+func (c *Client) GetPayout(ctx context.Context, payoutID string, opts ...RequestOption) (*Payout, error) {
+	return do[Payout](ctx, c, "GET", "/payouts/"+url.PathEscape(payoutID), nil, opts...)
+}
+
+// ListPendingPayoutApprovals retrieves payouts that are waiting for approval
+func (c *Client) ListPendingPayoutApprovals(ctx context.Context, opts ...RequestOption) (*ListPayoutsResponse, error) {
+	return do[ListPayoutsResponse](ctx, c, "GET", "/payouts?status="+PayoutStatusPendingApproval, nil, opts...)
+}
+
+// ApprovePayout records an approval on a payout draft. The approver token identifies the
+// approving user; the API rejects approvals by the user who created the draft, enforcing
+// four-eyes control.
+func (c *Client) ApprovePayout(ctx context.Context, payoutID, approverToken string, opts ...RequestOption) (*Payout, error) {
+	return c.decidePayout(ctx, payoutID, "approve", payoutDecisionRequest{ApproverToken: approverToken}, opts...)
+}
+
+// RejectPayout rejects a payout draft with an optional reason
+func (c *Client) RejectPayout(ctx context.Context, payoutID, approverToken, reason string, opts ...RequestOption) (*Payout, error) {
+	return c.decidePayout(ctx, payoutID, "reject", payoutDecisionRequest{ApproverToken: approverToken, Reason: reason}, opts...)
+}
+
+// decidePayout posts an approval decision for a payout
+func (c *Client) decidePayout(ctx context.Context, payoutID, action string, req payoutDecisionRequest, opts ...RequestOption) (*Payout, error) {
+	if req.ApproverToken == "" {
+		return nil, ErrApproverTokenRequired
+	}
+
+	return do[Payout](ctx, c, "POST", "/payouts/"+url.PathEscape(payoutID)+"/"+action, req, opts...)
+}
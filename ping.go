@@ -0,0 +1,35 @@
+package itispay
+
+import (
+	"context"
+	"time"
+)
+
+// apiVersionHeader carries the API version on every response
+const apiVersionHeader = "X-Api-Version"
+
+// PingResult reports the availability of the API
+type PingResult struct {
+	// Status is "ok" when the API is healthy
+	Status string `json:"status"`
+	// Version is the API version serving the request
+	Version string `json:"version"`
+	// Latency is the round trip time of the health check
+	Latency time.Duration `json:"-"`
+}
+
+// Ping calls the lightweight health endpoint and returns the API version and round trip
+// latency, e.g. for readiness probes or to gate deploys on the API being reachable.
+// Any error means the API could not be reached or is unhealthy.
+func (c *Client) Ping(ctx context.Context, opts ...RequestOption) (*PingResult, error) {
+	start := time.Now()
+	result, resp, err := doWithResponse[PingResult](ctx, c, "GET", "/health", nil, opts...)
+	if err != nil {
+		return nil, err
+	}
+	result.Latency = time.Since(start)
+	if result.Version == "" {
+		result.Version = resp.Header.Get(apiVersionHeader)
+	}
+	return result, nil
+}
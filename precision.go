@@ -0,0 +1,174 @@
+package itispay
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// PrecisionMode controls how crypto amounts exceeding the currency precision are handled
+type PrecisionMode int
+
+// Precision mode constants
+const (
+	// PrecisionOff sends amounts as given and lets the API round them
+	PrecisionOff PrecisionMode = iota
+	// PrecisionReject fails the call with a *PrecisionError
+	PrecisionReject
+	// PrecisionRound rounds the amount to the currency precision and reports a PrecisionWarning
+	PrecisionRound
+)
+
+// PrecisionWarning describes an amount rounded by PrecisionRound
+type PrecisionWarning struct {
+	Currency  string
+	Precision int
+	Original  float64
+	Rounded   float64
+}
+
+// PrecisionError is returned when an amount has more decimals than its currency allows
+type PrecisionError struct {
+	Currency  string
+	Precision int
+	Amount    float64
+}
+
+// Error returns the error message
+func (e *PrecisionError) Error() string {
+	return fmt.Sprintf("itispay: amount %s exceeds %s precision of %d decimals",
+		strconv.FormatFloat(e.Amount, 'f', -1, 64), e.Currency, e.Precision)
+}
+
+// precisionPolicy holds the configured precision enforcement
+type precisionPolicy struct {
+	mode    PrecisionMode
+	onRound func(PrecisionWarning)
+}
+
+// currencyCacheTTL is how long the loaded currencies are used before being reloaded
+const currencyCacheTTL = time.Hour
+
+// currencyCache lazily loads the supported currencies
+type currencyCache struct {
+	mu         sync.Mutex
+	currencies map[string]Currency
+	loadedAt   time.Time
+	// loading is the load in flight, if any
+	loading *currencyLoad
+}
+
+// currencyLoad is a load of the currency list shared by concurrent lookups
+type currencyLoad struct {
+	done chan struct{}
+	err  error
+}
+
+// store replaces the cached currencies; mu must be held
+func (cc *currencyCache) store(currencies []Currency, now time.Time) {
+	cc.currencies = make(map[string]Currency, len(currencies))
+	for _, currency := range currencies {
+		cc.currencies[strings.ToUpper(currency.CurrencyCode)] = currency
+	}
+	cc.loadedAt = now
+}
+
+// WithPrecisionEnforcement checks CryptoAmount against the currency's Precision before
+// creating invoices. With PrecisionRound, onRound (which may be nil) is called for every
+// rounded amount. Currency precisions are loaded via GetCurrencies and cached for an hour.
+func WithPrecisionEnforcement(mode PrecisionMode, onRound func(PrecisionWarning)) Option {
+	return func(c *Client) {
+		c.precision = precisionPolicy{mode: mode, onRound: onRound}
+	}
+}
+
+// lookupCurrency returns a supported currency by code, loading the currency list on first
+// use and once it has expired. Only one load runs at a time, without holding the cache
+// lock; concurrent lookups wait for its result.
+func (c *Client) lookupCurrency(ctx context.Context, code string) (Currency, bool, error) {
+	cc := &c.currencyCache
+	for {
+		cc.mu.Lock()
+		if cc.currencies != nil && c.clock.Now().Sub(cc.loadedAt) < currencyCacheTTL {
+			currency, ok := cc.currencies[strings.ToUpper(code)]
+			cc.mu.Unlock()
+			return currency, ok, nil
+		}
+
+		if load := cc.loading; load != nil {
+			cc.mu.Unlock()
+			select {
+			case <-load.done:
+			case <-ctx.Done():
+				return Currency{}, false, ctx.Err()
+			}
+			// A load abandoned by its caller is retried with this caller's context
+			if load.err != nil && !(errors.Is(load.err, context.Canceled) || errors.Is(load.err, context.DeadlineExceeded)) {
+				return Currency{}, false, load.err
+			}
+			continue
+		}
+
+		load := &currencyLoad{done: make(chan struct{})}
+		cc.loading = load
+		cc.mu.Unlock()
+
+		response, err := c.GetCurrencies(ctx)
+		cc.mu.Lock()
+		cc.loading = nil
+		if err != nil {
+			load.err = fmt.Errorf("failed to load currencies: %w", err)
+		} else {
+			cc.store(response.Currencies, c.clock.Now())
+		}
+		cc.mu.Unlock()
+		close(load.done)
+		if load.err != nil {
+			return Currency{}, false, load.err
+		}
+	}
+}
+
+// enforcePrecision applies the precision policy to an amount. Unknown currencies are
+// left to the API to validate.
+func (c *Client) enforcePrecision(ctx context.Context, currencyCode string, amount float64) (float64, error) {
+	if c.precision.mode == PrecisionOff {
+		return amount, nil
+	}
+
+	currency, ok, err := c.lookupCurrency(ctx, currencyCode)
+	if err != nil || !ok {
+		return amount, err
+	}
+	if decimalPlaces(amount) <= currency.Precision {
+		return amount, nil
+	}
+
+	if c.precision.mode == PrecisionReject {
+		return 0, &PrecisionError{Currency: currency.CurrencyCode, Precision: currency.Precision, Amount: amount}
+	}
+
+	rounded, _ := strconv.ParseFloat(strconv.FormatFloat(amount, 'f', currency.Precision, 64), 64)
+	if c.precision.onRound != nil {
+		c.precision.onRound(PrecisionWarning{
+			Currency:  currency.CurrencyCode,
+			Precision: currency.Precision,
+			Original:  amount,
+			Rounded:   rounded,
+		})
+	}
+	return rounded, nil
+}
+
+// decimalPlaces returns the number of decimals in the shortest representation of f
+func decimalPlaces(f float64) int {
+	s := strconv.FormatFloat(f, 'f', -1, 64)
+	if i := strings.IndexByte(s, '.'); i >= 0 {
+		return len(s) - i - 1
+	}
+	return 0
+}
@@ -0,0 +1,69 @@
+package itispay_test
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	itispay "github.com/ItIsPay/go-client"
+	"github.com/ItIsPay/go-client/itispaytest"
+)
+
+func TestPrecisionCurrencyCache(t *testing.T) {
+	srv := itispaytest.NewServer()
+	defer srv.Close()
+	clock := itispaytest.NewFakeClock(time.Now())
+	var loads atomic.Int32
+	client := srv.Client(
+		itispay.WithClock(clock),
+		itispay.WithPrecisionEnforcement(itispay.PrecisionRound, nil),
+		itispay.WithInterceptor(func(next itispay.RoundTripFunc) itispay.RoundTripFunc {
+			return func(req *http.Request) (*http.Response, error) {
+				if req.URL.Path == "/currencies" {
+					loads.Add(1)
+				}
+				return next(req)
+			}
+		}),
+	)
+	create := func() {
+		amount := 0.123456789123
+		_, err := client.CreateInvoice(context.Background(), itispay.CreateInvoiceRequest{
+			OrderID:      "ORDER-1",
+			CryptoAmount: &amount,
+			Currency:     "BTC",
+		})
+		if err != nil {
+			t.Error(err)
+		}
+	}
+
+	// Concurrent creations share one load
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			create()
+		}()
+	}
+	wg.Wait()
+	if got := loads.Load(); got != 1 {
+		t.Errorf("currencies loaded %d times, want 1", got)
+	}
+
+	clock.Advance(30 * time.Minute)
+	create()
+	if got := loads.Load(); got != 1 {
+		t.Errorf("currencies loaded %d times before expiry, want 1", got)
+	}
+
+	clock.Advance(time.Hour)
+	create()
+	if got := loads.Load(); got != 2 {
+		t.Errorf("currencies loaded %d times after expiry, want 2", got)
+	}
+}
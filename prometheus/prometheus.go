@@ -0,0 +1,209 @@
+// Package prometheus provides an itispay.Metrics implementation backed by the Prometheus
+// client library. Metrics is a prometheus.Collector, so register it with the registry
+// your service already exposes:
+//
+//	metrics := itispayprom.New()
+//	prometheus.MustRegister(metrics)
+//	client := itispay.NewClient(apiKey, itispay.WithMetrics(metrics))
+//
+// or serve it on its own, since it is also an http.Handler:
+//
+//	http.Handle("/metrics/itispay", metrics)
+//
+// Exported metrics:
+//
+//   - itispay_client_request_duration_seconds{endpoint,code}: histogram of API call latency
+//   - itispay_client_retries_total{endpoint}: requests retried by the client
+//   - itispay_client_rate_limited_total{endpoint}: requests rejected with HTTP 429
+//   - itispay_client_deprecation_warnings_total{endpoint}: responses carrying deprecation headers
+//   - itispay_webhook_latency_seconds{status}: histogram of the delay between invoice updates and
+//     webhook receipt, when passed to the webhook package's Handler or Outbox
+//
+// With itispay.WithTraceIDFunc, request latencies carry trace ID exemplars. Exemplars are
+// only part of the OpenMetrics format, served to scrapers asking for it.
+//
+// It lives in its own module to keep the core client free of dependencies.
+package prometheus
+
+import (
+	"net/http"
+	"sort"
+	"strconv"
+	"time"
+
+	itispay "github.com/ItIsPay/go-client"
+	prom "github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// Namespace is the metric namespace used by default
+const Namespace = "itispay"
+
+// DefaultBuckets are the default request duration histogram buckets, in seconds
+var DefaultBuckets = []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10}
+
+// DefaultWebhookBuckets are the default webhook latency histogram buckets, in seconds
+var DefaultWebhookBuckets = []float64{.5, 1, 2.5, 5, 10, 30, 60, 120, 300, 600, 1800, 3600}
+
+// Config configures the exported metrics
+type Config struct {
+	// Namespace overrides the metric namespace (default "itispay")
+	Namespace string
+	// Buckets overrides the request duration histogram buckets (default DefaultBuckets)
+	Buckets []float64
+	// WebhookBuckets overrides the webhook latency histogram buckets (default
+	// DefaultWebhookBuckets)
+	WebhookBuckets []float64
+	// ConstLabels are added to every metric, e.g. the service name
+	ConstLabels map[string]string
+}
+
+// Metrics collects ItIsPay client metrics. It is a prometheus.Collector and serves
+// them over HTTP.
+type Metrics struct {
+	requestDuration *prom.HistogramVec
+	retries         *prom.CounterVec
+	rateLimited     *prom.CounterVec
+	deprecation     *prom.CounterVec
+	webhookLatency  *prom.HistogramVec
+
+	handler http.Handler
+}
+
+var (
+	_ itispay.Metrics            = (*Metrics)(nil)
+	_ itispay.ExemplarMetrics    = (*Metrics)(nil)
+	_ itispay.DeprecationMetrics = (*Metrics)(nil)
+	_ prom.Collector             = (*Metrics)(nil)
+)
+
+// New creates Metrics with the default configuration
+func New() *Metrics {
+	return NewWithConfig(Config{})
+}
+
+// NewWithConfig creates Metrics with the given configuration
+func NewWithConfig(cfg Config) *Metrics {
+	if cfg.Namespace == "" {
+		cfg.Namespace = Namespace
+	}
+	if cfg.Buckets == nil {
+		cfg.Buckets = DefaultBuckets
+	}
+	if cfg.WebhookBuckets == nil {
+		cfg.WebhookBuckets = DefaultWebhookBuckets
+	}
+	constLabels := prom.Labels(cfg.ConstLabels)
+
+	m := &Metrics{
+		requestDuration: prom.NewHistogramVec(prom.HistogramOpts{
+			Namespace:   cfg.Namespace,
+			Subsystem:   "client",
+			Name:        "request_duration_seconds",
+			Help:        "Duration of ItIsPay API requests by endpoint and HTTP status code (0 for network errors).",
+			Buckets:     sortedBuckets(cfg.Buckets),
+			ConstLabels: constLabels,
+		}, []string{"endpoint", "code"}),
+		retries: prom.NewCounterVec(prom.CounterOpts{
+			Namespace:   cfg.Namespace,
+			Subsystem:   "client",
+			Name:        "retries_total",
+			Help:        "Number of ItIsPay API requests retried by the client.",
+			ConstLabels: constLabels,
+		}, []string{"endpoint"}),
+		rateLimited: prom.NewCounterVec(prom.CounterOpts{
+			Namespace:   cfg.Namespace,
+			Subsystem:   "client",
+			Name:        "rate_limited_total",
+			Help:        "Number of ItIsPay API requests rejected with HTTP 429.",
+			ConstLabels: constLabels,
+		}, []string{"endpoint"}),
+		deprecation: prom.NewCounterVec(prom.CounterOpts{
+			Namespace:   cfg.Namespace,
+			Subsystem:   "client",
+			Name:        "deprecation_warnings_total",
+			Help:        "Number of ItIsPay API responses carrying deprecation or sunset headers.",
+			ConstLabels: constLabels,
+		}, []string{"endpoint"}),
+		webhookLatency: prom.NewHistogramVec(prom.HistogramOpts{
+			Namespace:   cfg.Namespace,
+			Subsystem:   "webhook",
+			Name:        "latency_seconds",
+			Help:        "Delay between ItIsPay invoice updates and receipt of the webhook, by invoice status.",
+			Buckets:     sortedBuckets(cfg.WebhookBuckets),
+			ConstLabels: constLabels,
+		}, []string{"status"}),
+	}
+
+	registry := prom.NewRegistry()
+	registry.MustRegister(m)
+	m.handler = promhttp.HandlerFor(registry, promhttp.HandlerOpts{EnableOpenMetrics: true})
+	return m
+}
+
+// sortedBuckets returns a sorted copy of buckets, as histograms require
+func sortedBuckets(buckets []float64) []float64 {
+	sorted := append([]float64(nil), buckets...)
+	sort.Float64s(sorted)
+	return sorted
+}
+
+// ObserveRequest records a completed HTTP call
+func (m *Metrics) ObserveRequest(endpoint string, statusCode int, duration time.Duration) {
+	m.requestDuration.WithLabelValues(endpoint, strconv.Itoa(statusCode)).Observe(duration.Seconds())
+}
+
+// ObserveRequestWithTrace records a completed HTTP call with its trace ID as exemplar
+func (m *Metrics) ObserveRequestWithTrace(endpoint string, statusCode int, duration time.Duration, traceID string) {
+	observer := m.requestDuration.WithLabelValues(endpoint, strconv.Itoa(statusCode))
+	if exemplar, ok := observer.(prom.ExemplarObserver); ok && traceID != "" {
+		exemplar.ObserveWithExemplar(duration.Seconds(), prom.Labels{"trace_id": traceID})
+		return
+	}
+	observer.Observe(duration.Seconds())
+}
+
+// IncRetry counts a retried request
+func (m *Metrics) IncRetry(endpoint string) {
+	m.retries.WithLabelValues(endpoint).Inc()
+}
+
+// IncRateLimited counts a rate-limited request
+func (m *Metrics) IncRateLimited(endpoint string) {
+	m.rateLimited.WithLabelValues(endpoint).Inc()
+}
+
+// IncDeprecationWarning counts a response carrying deprecation headers
+func (m *Metrics) IncDeprecationWarning(endpoint string) {
+	m.deprecation.WithLabelValues(endpoint).Inc()
+}
+
+// ObserveWebhookLatency records the delay between an invoice update and the receipt
+// of its webhook
+func (m *Metrics) ObserveWebhookLatency(status string, latency time.Duration) {
+	m.webhookLatency.WithLabelValues(status).Observe(latency.Seconds())
+}
+
+// Describe implements prometheus.Collector
+func (m *Metrics) Describe(ch chan<- *prom.Desc) {
+	m.requestDuration.Describe(ch)
+	m.retries.Describe(ch)
+	m.rateLimited.Describe(ch)
+	m.deprecation.Describe(ch)
+	m.webhookLatency.Describe(ch)
+}
+
+// Collect implements prometheus.Collector
+func (m *Metrics) Collect(ch chan<- prom.Metric) {
+	m.requestDuration.Collect(ch)
+	m.retries.Collect(ch)
+	m.rateLimited.Collect(ch)
+	m.deprecation.Collect(ch)
+	m.webhookLatency.Collect(ch)
+}
+
+// ServeHTTP serves the metrics in the Prometheus text exposition format, or in the
+// OpenMetrics format with exemplars if the scraper accepts it
+func (m *Metrics) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	m.handler.ServeHTTP(w, r)
+}
@@ -0,0 +1,78 @@
+package prometheus_test
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	itispayprom "github.com/ItIsPay/go-client/prometheus"
+	prom "github.com/prometheus/client_golang/prometheus"
+)
+
+// scrape serves the metrics to a scraper accepting accept and returns the body
+func scrape(t *testing.T, handler http.Handler, accept string) string {
+	t.Helper()
+	req := httptest.NewRequest(http.MethodGet, "/metrics", nil)
+	req.Header.Set("Accept", accept)
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	body, err := io.ReadAll(rec.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(body)
+}
+
+func TestMetrics(t *testing.T) {
+	metrics := itispayprom.NewWithConfig(itispayprom.Config{ConstLabels: map[string]string{"service": "shop"}})
+	metrics.ObserveRequest("POST /invoices", 201, 30*time.Millisecond)
+	metrics.IncRetry("GET /rates")
+	metrics.IncRateLimited("GET /rates")
+	metrics.IncDeprecationWarning("GET /rates")
+	metrics.ObserveWebhookLatency("completed", 2*time.Second)
+
+	body := scrape(t, metrics, "text/plain")
+	for _, want := range []string{
+		`itispay_client_request_duration_seconds_bucket{code="201",endpoint="POST /invoices",service="shop",le="0.05"} 1`,
+		`itispay_client_retries_total{endpoint="GET /rates",service="shop"} 1`,
+		`itispay_client_rate_limited_total{endpoint="GET /rates",service="shop"} 1`,
+		`itispay_client_deprecation_warnings_total{endpoint="GET /rates",service="shop"} 1`,
+		`itispay_webhook_latency_seconds_count{service="shop",status="completed"} 1`,
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("missing %s in:\n%s", want, body)
+		}
+	}
+}
+
+func TestMetricsExemplars(t *testing.T) {
+	metrics := itispayprom.New()
+	metrics.ObserveRequestWithTrace("GET /rates", 200, 20*time.Millisecond, "4bf92f3577b34da6a3ce929d0e0e4736")
+
+	if body := scrape(t, metrics, "application/openmetrics-text"); !strings.Contains(body, `# {trace_id="4bf92f3577b34da6a3ce929d0e0e4736"} 0.02`) {
+		t.Errorf("no exemplar in OpenMetrics output:\n%s", body)
+	}
+	if body := scrape(t, metrics, "text/plain"); strings.Contains(body, "trace_id") {
+		t.Errorf("exemplar in text format output:\n%s", body)
+	}
+}
+
+func TestMetricsRegister(t *testing.T) {
+	registry := prom.NewRegistry()
+	metrics := itispayprom.New()
+	if err := registry.Register(metrics); err != nil {
+		t.Fatal(err)
+	}
+	metrics.IncRetry("GET /rates")
+
+	families, err := registry.Gather()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(families) != 1 || families[0].GetName() != "itispay_client_retries_total" {
+		t.Errorf("gathered %v, want the retries counter", families)
+	}
+}
@@ -0,0 +1,56 @@
+// Package qrcode renders ItIsPay payment URIs as QR codes locally, so checkout
+// pages do not depend on a server-provided image.
+//
+// It lives in its own module to keep the core client free of dependencies.
+package qrcode
+
+import (
+	"bytes"
+	"fmt"
+
+	qr "github.com/skip2/go-qrcode"
+)
+
+// DefaultSize is the default image size in pixels
+const DefaultSize = 256
+
+// PNG renders content (typically Invoice.PaymentURI()) as a PNG image of the given size
+func PNG(content string, size int) ([]byte, error) {
+	code, err := qr.New(content, qr.Medium)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode QR code: %w", err)
+	}
+	if size <= 0 {
+		size = DefaultSize
+	}
+	return code.PNG(size)
+}
+
+// SVG renders content (typically Invoice.PaymentURI()) as an SVG image of the given size
+func SVG(content string, size int) ([]byte, error) {
+	code, err := qr.New(content, qr.Medium)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode QR code: %w", err)
+	}
+	if size <= 0 {
+		size = DefaultSize
+	}
+
+	bitmap := code.Bitmap()
+	modules := len(bitmap)
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d" shape-rendering="crispEdges">`,
+		size, size, modules, modules)
+	fmt.Fprintf(&buf, `<rect width="%d" height="%d" fill="#ffffff"/>`, modules, modules)
+	buf.WriteString(`<path fill="#000000" d="`)
+	for y, row := range bitmap {
+		for x, dark := range row {
+			if dark {
+				fmt.Fprintf(&buf, "M%d %dh1v1h-1z", x, y)
+			}
+		}
+	}
+	buf.WriteString(`"/></svg>`)
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,65 @@
+package qrcode_test
+
+import (
+	"bytes"
+	"image/png"
+	"strings"
+	"testing"
+
+	"github.com/ItIsPay/go-client/qrcode"
+)
+
+const paymentURI = "bitcoin:bc1qaddress?amount=0.0015"
+
+func TestPNG(t *testing.T) {
+	tests := []struct {
+		name string
+		size int
+		want int
+	}{
+		{name: "given size", size: 128, want: 128},
+		{name: "default size", want: qrcode.DefaultSize},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := qrcode.PNG(paymentURI, tt.size)
+			if err != nil {
+				t.Fatal(err)
+			}
+			img, err := png.Decode(bytes.NewReader(data))
+			if err != nil {
+				t.Fatalf("decoding: %v", err)
+			}
+			if bounds := img.Bounds(); bounds.Dx() != tt.want || bounds.Dy() != tt.want {
+				t.Errorf("image is %dx%d, want %dx%[3]d", bounds.Dx(), bounds.Dy(), tt.want)
+			}
+		})
+	}
+}
+
+func TestSVG(t *testing.T) {
+	data, err := qrcode.SVG(paymentURI, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	svg := string(data)
+	if !strings.HasPrefix(svg, "<svg ") || !strings.HasSuffix(svg, "</svg>") {
+		t.Fatalf("not an SVG document: %.60s", svg)
+	}
+	if !strings.Contains(svg, `width="256" height="256"`) {
+		t.Errorf("SVG does not have the default size: %.120s", svg)
+	}
+	if !strings.Contains(svg, "h1v1h-1z") {
+		t.Error("SVG has no dark modules")
+	}
+}
+
+func TestTooLong(t *testing.T) {
+	if _, err := qrcode.PNG(strings.Repeat("x", 4000), 0); err == nil {
+		t.Error("content beyond QR code capacity encoded")
+	}
+	if _, err := qrcode.SVG(strings.Repeat("x", 4000), 0); err == nil {
+		t.Error("content beyond QR code capacity encoded")
+	}
+}
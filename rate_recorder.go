@@ -0,0 +1,175 @@
+package itispay
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"sync"
+	"time"
+)
+
+// RateSnapshot records the exchange rate that produced an invoice's crypto amount
+type RateSnapshot struct {
+	InvoiceID    string  `json:"invoice_id"`
+	OrderID      string  `json:"order_id"`
+	FiatCurrency string  `json:"fiat_currency"`
+	Currency     string  `json:"currency"`
+	FiatAmount   float64 `json:"fiat_amount"`
+	CryptoAmount float64 `json:"crypto_amount"`
+	// Rate is the fiat price of one unit of Currency, as provided by the API if it was
+	Rate float64 `json:"rate"`
+	// Derived is set when the API provided no rate and Rate was computed as
+	// FiatAmount / CryptoAmount, so it carries the rounding of CryptoAmount
+	Derived    bool      `json:"derived"`
+	QuotedAt   time.Time `json:"quoted_at"`
+	RecordedAt time.Time `json:"recorded_at"`
+}
+
+// RateStore persists rate snapshots. Implementations must be safe for concurrent use.
+type RateStore interface {
+	SaveRateSnapshot(ctx context.Context, snapshot RateSnapshot) error
+}
+
+// RateRecorder persists a RateSnapshot for every invoice created through the client,
+// so audits can prove which rate produced a given crypto amount. Feed it webhooks with
+// RecordWebhook to keep the rate provided by the API once the invoice is paid.
+type RateRecorder struct {
+	store RateStore
+	// OnError is called when a snapshot cannot be saved. Invoice creation is not
+	// affected by recording failures; if OnError is nil they are ignored.
+	OnError func(snapshot RateSnapshot, err error)
+	// Clock stamps RecordedAt, SystemClock if nil
+	Clock Clock
+}
+
+// NewRateRecorder creates a RateRecorder backed by store
+func NewRateRecorder(store RateStore) *RateRecorder {
+	return &RateRecorder{store: store}
+}
+
+// WithRateRecorder records a rate snapshot after each successful CreateInvoice call
+func WithRateRecorder(recorder *RateRecorder) Option {
+	return func(c *Client) {
+		c.rateRecorder = recorder
+	}
+}
+
+// Record saves the rate snapshot of an invoice
+func (r *RateRecorder) Record(ctx context.Context, invoice *Invoice) error {
+	return r.save(ctx, NewRateSnapshot(invoice))
+}
+
+// RecordWebhook saves the rate snapshot of a webhook, replacing the invoice's derived
+// rate with the PaymentRate provided by the API, if the webhook carries it
+func (r *RateRecorder) RecordWebhook(ctx context.Context, payload *WebhookPayload) error {
+	return r.save(ctx, NewWebhookRateSnapshot(payload))
+}
+
+// save stamps and stores snapshot
+func (r *RateRecorder) save(ctx context.Context, snapshot RateSnapshot) error {
+	snapshot.RecordedAt = clockOrSystem(r.Clock).Now().UTC()
+	err := r.store.SaveRateSnapshot(ctx, snapshot)
+	if err != nil && r.OnError != nil {
+		r.OnError(snapshot, err)
+	}
+	return err
+}
+
+// NewRateSnapshot returns the rate snapshot of an invoice. Invoices do not carry the
+// rate they were quoted at, so it is derived from the amounts.
+func NewRateSnapshot(invoice *Invoice) RateSnapshot {
+	snapshot := RateSnapshot{
+		InvoiceID:    invoice.InvoiceID,
+		OrderID:      invoice.OrderID,
+		FiatCurrency: invoice.FiatCurrency,
+		Currency:     invoice.Currency,
+		FiatAmount:   invoice.FiatAmount,
+		CryptoAmount: invoice.CryptoAmount,
+		QuotedAt:     invoice.CreatedAt,
+	}
+	if invoice.CryptoAmount > 0 {
+		snapshot.Rate = invoice.FiatAmount / invoice.CryptoAmount
+		snapshot.Derived = true
+	}
+	return snapshot
+}
+
+// NewWebhookRateSnapshot returns the rate snapshot of a webhook: its PaymentRate, as of
+// the update it reports, if provided, otherwise the rate derived from the amounts
+func NewWebhookRateSnapshot(payload *WebhookPayload) RateSnapshot {
+	snapshot := RateSnapshot{
+		InvoiceID:    payload.InvoiceID,
+		OrderID:      payload.OrderID,
+		FiatCurrency: payload.FiatCurrency,
+		Currency:     payload.Currency,
+		FiatAmount:   payload.FiatAmount,
+		CryptoAmount: payload.CryptoAmount,
+		QuotedAt:     payload.CreatedAt,
+	}
+	switch {
+	case payload.PaymentRate > 0:
+		snapshot.Rate = payload.PaymentRate
+		snapshot.QuotedAt = payload.UpdatedAt
+	case payload.CryptoAmount > 0:
+		snapshot.Rate = payload.FiatAmount / payload.CryptoAmount
+		snapshot.Derived = true
+	}
+	return snapshot
+}
+
+// MemoryRateStore is an in-memory RateStore, useful for tests and short-lived processes.
+// It keeps every snapshot of an invoice, so the one recorded at creation survives the
+// webhook's.
+type MemoryRateStore struct {
+	mu        sync.RWMutex
+	snapshots map[string][]RateSnapshot
+}
+
+// NewMemoryRateStore creates an empty MemoryRateStore
+func NewMemoryRateStore() *MemoryRateStore {
+	return &MemoryRateStore{snapshots: make(map[string][]RateSnapshot)}
+}
+
+// SaveRateSnapshot appends the snapshot to those of its invoice
+func (s *MemoryRateStore) SaveRateSnapshot(_ context.Context, snapshot RateSnapshot) error {
+	s.mu.Lock()
+	s.snapshots[snapshot.InvoiceID] = append(s.snapshots[snapshot.InvoiceID], snapshot)
+	s.mu.Unlock()
+	return nil
+}
+
+// RateSnapshot returns the latest snapshot recorded for an invoice
+func (s *MemoryRateStore) RateSnapshot(invoiceID string) (RateSnapshot, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	snapshots := s.snapshots[invoiceID]
+	if len(snapshots) == 0 {
+		return RateSnapshot{}, false
+	}
+	return snapshots[len(snapshots)-1], true
+}
+
+// RateSnapshots returns every snapshot recorded for an invoice, oldest first
+func (s *MemoryRateStore) RateSnapshots(invoiceID string) []RateSnapshot {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return append([]RateSnapshot(nil), s.snapshots[invoiceID]...)
+}
+
+// WriterRateStore appends snapshots as JSON lines to a writer, e.g. an append-only audit file
+type WriterRateStore struct {
+	mu  sync.Mutex
+	enc *json.Encoder
+}
+
+// NewWriterRateStore creates a WriterRateStore writing to w
+func NewWriterRateStore(w io.Writer) *WriterRateStore {
+	return &WriterRateStore{enc: json.NewEncoder(w)}
+}
+
+// SaveRateSnapshot writes the snapshot as a single JSON line
+func (s *WriterRateStore) SaveRateSnapshot(_ context.Context, snapshot RateSnapshot) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.enc.Encode(snapshot)
+}
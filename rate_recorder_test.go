@@ -0,0 +1,42 @@
+package itispay_test
+
+import (
+	"context"
+	"testing"
+
+	itispay "github.com/ItIsPay/go-client"
+)
+
+func TestMemoryRateStoreKeepsEverySnapshot(t *testing.T) {
+	store := itispay.NewMemoryRateStore()
+	recorder := itispay.NewRateRecorder(store)
+	ctx := context.Background()
+
+	invoice := &itispay.Invoice{InvoiceID: "inv-1", FiatAmount: 100, CryptoAmount: 0.002}
+	if err := recorder.Record(ctx, invoice); err != nil {
+		t.Fatal(err)
+	}
+	payload := &itispay.WebhookPayload{InvoiceID: "inv-1", FiatAmount: 100, CryptoAmount: 0.002, PaymentRate: 50100}
+	if err := recorder.RecordWebhook(ctx, payload); err != nil {
+		t.Fatal(err)
+	}
+
+	snapshots := store.RateSnapshots("inv-1")
+	if len(snapshots) != 2 {
+		t.Fatalf("%d snapshots, want the creation and the webhook ones", len(snapshots))
+	}
+	if !snapshots[0].Derived || snapshots[0].Rate != 50000 {
+		t.Errorf("creation snapshot = %+v, want the derived rate", snapshots[0])
+	}
+	if snapshots[1].Derived || snapshots[1].Rate != 50100 {
+		t.Errorf("webhook snapshot = %+v, want the payment rate", snapshots[1])
+	}
+	if latest, ok := store.RateSnapshot("inv-1"); !ok || latest.Rate != 50100 {
+		t.Errorf("RateSnapshot = %+v, %t, want the webhook snapshot", latest, ok)
+	}
+	for _, snapshot := range snapshots {
+		if snapshot.RecordedAt.IsZero() {
+			t.Errorf("snapshot %+v not stamped", snapshot)
+		}
+	}
+}
@@ -0,0 +1,79 @@
+package itispay
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+)
+
+// Default read-back policy
+const (
+	DefaultReadBackAttempts = 5
+	DefaultReadBackInterval = 200 * time.Millisecond
+)
+
+// ErrReadBackTimeout is returned when a mutation succeeded but its effect did not become
+// visible to reads within the read-back attempts. The mutation result is still returned.
+var ErrReadBackTimeout = errors.New("itispay: change not visible after read-back attempts")
+
+// readBackPolicy controls read-back verification of mutations
+type readBackPolicy struct {
+	attempts int
+	interval time.Duration
+}
+
+// WithReadBack re-fetches the resource after CreateInvoice or UpdateInvoiceStatus until
+// the change is visible, protecting callers from eventually consistent reads. It uses
+// DefaultReadBackAttempts attempts, backing off from DefaultReadBackInterval.
+func WithReadBack() RequestOption {
+	return WithReadBackPolicy(DefaultReadBackAttempts, DefaultReadBackInterval)
+}
+
+// WithReadBackPolicy is WithReadBack with a custom number of attempts and initial
+// interval; the interval doubles after each attempt
+func WithReadBackPolicy(attempts int, interval time.Duration) RequestOption {
+	return func(o *requestOptions) {
+		o.readBack = &readBackPolicy{attempts: attempts, interval: interval}
+	}
+}
+
+// readBackInvoice polls GetInvoice until visible reports true for the fetched invoice.
+// The reads use opts, the options of the mutation, e.g. its timeout and API key.
+func (c *Client) readBackInvoice(ctx context.Context, policy *readBackPolicy, invoiceID string, visible func(*Invoice) bool, opts []RequestOption) error {
+	opts = readBackOptions(opts)
+	interval := policy.interval
+	for attempt := 0; attempt < policy.attempts; attempt++ {
+		if attempt > 0 {
+			if err := sleep(ctx, c.clock, interval); err != nil {
+				return err
+			}
+			interval *= 2
+		}
+
+		invoice, err := c.GetInvoice(ctx, invoiceID, opts...)
+		if err != nil {
+			var apiErr *APIError
+			if errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusNotFound {
+				continue
+			}
+			return err
+		}
+		if visible(invoice) {
+			return nil
+		}
+	}
+	return ErrReadBackTimeout
+}
+
+// readBackOptions returns the options of a mutation for its read-back GETs, without
+// those that only apply to the mutation. The response cache is bypassed, as it would
+// keep serving a stale first read.
+func readBackOptions(opts []RequestOption) []RequestOption {
+	return append(opts[:len(opts):len(opts)], func(o *requestOptions) {
+		o.readBack = nil
+		o.dryRun = false
+		o.meta = nil
+		o.headers.Del(IdempotencyKeyHeader)
+	}, WithCacheRefresh())
+}
@@ -0,0 +1,36 @@
+package itispay
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+// ErrReadOnlyClient is returned by calls that would modify data on a client created
+// with WithReadOnly
+var ErrReadOnlyClient = errors.New("itispay: client is read-only")
+
+// readOnlyAllowed lists the non-GET endpoints that do not modify payments or settings
+var readOnlyAllowed = map[string]bool{
+	"POST /events/ack": true,
+}
+
+// WithReadOnly blocks every call that creates or modifies data before it is sent,
+// returning ErrReadOnlyClient. Use it for reporting and analytics services so they
+// cannot move money even if configured with a key that could.
+func WithReadOnly() Option {
+	return func(c *Client) {
+		c.readOnly = true
+	}
+}
+
+// createInvoiceEndpoint is the endpoint label of invoice creation
+const createInvoiceEndpoint = "POST /invoices"
+
+// checkReadOnly rejects mutating requests on a read-only client
+func (c *Client) checkReadOnly(method, endpoint string) error {
+	if !c.readOnly || method == http.MethodGet || method == http.MethodHead || readOnlyAllowed[endpoint] {
+		return nil
+	}
+	return fmt.Errorf("%w: %s", ErrReadOnlyClient, endpoint)
+}
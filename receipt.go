@@ -0,0 +1,152 @@
+package itispay
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// ReceiptSource tells where the receipt written by GetInvoiceReceipt comes from
+type ReceiptSource string
+
+// Receipt sources
+const (
+	// ReceiptDownloaded is a receipt issued by the API
+	ReceiptDownloaded ReceiptSource = "downloaded"
+	// ReceiptRendered is a receipt rendered locally from the invoice by RenderReceipt
+	ReceiptRendered ReceiptSource = "rendered"
+)
+
+// GetInvoiceReceipt writes the PDF receipt of an invoice to w, e.g. for customers who need
+// documentation for accounting. If the API does not issue receipts, one is rendered locally
+// from the invoice data with RenderReceipt. Nothing is written to w if an error occurs
+// before the receipt is complete.
+func (c *Client) GetInvoiceReceipt(ctx context.Context, invoiceID string, w io.Writer, opts ...RequestOption) (ReceiptSource, error) {
+	path := "/invoices/" + url.PathEscape(invoiceID) + "/receipt"
+	receiptOpts := append(opts[:len(opts):len(opts)], WithHeader("Accept", "application/pdf"))
+	resp, err := c.doRequest(ctx, "GET", path, nil, receiptOpts...)
+	if err == nil {
+		if _, err := w.Write(resp.body); err != nil {
+			return "", fmt.Errorf("failed to write receipt: %w", err)
+		}
+		return ReceiptDownloaded, nil
+	}
+	if statusCode, ok := errorStatusCode(err); !ok || !isBatchUnsupported(statusCode) {
+		return "", err
+	}
+
+	invoice, err := c.GetInvoice(ctx, invoiceID, opts...)
+	if err != nil {
+		return "", err
+	}
+	if err := RenderReceipt(w, invoice); err != nil {
+		return "", err
+	}
+	return ReceiptRendered, nil
+}
+
+// RenderReceipt writes a single-page PDF receipt for invoice to w. Characters outside
+// Latin-1 are replaced with "?".
+func RenderReceipt(w io.Writer, invoice *Invoice) error {
+	lines := []string{
+		"Invoice: " + invoice.InvoiceID,
+		"Order: " + invoice.OrderID,
+	}
+	if invoice.OrderName != "" {
+		lines = append(lines, "Description: "+invoice.OrderName)
+	}
+	lines = append(lines,
+		"Status: "+string(invoice.Status),
+		"Created: "+receiptTime(invoice.CreatedAt),
+		"Updated: "+receiptTime(invoice.UpdatedAt),
+		"",
+	)
+	if invoice.FiatCurrency != "" {
+		lines = append(lines, "Amount: "+receiptAmount(invoice.FiatAmount)+" "+invoice.FiatCurrency)
+	}
+	lines = append(lines,
+		"Amount due: "+receiptAmount(invoice.CryptoAmount)+" "+invoice.Currency,
+		"Amount paid: "+receiptAmount(invoice.ActualCryptoAmountPaid)+" "+invoice.Currency,
+	)
+	if invoice.BlockchainDetails != nil && invoice.BlockchainDetails.BlockchainAddress != "" {
+		lines = append(lines, "Address: "+invoice.BlockchainDetails.BlockchainAddress)
+	}
+	if len(invoice.Transactions) > 0 {
+		lines = append(lines, "", "Transactions:")
+		for _, tx := range invoice.Transactions {
+			lines = append(lines, "  "+tx.TxHash+"  "+receiptAmount(tx.Amount)+" "+invoice.Currency)
+		}
+	}
+	if invoice.TestMode {
+		lines = append(lines, "", "TEST MODE - not a real payment")
+	}
+
+	var content bytes.Buffer
+	content.WriteString("BT\n/F2 18 Tf\n50 780 Td\n(Payment Receipt) Tj\n/F1 10 Tf\n14 TL\n0 -20 Td\n")
+	for _, line := range lines {
+		fmt.Fprintf(&content, "(%s) '\n", pdfString(line))
+	}
+	content.WriteString("ET\n")
+
+	objects := []string{
+		"<< /Type /Catalog /Pages 2 0 R >>",
+		"<< /Type /Pages /Kids [3 0 R] /Count 1 >>",
+		"<< /Type /Page /Parent 2 0 R /MediaBox [0 0 595 842] /Resources << /Font << /F1 4 0 R /F2 5 0 R >> >> /Contents 6 0 R >>",
+		"<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica /Encoding /WinAnsiEncoding >>",
+		"<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica-Bold /Encoding /WinAnsiEncoding >>",
+		"<< /Length " + strconv.Itoa(content.Len()) + " >>\nstream\n" + content.String() + "endstream",
+	}
+
+	var pdf bytes.Buffer
+	pdf.WriteString("%PDF-1.4\n")
+	offsets := make([]int, len(objects))
+	for i, object := range objects {
+		offsets[i] = pdf.Len()
+		fmt.Fprintf(&pdf, "%d 0 obj\n%s\nendobj\n", i+1, object)
+	}
+	xref := pdf.Len()
+	fmt.Fprintf(&pdf, "xref\n0 %d\n0000000000 65535 f \n", len(objects)+1)
+	for _, offset := range offsets {
+		fmt.Fprintf(&pdf, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(&pdf, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(objects)+1, xref)
+
+	if _, err := w.Write(pdf.Bytes()); err != nil {
+		return fmt.Errorf("failed to write receipt: %w", err)
+	}
+	return nil
+}
+
+// pdfString escapes s for a PDF literal string in WinAnsi encoding
+func pdfString(s string) string {
+	var b bytes.Buffer
+	for _, r := range s {
+		switch {
+		case r == '(' || r == ')' || r == '\\':
+			b.WriteByte('\\')
+			b.WriteRune(r)
+		case r < 0x20 || r > 0xff:
+			b.WriteByte('?')
+		default:
+			b.WriteByte(byte(r))
+		}
+	}
+	return b.String()
+}
+
+// receiptAmount formats an amount without trailing zeros
+func receiptAmount(amount float64) string {
+	return strconv.FormatFloat(amount, 'f', -1, 64)
+}
+
+// receiptTime formats a timestamp in UTC, empty if unset
+func receiptTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.UTC().Format("2006-01-02 15:04:05 UTC")
+}
@@ -0,0 +1,199 @@
+// Package reconcile matches expected orders against ItIsPay invoices and reports
+// missing, unpaid, underpaid and overpaid orders.
+package reconcile
+
+import (
+	"context"
+	"math"
+	"sort"
+
+	itispay "github.com/ItIsPay/go-client"
+)
+
+// Result is the reconciliation outcome of an order
+type Result string
+
+// Reconciliation results
+const (
+	// ResultMatched means the order was paid within the invoice's allowed error
+	ResultMatched Result = "matched"
+	// ResultUnderpaid means less than the allowed minimum was paid
+	ResultUnderpaid Result = "underpaid"
+	// ResultOverpaid means more than the allowed maximum was paid
+	ResultOverpaid Result = "overpaid"
+	// ResultUnpaid means an invoice exists but nothing was paid
+	ResultUnpaid Result = "unpaid"
+	// ResultMissing means no invoice exists for the order
+	ResultMissing Result = "missing"
+	// ResultAmountMismatch means the invoice amount differs from the expected amount
+	ResultAmountMismatch Result = "amount_mismatch"
+)
+
+// amountEpsilon absorbs floating point noise when comparing amounts
+const amountEpsilon = 1e-9
+
+// ExpectedOrder is an order the merchant expects to be paid. Amount is in the invoice's
+// fiat currency for fiat-priced invoices, and in crypto otherwise.
+type ExpectedOrder struct {
+	OrderID string
+	Amount  float64
+}
+
+// Entry is the reconciliation of one expected order
+type Entry struct {
+	OrderID string
+	// Expected is the expected order amount
+	Expected float64
+	Result   Result
+	// Invoice is the invoice the order was reconciled against: the one with the largest
+	// payment when the order has several. It is nil for ResultMissing.
+	Invoice *itispay.Invoice
+	// Invoices are all invoices found for the order
+	Invoices []itispay.Invoice
+	// PaidInvoices is the number of invoices of the order that received a payment. More
+	// than one means the order was paid in parts, or twice.
+	PaidInvoices int
+	// PaidRatio is the amount paid across the order's invoices relative to the invoiced
+	// crypto amount (1 = exact)
+	PaidRatio float64
+	// Difference is the paid minus the invoiced crypto amount, in the currency of Invoice
+	Difference float64
+}
+
+// ReconciliationReport is the result of a reconciliation run
+type ReconciliationReport struct {
+	Range   itispay.TimeRange
+	Entries []Entry
+	// Unexpected are invoices in the range that match no expected order
+	Unexpected []itispay.Invoice
+	// Summary counts entries per result
+	Summary map[Result]int
+}
+
+// Problems returns the entries that are not ResultMatched
+func (r *ReconciliationReport) Problems() []Entry {
+	var problems []Entry
+	for _, entry := range r.Entries {
+		if entry.Result != ResultMatched {
+			problems = append(problems, entry)
+		}
+	}
+	return problems
+}
+
+// Reconcile pulls all invoices created within r and matches them to the expected orders
+// by OrderID
+func Reconcile(ctx context.Context, client *itispay.Client, expected []ExpectedOrder, r itispay.TimeRange, opts ...itispay.RequestOption) (*ReconciliationReport, error) {
+	var invoices []itispay.Invoice
+	pager := client.NewInvoicePager(r.Apply(itispay.ListInvoicesParams{}), opts...)
+	for pager.Next(ctx) {
+		invoices = append(invoices, *pager.Invoice())
+	}
+	if err := pager.Err(); err != nil {
+		return nil, err
+	}
+
+	report := Match(expected, invoices)
+	report.Range = r
+	return report, nil
+}
+
+// Match reconciles expected orders against already fetched invoices
+func Match(expected []ExpectedOrder, invoices []itispay.Invoice) *ReconciliationReport {
+	byOrder := make(map[string][]itispay.Invoice)
+	for _, invoice := range invoices {
+		byOrder[invoice.OrderID] = append(byOrder[invoice.OrderID], invoice)
+	}
+
+	report := &ReconciliationReport{Summary: make(map[Result]int)}
+	seen := make(map[string]bool, len(expected))
+	for _, order := range expected {
+		seen[order.OrderID] = true
+		entry := reconcileOrder(order, byOrder[order.OrderID])
+		report.Entries = append(report.Entries, entry)
+		report.Summary[entry.Result]++
+	}
+
+	for _, invoice := range invoices {
+		if !seen[invoice.OrderID] {
+			report.Unexpected = append(report.Unexpected, invoice)
+		}
+	}
+	sort.SliceStable(report.Unexpected, func(i, j int) bool {
+		return report.Unexpected[i].CreatedAt.Before(report.Unexpected[j].CreatedAt)
+	})
+
+	return report
+}
+
+// reconcileOrder classifies an order given its invoices. A buyer may pay an order
+// across several invoices, e.g. partly before one expired and the rest on its reissue,
+// so the payments of all its invoices count towards the order.
+func reconcileOrder(order ExpectedOrder, invoices []itispay.Invoice) Entry {
+	entry := Entry{OrderID: order.OrderID, Expected: order.Amount, Invoices: invoices}
+	if len(invoices) == 0 {
+		entry.Result = ResultMissing
+		return entry
+	}
+
+	primary := &invoices[0]
+	for i := range invoices[1:] {
+		if paidRatio(&invoices[i+1]) > paidRatio(primary) {
+			primary = &invoices[i+1]
+		}
+	}
+	entry.Invoice = primary
+	// Invoices may be in different currencies, so payments add up as ratios
+	for i := range invoices {
+		entry.PaidRatio += paidRatio(&invoices[i])
+		if invoices[i].ActualCryptoAmountPaid > 0 {
+			entry.PaidInvoices++
+		}
+	}
+	invoicedCrypto := cryptoAmount(primary)
+	entry.Difference = (entry.PaidRatio - 1) * invoicedCrypto
+
+	invoiced := invoicedCrypto
+	if primary.FiatAmount > 0 {
+		invoiced = primary.FiatAmount
+	}
+	tolerance := float64(primary.AllowedErrorPercent) / 100
+
+	switch {
+	case math.Abs(invoiced-order.Amount) > amountEpsilon*math.Max(1, math.Abs(order.Amount)):
+		entry.Result = ResultAmountMismatch
+	case entry.PaidInvoices == 0:
+		entry.Result = ResultUnpaid
+	case entry.PaidRatio < 1-tolerance-amountEpsilon:
+		entry.Result = ResultUnderpaid
+	case entry.PaidRatio > 1+tolerance+amountEpsilon:
+		entry.Result = ResultOverpaid
+	default:
+		entry.Result = ResultMatched
+	}
+	return entry
+}
+
+// cryptoAmount returns the crypto amount an invoice asks for. A multi-currency invoice
+// has none until the buyer picks a currency, then asks for that option's amount.
+func cryptoAmount(invoice *itispay.Invoice) float64 {
+	if invoice.CryptoAmount > 0 {
+		return invoice.CryptoAmount
+	}
+	if invoice.Currency == "" {
+		return 0
+	}
+	if option, ok := invoice.PaymentOption(invoice.Currency); ok {
+		return option.CryptoAmount
+	}
+	return 0
+}
+
+// paidRatio returns the amount paid to an invoice relative to its crypto amount
+func paidRatio(invoice *itispay.Invoice) float64 {
+	amount := cryptoAmount(invoice)
+	if amount <= 0 {
+		return 0
+	}
+	return invoice.ActualCryptoAmountPaid / amount
+}
@@ -0,0 +1,71 @@
+package reconcile_test
+
+import (
+	"testing"
+
+	itispay "github.com/ItIsPay/go-client"
+	"github.com/ItIsPay/go-client/reconcile"
+)
+
+func TestMatch(t *testing.T) {
+	invoice := func(id string, cryptoAmount, paid float64) itispay.Invoice {
+		return itispay.Invoice{
+			InvoiceID:              id,
+			OrderID:                "ORDER-1",
+			FiatAmount:             100,
+			FiatCurrency:           "EUR",
+			Currency:               "BTC",
+			CryptoAmount:           cryptoAmount,
+			ActualCryptoAmountPaid: paid,
+		}
+	}
+	multiCurrency := func(currency string, paid float64) itispay.Invoice {
+		inv := invoice("inv-multi", 0, paid)
+		inv.Currency = currency
+		inv.PaymentOptions = []itispay.PaymentOption{
+			{Currency: "BTC", CryptoAmount: 0.002},
+			{Currency: "ETH", CryptoAmount: 0.04},
+		}
+		return inv
+	}
+
+	tests := []struct {
+		name         string
+		invoices     []itispay.Invoice
+		want         reconcile.Result
+		paidInvoices int
+	}{
+		{name: "missing", want: reconcile.ResultMissing},
+		{name: "paid", invoices: []itispay.Invoice{invoice("inv-1", 0.002, 0.002)}, want: reconcile.ResultMatched, paidInvoices: 1},
+		{name: "unpaid", invoices: []itispay.Invoice{invoice("inv-1", 0.002, 0)}, want: reconcile.ResultUnpaid},
+		{name: "underpaid", invoices: []itispay.Invoice{invoice("inv-1", 0.002, 0.001)}, want: reconcile.ResultUnderpaid, paidInvoices: 1},
+		{
+			name:         "paid across two invoices",
+			invoices:     []itispay.Invoice{invoice("inv-1", 0.002, 0.001), invoice("inv-2", 0.0025, 0.00125)},
+			want:         reconcile.ResultMatched,
+			paidInvoices: 2,
+		},
+		{
+			name:         "paid twice",
+			invoices:     []itispay.Invoice{invoice("inv-1", 0.002, 0.002), invoice("inv-2", 0.002, 0.002)},
+			want:         reconcile.ResultOverpaid,
+			paidInvoices: 2,
+		},
+		{name: "multi-currency paid", invoices: []itispay.Invoice{multiCurrency("ETH", 0.04)}, want: reconcile.ResultMatched, paidInvoices: 1},
+		{name: "multi-currency underpaid", invoices: []itispay.Invoice{multiCurrency("ETH", 0.02)}, want: reconcile.ResultUnderpaid, paidInvoices: 1},
+		{name: "multi-currency unpaid", invoices: []itispay.Invoice{multiCurrency("", 0)}, want: reconcile.ResultUnpaid},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			report := reconcile.Match([]reconcile.ExpectedOrder{{OrderID: "ORDER-1", Amount: 100}}, tt.invoices)
+			entry := report.Entries[0]
+			if entry.Result != tt.want {
+				t.Errorf("result = %s (paid ratio %v), want %s", entry.Result, entry.PaidRatio, tt.want)
+			}
+			if entry.PaidInvoices != tt.paidInvoices {
+				t.Errorf("paid invoices = %d, want %d", entry.PaidInvoices, tt.paidInvoices)
+			}
+		})
+	}
+}
@@ -0,0 +1,113 @@
+package itispay
+
+import (
+	"context"
+	"net/url"
+	"time"
+)
+
+// Refund address status constants
+const (
+	RefundAddressStatusUnverified = "unverified"
+	RefundAddressStatusPending    = "pending_verification"
+	RefundAddressStatusVerified   = "verified"
+	RefundAddressStatusRejected   = "rejected"
+)
+
+// Refund address verification method constants
+const (
+	// VerificationMethodMicroDeposit sends a small random amount to the address which
+	// the customer confirms
+	VerificationMethodMicroDeposit = "micro_deposit"
+	// VerificationMethodSignedMessage asks the customer to sign a provider-issued message
+	// with the address' private key
+	VerificationMethodSignedMessage = "signed_message"
+)
+
+// SaveRefundAddressRequest represents the request to save a refund address for a customer
+type SaveRefundAddressRequest struct {
+	Currency string `json:"currency"`
+	Network  string `json:"network,omitempty"`
+	Address  string `json:"address"`
+	Label    string `json:"label,omitempty"`
+}
+
+// RefundAddress represents a refund destination stored in a customer's address book
+type RefundAddress struct {
+	ID                 string     `json:"id"`
+	CustomerID         string     `json:"customer_id"`
+	Currency           string     `json:"currency"`
+	Network            string     `json:"network,omitempty"`
+	Address            string     `json:"address"`
+	Label              string     `json:"label,omitempty"`
+	Status             string     `json:"status"`
+	VerificationMethod string     `json:"verification_method,omitempty"`
+	CreatedAt          time.Time  `json:"created_at"`
+	VerifiedAt         *time.Time `json:"verified_at,omitempty"`
+}
+
+// ListRefundAddressesResponse represents the response from listing refund addresses
+type ListRefundAddressesResponse struct {
+	Items []RefundAddress `json:"items"`
+}
+
+// StartRefundAddressVerificationRequest represents the request to start verifying a refund address
+type StartRefundAddressVerificationRequest struct {
+	Method string `json:"method"`
+}
+
+// RefundAddressVerification represents an in-progress refund address verification
+type RefundAddressVerification struct {
+	AddressID string `json:"address_id"`
+	Method    string `json:"method"`
+	Status    string `json:"status"`
+	// Message is the text the customer must sign (signed_message method only)
+	Message string `json:"message,omitempty"`
+	// DepositTxHash is the transaction carrying the micro-deposit (micro_deposit method only)
+	DepositTxHash string    `json:"deposit_tx_hash,omitempty"`
+	ExpiresAt     time.Time `json:"expires_at"`
+}
+
+// ConfirmRefundAddressVerificationRequest represents the proof completing a verification.
+// Set Signature for the signed_message method, or DepositAmount for the micro_deposit method.
+type ConfirmRefundAddressVerificationRequest struct {
+	Signature     string   `json:"signature,omitempty"`
+	DepositAmount *float64 `json:"deposit_amount,omitempty"`
+}
+
+// refundAddressesPath returns the refund address book path for a customer
+func refundAddressesPath(customerID string) string {
+	return "/customers/" + url.PathEscape(customerID) + "/refund-addresses"
+}
+
+// SaveRefundAddress adds a refund address to a customer's address book.
+// New addresses are unverified until a verification flow completes.
+func (c *Client) SaveRefundAddress(ctx context.Context, customerID string, req SaveRefundAddressRequest, opts ...RequestOption) (*RefundAddress, error) {
+	return do[RefundAddress](ctx, c, "POST", refundAddressesPath(customerID), req, opts...)
+}
+
+// ListRefundAddresses retrieves all refund addresses saved for a customer
+func (c *Client) ListRefundAddresses(ctx context.Context, customerID string, opts ...RequestOption) (*ListRefundAddressesResponse, error) {
+	return do[ListRefundAddressesResponse](ctx, c, "GET", refundAddressesPath(customerID), nil, opts...)
+}
+
+// DeleteRefundAddress removes a refund address from a customer's address book
+func (c *Client) DeleteRefundAddress(ctx context.Context, customerID, addressID string, opts ...RequestOption) error {
+	_, err := c.doRequest(ctx, "DELETE", refundAddressesPath(customerID)+"/"+url.PathEscape(addressID), nil, opts...)
+	return err
+}
+
+// StartRefundAddressVerification starts verifying a refund address using the given method
+// (VerificationMethodMicroDeposit or VerificationMethodSignedMessage)
+func (c *Client) StartRefundAddressVerification(ctx context.Context, customerID, addressID, method string, opts ...RequestOption) (*RefundAddressVerification, error) {
+	req := StartRefundAddressVerificationRequest{Method: method}
+	path := refundAddressesPath(customerID) + "/" + url.PathEscape(addressID) + "/verification"
+	return do[RefundAddressVerification](ctx, c, "POST", path, req, opts...)
+}
+
+// ConfirmRefundAddressVerification completes a verification with the customer's proof
+// and returns the updated refund address
+func (c *Client) ConfirmRefundAddressVerification(ctx context.Context, customerID, addressID string, req ConfirmRefundAddressVerificationRequest, opts ...RequestOption) (*RefundAddress, error) {
+	path := refundAddressesPath(customerID) + "/" + url.PathEscape(addressID) + "/verification/confirm"
+	return do[RefundAddress](ctx, c, "POST", path, req, opts...)
+}
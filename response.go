@@ -0,0 +1,36 @@
+package itispay
+
+import (
+	"encoding/json"
+	"net/http"
+)
+
+// Response is the raw HTTP response of an API call, returned by the WithResponse
+// variants of the client methods. It gives access to fields the client does not know
+// about yet.
+type Response struct {
+	StatusCode int
+	Header     http.Header
+	// Body is the undecoded JSON response body
+	Body []byte
+
+	codec Codec
+}
+
+// Decode unmarshals the response body into v, e.g. a struct holding new fields
+func (r *Response) Decode(v interface{}) error {
+	if r.codec != nil {
+		return r.codec.Unmarshal(r.Body, v)
+	}
+	return json.Unmarshal(r.Body, v)
+}
+
+// response returns the raw view of a successful API response
+func (r *apiResponse) response() *Response {
+	return &Response{
+		StatusCode: r.statusCode,
+		Header:     r.header,
+		Body:       r.body,
+		codec:      r.codec,
+	}
+}
@@ -0,0 +1,157 @@
+package itispay
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ResponseCache stores API response bodies for WithResponseCache. Implement it on
+// Redis or memcached to share cached responses between instances.
+type ResponseCache interface {
+	// Get returns the body stored under key, ok false if there is none or it expired
+	Get(ctx context.Context, key string) (body []byte, ok bool, err error)
+	// Set stores body under key for ttl
+	Set(ctx context.Context, key string, body []byte, ttl time.Duration) error
+	// Delete removes the body stored under key, if any
+	Delete(ctx context.Context, key string) error
+}
+
+// WithResponseCache serves GET requests from cache for the endpoints listed in ttls,
+// keyed by path and query parameters. ttls maps endpoint labels such as
+// "GET /invoices/{id}" to how long their responses stay fresh. A successful request
+// modifying a resource, e.g. UpdateInvoiceStatus, drops its cached GET response; cached
+// listings are only refreshed when their TTL expires. Cache
+// errors are ignored and the API is called instead. Entries are scoped to the API key.
+func WithResponseCache(cache ResponseCache, ttls map[string]time.Duration) Option {
+	return func(c *Client) {
+		copied := make(map[string]time.Duration, len(ttls))
+		for endpoint, ttl := range ttls {
+			copied[endpoint] = ttl
+		}
+		c.responseCache = &responseCache{cache: cache, ttls: copied}
+	}
+}
+
+// WithCacheRefresh skips the response cache for this call and stores the fresh response
+func WithCacheRefresh() RequestOption {
+	return func(o *requestOptions) {
+		o.cacheRefresh = true
+	}
+}
+
+// responseCache applies WithResponseCache
+type responseCache struct {
+	cache ResponseCache
+	ttls  map[string]time.Duration
+}
+
+// key returns the cache key of a GET path for apiKey
+func (rc *responseCache) key(apiKey, path string) string {
+	sum := sha256.Sum256([]byte(apiKey))
+	return hex.EncodeToString(sum[:8]) + " GET " + path
+}
+
+// get returns the cached response of a GET request, if any
+func (rc *responseCache) get(ctx context.Context, apiKey, endpoint, path string) (*apiResponse, bool) {
+	if rc.ttls[endpoint] <= 0 {
+		return nil, false
+	}
+	body, ok, err := rc.cache.Get(ctx, rc.key(apiKey, path))
+	if err != nil || !ok {
+		return nil, false
+	}
+	return &apiResponse{
+		endpoint:   endpoint,
+		statusCode: http.StatusOK,
+		header:     http.Header{},
+		body:       body,
+	}, true
+}
+
+// store caches the response of a GET request if its endpoint has a TTL
+func (rc *responseCache) store(ctx context.Context, apiKey, endpoint, path string, resp *apiResponse) {
+	if ttl := rc.ttls[endpoint]; ttl > 0 && resp.body != nil {
+		_ = rc.cache.Set(ctx, rc.key(apiKey, path), resp.body, ttl)
+	}
+}
+
+// invalidate drops the cached GET responses of a resource modified by a request and of
+// its parents, e.g. /invoices/{id} after POST /invoices/{id}/extend
+func (rc *responseCache) invalidate(ctx context.Context, apiKey, path string) {
+	if i := strings.IndexByte(path, '?'); i >= 0 {
+		path = path[:i]
+	}
+	for ; path != ""; path = path[:strings.LastIndexByte(path, '/')] {
+		if rc.ttls[endpointLabel(http.MethodGet, path)] > 0 {
+			_ = rc.cache.Delete(ctx, rc.key(apiKey, path))
+		}
+	}
+}
+
+// MemoryResponseCache is an in-process ResponseCache
+type MemoryResponseCache struct {
+	// Clock tells the time for expiring entries, SystemClock if nil
+	Clock Clock
+
+	mu        sync.Mutex
+	entries   map[string]memoryCacheEntry
+	nextPrune int
+}
+
+// memoryCacheEntry is a cached body and its expiry
+type memoryCacheEntry struct {
+	body      []byte
+	expiresAt time.Time
+}
+
+// NewMemoryResponseCache returns an empty in-memory response cache
+func NewMemoryResponseCache() *MemoryResponseCache {
+	return &MemoryResponseCache{entries: make(map[string]memoryCacheEntry)}
+}
+
+// Get implements ResponseCache
+func (m *MemoryResponseCache) Get(ctx context.Context, key string) ([]byte, bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	e, ok := m.entries[key]
+	if !ok {
+		return nil, false, nil
+	}
+	if !clockOrSystem(m.Clock).Now().Before(e.expiresAt) {
+		delete(m.entries, key)
+		return nil, false, nil
+	}
+	return e.body, true, nil
+}
+
+// Set implements ResponseCache
+func (m *MemoryResponseCache) Set(ctx context.Context, key string, body []byte, ttl time.Duration) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := clockOrSystem(m.Clock).Now()
+	if len(m.entries) >= m.nextPrune {
+		// Drop expired entries whenever the cache has doubled since the last sweep
+		for k, e := range m.entries {
+			if !now.Before(e.expiresAt) {
+				delete(m.entries, k)
+			}
+		}
+		m.nextPrune = max(2*len(m.entries), 1024)
+	}
+	m.entries[key] = memoryCacheEntry{body: body, expiresAt: now.Add(ttl)}
+	return nil
+}
+
+// Delete implements ResponseCache
+func (m *MemoryResponseCache) Delete(ctx context.Context, key string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.entries, key)
+	return nil
+}
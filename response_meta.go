@@ -0,0 +1,81 @@
+package itispay
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// Response metadata headers
+const (
+	RequestIDHeader          = "X-Request-ID"
+	RateLimitLimitHeader     = "X-RateLimit-Limit"
+	RateLimitRemainingHeader = "X-RateLimit-Remaining"
+	// RateLimitResetHeader is the Unix time at which the rate limit window resets
+	RateLimitResetHeader = "X-RateLimit-Reset"
+)
+
+// ResponseMeta describes the HTTP response of an API call, filled in by WithResponseMeta
+type ResponseMeta struct {
+	StatusCode int
+	// RequestID identifies the request in the API's logs; quote it to support
+	RequestID string
+	// APIVersion is the version of the API that served the request
+	APIVersion string
+	// ServerTime is the Date of the response, zero if absent
+	ServerTime time.Time
+	RateLimit  RateLimit
+	// Cached is set when the response was served by WithResponseCache, in which case
+	// only StatusCode is filled in
+	Cached bool
+	Header http.Header
+}
+
+// RateLimit is the rate limit state reported by the API; fields are zero if absent
+type RateLimit struct {
+	Limit     int
+	Remaining int
+	Reset     time.Time
+}
+
+// WithResponseMeta fills meta with the response metadata of this call, e.g. the request
+// ID and rate limit counters, without switching to the WithResponse variants. It is
+// filled for error responses too, and reflects the last attempt if the call was retried.
+//
+//	var meta itispay.ResponseMeta
+//	invoice, err := client.CreateInvoice(ctx, req, itispay.WithResponseMeta(&meta))
+//	log.Printf("request %s, %d calls left", meta.RequestID, meta.RateLimit.Remaining)
+func WithResponseMeta(meta *ResponseMeta) RequestOption {
+	return func(o *requestOptions) {
+		o.meta = meta
+	}
+}
+
+// recordMeta fills the ResponseMeta requested with WithResponseMeta, if any
+func (o *requestOptions) recordMeta(statusCode int, header http.Header) {
+	if o.meta == nil {
+		return
+	}
+	*o.meta = ResponseMeta{
+		StatusCode: statusCode,
+		RequestID:  header.Get(RequestIDHeader),
+		APIVersion: header.Get(apiVersionHeader),
+		RateLimit: RateLimit{
+			Limit:     headerInt(header, RateLimitLimitHeader),
+			Remaining: headerInt(header, RateLimitRemainingHeader),
+		},
+		Header: header,
+	}
+	if date, err := http.ParseTime(header.Get("Date")); err == nil {
+		o.meta.ServerTime = date
+	}
+	if reset := headerInt(header, RateLimitResetHeader); reset > 0 {
+		o.meta.RateLimit.Reset = time.Unix(int64(reset), 0)
+	}
+}
+
+// headerInt returns the integer value of a header, zero if absent or invalid
+func headerInt(header http.Header, name string) int {
+	n, _ := strconv.Atoi(header.Get(name))
+	return n
+}
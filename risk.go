@@ -0,0 +1,58 @@
+package itispay
+
+import (
+	"context"
+
+	"github.com/ItIsPay/go-client/types"
+)
+
+// DefaultHighRiskScore is the risk score from which IsHighRisk reports a payment as high
+// risk when no threshold is given
+const DefaultHighRiskScore = types.DefaultHighRiskScore
+
+// RiskFlag names a source of risk found in the funds paying an invoice
+type RiskFlag = types.RiskFlag
+
+// Risk flag constants
+const (
+	RiskFlagSanctions    = types.RiskFlagSanctions
+	RiskFlagMixer        = types.RiskFlagMixer
+	RiskFlagDarknet      = types.RiskFlagDarknet
+	RiskFlagStolenFunds  = types.RiskFlagStolenFunds
+	RiskFlagRansomware   = types.RiskFlagRansomware
+	RiskFlagScam         = types.RiskFlagScam
+	RiskFlagGambling     = types.RiskFlagGambling
+	RiskFlagHighRiskVASP = types.RiskFlagHighRiskVASP
+)
+
+// IsHighRisk reports whether the payment's risk score is at least threshold
+// (DefaultHighRiskScore if zero) or the funds are flagged for sanctions. Unscored
+// invoices are not high risk.
+func (i *Invoice) IsHighRisk(threshold float64) bool {
+	if threshold <= 0 {
+		threshold = DefaultHighRiskScore
+	}
+	for _, flag := range i.RiskFlags {
+		if flag == RiskFlagSanctions {
+			return true
+		}
+	}
+	return i.RiskScore != nil && *i.RiskScore >= threshold
+}
+
+// ListFlaggedInvoices returns the invoices matching params whose payment carries risk
+// flags or scored at least minScore (DefaultHighRiskScore if zero), e.g. for a daily
+// compliance review. It reads every page, so narrow params with a time range.
+func (c *Client) ListFlaggedInvoices(ctx context.Context, params ListInvoicesParams, minScore float64, opts ...RequestOption) ([]Invoice, error) {
+	var flagged []Invoice
+	err := c.ListInvoicesStream(ctx, params, func(invoice *Invoice) error {
+		if len(invoice.RiskFlags) > 0 || invoice.IsHighRisk(minScore) {
+			flagged = append(flagged, *invoice)
+		}
+		return nil
+	}, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return flagged, nil
+}
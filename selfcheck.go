@@ -0,0 +1,193 @@
+package itispay
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// keyExpiryWarning is how long before expiry SelfCheck warns about an expiring API key
+const keyExpiryWarning = 7 * 24 * time.Hour
+
+// Diagnostic severities
+const (
+	SeverityError   = "error"
+	SeverityWarning = "warning"
+)
+
+// KeyInfo describes the API key the client authenticates with
+type KeyInfo struct {
+	KeyID       string      `json:"key_id"`
+	Name        string      `json:"name"`
+	ProjectID   string      `json:"project_id"`
+	Environment Environment `json:"environment"`
+	Scopes      []string    `json:"scopes"`
+	ExpiresAt   *time.Time  `json:"expires_at,omitempty"`
+}
+
+// WebhookTestResult is the outcome of a test webhook delivery
+type WebhookTestResult struct {
+	URL        string `json:"url"`
+	Delivered  bool   `json:"delivered"`
+	StatusCode int    `json:"status_code"`
+	Error      string `json:"error,omitempty"`
+	LatencyMs  int64  `json:"latency_ms"`
+}
+
+// webhookTestRequest asks the API to deliver a test webhook
+type webhookTestRequest struct {
+	URL string `json:"url"`
+}
+
+// SelfCheckParams selects what SelfCheck verifies
+type SelfCheckParams struct {
+	// RequiredScopes are the scopes the integration needs
+	RequiredScopes []string
+	// WebhookURL, if set, receives a test delivery to confirm it is reachable
+	WebhookURL string
+}
+
+// Diagnostic is a problem found by SelfCheck with a hint on how to fix it
+type Diagnostic struct {
+	Check    string
+	Severity string
+	Message  string
+	Hint     string
+}
+
+// String formats the diagnostic for logs
+func (d Diagnostic) String() string {
+	s := fmt.Sprintf("%s: %s: %s", d.Severity, d.Check, d.Message)
+	if d.Hint != "" {
+		s += " (" + d.Hint + ")"
+	}
+	return s
+}
+
+// SelfCheckReport is the result of SelfCheck
+type SelfCheckReport struct {
+	// Key is the API key information, nil if the key was rejected
+	Key *KeyInfo
+	// Webhook is the test delivery result, nil if no WebhookURL was given
+	Webhook     *WebhookTestResult
+	Diagnostics []Diagnostic
+}
+
+// OK reports whether no errors were found; warnings are allowed
+func (r *SelfCheckReport) OK() bool {
+	for _, d := range r.Diagnostics {
+		if d.Severity == SeverityError {
+			return false
+		}
+	}
+	return true
+}
+
+// SelfCheckError is returned by SelfCheck when errors were found
+type SelfCheckError struct {
+	Report *SelfCheckReport
+}
+
+// Error returns the error message
+func (e *SelfCheckError) Error() string {
+	var problems []string
+	for _, d := range e.Report.Diagnostics {
+		if d.Severity == SeverityError {
+			problems = append(problems, d.String())
+		}
+	}
+	return "itispay: self-check failed: " + strings.Join(problems, "; ")
+}
+
+// SelfCheck verifies the client configuration at startup, turning misconfiguration into
+// an immediate error instead of failed payments later. It checks that the API key is
+// valid for the configured environment, has the required scopes and is not about to
+// expire, and that the webhook URL accepts a test delivery. The report is always
+// returned; the error is a *SelfCheckError if any check failed.
+func (c *Client) SelfCheck(ctx context.Context, params SelfCheckParams, opts ...RequestOption) (*SelfCheckReport, error) {
+	report := &SelfCheckReport{}
+	add := func(check, severity, message, hint string) {
+		report.Diagnostics = append(report.Diagnostics, Diagnostic{Check: check, Severity: severity, Message: message, Hint: hint})
+	}
+
+	key, err := c.GetKeyInfo(ctx, opts...)
+	var apiErr *APIError
+	switch {
+	case isUnauthorized(err):
+		add("api_key", SeverityError, "the API key was rejected",
+			fmt.Sprintf("check the key and that it belongs to the %s environment", c.environment))
+	case errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusForbidden:
+		add("api_key", SeverityError, "the API key may not read its own details", "grant the key read access to its details")
+	case err != nil:
+		add("connectivity", SeverityError, err.Error(), "check network access to "+c.BaseURL())
+	default:
+		report.Key = key
+		c.checkKey(key, params.RequiredScopes, add)
+	}
+
+	if params.WebhookURL != "" {
+		result, err := c.TestWebhook(ctx, params.WebhookURL, opts...)
+		switch {
+		case err != nil:
+			add("webhook", SeverityError, "test delivery could not be requested: "+err.Error(), "")
+		case !result.Delivered:
+			add("webhook", SeverityError, fmt.Sprintf("test delivery to %s failed: %s", result.URL, result.Error),
+				"make sure the URL is publicly reachable over HTTPS")
+		case result.StatusCode < 200 || result.StatusCode >= 300:
+			add("webhook", SeverityError, fmt.Sprintf("webhook endpoint answered HTTP %d", result.StatusCode),
+				"the handler must respond with 2xx to acknowledge deliveries")
+		}
+		report.Webhook = result
+	}
+
+	if !report.OK() {
+		return report, &SelfCheckError{Report: report}
+	}
+	return report, nil
+}
+
+// checkKey adds diagnostics about the key's environment, scopes and expiry
+func (c *Client) checkKey(key *KeyInfo, requiredScopes []string, add func(check, severity, message, hint string)) {
+	if key.Environment != "" && key.Environment != c.environment {
+		add("environment", SeverityError,
+			fmt.Sprintf("the API key is a %s key but the client targets %s", key.Environment, c.environment),
+			fmt.Sprintf("use WithEnvironment(%q) or a %s key", key.Environment, c.environment))
+	}
+
+	granted := make(map[string]bool, len(key.Scopes))
+	for _, scope := range key.Scopes {
+		granted[scope] = true
+	}
+	var missing []string
+	for _, scope := range requiredScopes {
+		if !granted[scope] {
+			missing = append(missing, scope)
+		}
+	}
+	if len(missing) > 0 {
+		add("scopes", SeverityError, "the API key lacks scopes: "+strings.Join(missing, ", "),
+			"add the scopes to the key in the dashboard")
+	}
+
+	if key.ExpiresAt != nil {
+		if remaining := key.ExpiresAt.Sub(c.clock.Now()); remaining <= 0 {
+			add("api_key", SeverityError, "the API key has expired", "create a new key")
+		} else if remaining < keyExpiryWarning {
+			add("api_key", SeverityWarning, fmt.Sprintf("the API key expires on %s", key.ExpiresAt.Format(time.RFC3339)),
+				"rotate the key before it expires")
+		}
+	}
+}
+
+// GetKeyInfo retrieves details of the API key the client authenticates with
+func (c *Client) GetKeyInfo(ctx context.Context, opts ...RequestOption) (*KeyInfo, error) {
+	return do[KeyInfo](ctx, c, "GET", "/auth/key", nil, opts...)
+}
+
+// TestWebhook asks the API to deliver a test webhook to url and reports the outcome
+func (c *Client) TestWebhook(ctx context.Context, url string, opts ...RequestOption) (*WebhookTestResult, error) {
+	return do[WebhookTestResult](ctx, c, "POST", "/webhooks/test", webhookTestRequest{URL: url}, opts...)
+}
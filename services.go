@@ -0,0 +1,64 @@
+package itispay
+
+import (
+	"context"
+	"io"
+)
+
+// InvoiceService is the invoice surface of the client. Hand it to code that only
+// creates and reads invoices, and mock it in that code's tests.
+type InvoiceService interface {
+	CreateInvoice(ctx context.Context, req CreateInvoiceRequest, opts ...RequestOption) (*Invoice, error)
+	CreateInvoices(ctx context.Context, reqs []CreateInvoiceRequest, opts ...RequestOption) (*BatchResult[CreateInvoiceRequest, *Invoice], error)
+	GetInvoice(ctx context.Context, invoiceID string, opts ...RequestOption) (*Invoice, error)
+	GetInvoices(ctx context.Context, invoiceIDs []string, opts ...RequestOption) (*BatchResult[string, *Invoice], error)
+	ListInvoices(ctx context.Context, params ListInvoicesParams, opts ...RequestOption) (*ListInvoicesResponse, error)
+	ListInvoicesStream(ctx context.Context, params ListInvoicesParams, fn func(*Invoice) error, opts ...RequestOption) error
+	UpdateInvoiceStatus(ctx context.Context, invoiceID string, status string, opts ...RequestOption) (*Invoice, error)
+	ExtendInvoiceExpiry(ctx context.Context, invoiceID string, additionalMinutes int, opts ...RequestOption) (*Invoice, error)
+	ReissueInvoice(ctx context.Context, invoiceID string, opts ...RequestOption) (*Invoice, error)
+	FindByExternalRef(ctx context.Context, key, value string, opts ...RequestOption) ([]Invoice, error)
+	GetPaymentProof(ctx context.Context, invoiceID string, opts ...RequestOption) (*PaymentProof, error)
+	GetInvoiceTransactions(ctx context.Context, invoiceID string, opts ...RequestOption) ([]Transaction, error)
+	GetInvoiceReceipt(ctx context.Context, invoiceID string, w io.Writer, opts ...RequestOption) (ReceiptSource, error)
+}
+
+// PayoutService is the payout surface of the client
+type PayoutService interface {
+	CreatePayoutDraft(ctx context.Context, req CreatePayoutDraftRequest, opts ...RequestOption) (*Payout, error)
+	GetPayout(ctx context.Context, payoutID string, opts ...RequestOption) (*Payout, error)
+	ListPendingPayoutApprovals(ctx context.Context, opts ...RequestOption) (*ListPayoutsResponse, error)
+	ApprovePayout(ctx context.Context, payoutID, approverToken string, opts ...RequestOption) (*Payout, error)
+	RejectPayout(ctx context.Context, payoutID, approverToken, reason string, opts ...RequestOption) (*Payout, error)
+	CreateFiatPayout(ctx context.Context, req CreateFiatPayoutRequest, opts ...RequestOption) (*FiatPayout, error)
+	GetFiatPayout(ctx context.Context, fiatPayoutID string, opts ...RequestOption) (*FiatPayout, error)
+	ListFiatPayouts(ctx context.Context, params ListFiatPayoutsParams, opts ...RequestOption) (*ListFiatPayoutsResponse, error)
+}
+
+// ReportService is the read-only reporting surface of the client. Combine it with
+// WithReadOnly to also block writes at runtime.
+type ReportService interface {
+	ListInvoices(ctx context.Context, params ListInvoicesParams, opts ...RequestOption) (*ListInvoicesResponse, error)
+	NewInvoicePager(params ListInvoicesParams, opts ...RequestOption) *InvoicePager
+	ExportInvoices(ctx context.Context, params ListInvoicesParams, w io.Writer, format ExportFormat, columns []string, opts ...RequestOption) (int, error)
+	ListFlaggedInvoices(ctx context.Context, params ListInvoicesParams, minScore float64, opts ...RequestOption) ([]Invoice, error)
+	ListSweepExecutions(ctx context.Context, params ListSweepExecutionsParams, opts ...RequestOption) (*ListSweepExecutionsResponse, error)
+	GetSettlements(ctx context.Context, params GetSettlementsParams, opts ...RequestOption) (*GetSettlementsResponse, error)
+	GetSettlementItems(ctx context.Context, settlementID string, params ListSettlementItemsParams, opts ...RequestOption) (*ListSettlementItemsResponse, error)
+	GetRates(ctx context.Context, opts ...RequestOption) (*RatesResponse, error)
+}
+
+// Invoices returns the invoice surface of the client
+func (c *Client) Invoices() InvoiceService {
+	return c
+}
+
+// Payouts returns the payout surface of the client
+func (c *Client) Payouts() PayoutService {
+	return c
+}
+
+// Reports returns the reporting surface of the client
+func (c *Client) Reports() ReportService {
+	return c
+}
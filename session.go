@@ -0,0 +1,146 @@
+package itispay
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// sessionRefreshMargin is how long before expiry a cached session token is renewed
+const sessionRefreshMargin = 30 * time.Second
+
+// sessionToken is the response of the session endpoint
+type sessionToken struct {
+	Token     string    `json:"session_token"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// sessionCache holds the short-lived session tokens some dashboard-scope endpoints
+// require instead of the API key, one per key, and remembers which endpoints require it
+type sessionCache struct {
+	mu       sync.Mutex
+	tokens   map[string]sessionToken
+	fetching map[string]*sessionFetch
+
+	endpoints sync.Map
+}
+
+// sessionFetch is an exchange of an API key for a session token in progress, shared by
+// the callers needing a token for that key meanwhile
+type sessionFetch struct {
+	done  chan struct{}
+	token sessionToken
+	err   error
+}
+
+// required reports whether endpoint is known to require a session token
+func (s *sessionCache) required(endpoint string) bool {
+	_, ok := s.endpoints.Load(endpoint)
+	return ok
+}
+
+// markRequired records that endpoint requires a session token
+func (s *sessionCache) markRequired(endpoint string) {
+	s.endpoints.Store(endpoint, struct{}{})
+}
+
+// authorizeSession adds a session token for apiKey to the request headers, exchanging
+// the key for a new token when none is cached or it is about to expire. force asks
+// for a new token because the one in the headers was rejected; it is skipped if
+// another call already replaced that token.
+func (c *Client) authorizeSession(ctx context.Context, apiKey string, options *requestOptions, force bool) error {
+	rejected := ""
+	if force {
+		rejected = strings.TrimPrefix(options.headers.Get("Authorization"), "Bearer ")
+	}
+	token, err := c.sessionToken(ctx, apiKey, force, rejected)
+	if err != nil {
+		return err
+	}
+	options.headers.Set("Authorization", "Bearer "+token.Token)
+	return nil
+}
+
+// sessionToken returns a usable session token for apiKey. Only one exchange per key
+// runs at a time; concurrent callers wait for its result instead of starting their
+// own, and callers for other keys are not held up.
+func (c *Client) sessionToken(ctx context.Context, apiKey string, force bool, rejected string) (sessionToken, error) {
+	s := &c.sessions
+	for {
+		s.mu.Lock()
+		token, ok := s.tokens[apiKey]
+		if ok && token.ExpiresAt.Sub(c.clock.Now()) >= sessionRefreshMargin && !(force && token.Token == rejected) {
+			s.mu.Unlock()
+			return token, nil
+		}
+
+		if fetch, ok := s.fetching[apiKey]; ok {
+			s.mu.Unlock()
+			select {
+			case <-fetch.done:
+			case <-ctx.Done():
+				return sessionToken{}, ctx.Err()
+			}
+			// An exchange abandoned by its caller is retried with this caller's context
+			if fetch.err != nil && (errors.Is(fetch.err, context.Canceled) || errors.Is(fetch.err, context.DeadlineExceeded)) && ctx.Err() == nil {
+				continue
+			}
+			return fetch.token, fetch.err
+		}
+
+		fetch := &sessionFetch{done: make(chan struct{})}
+		if s.fetching == nil {
+			s.fetching = make(map[string]*sessionFetch)
+		}
+		s.fetching[apiKey] = fetch
+		s.mu.Unlock()
+
+		fetch.token, fetch.err = c.fetchSessionToken(ctx, apiKey)
+		s.mu.Lock()
+		delete(s.fetching, apiKey)
+		if fetch.err == nil {
+			s.store(apiKey, fetch.token, c.clock.Now())
+		}
+		s.mu.Unlock()
+		close(fetch.done)
+		return fetch.token, fetch.err
+	}
+}
+
+// fetchSessionToken exchanges apiKey for a new session token
+func (c *Client) fetchSessionToken(ctx context.Context, apiKey string) (sessionToken, error) {
+	var token sessionToken
+	resp, err := c.send(ctx, "POST", "/auth/session", nil, apiKey, newRequestOptions(nil))
+	if err != nil {
+		return token, fmt.Errorf("failed to obtain session token: %w", err)
+	}
+	if err := resp.decode(&token); err != nil {
+		return token, fmt.Errorf("failed to unmarshal session response: %w", err)
+	}
+	return token, nil
+}
+
+// store caches the token of apiKey, dropping expired tokens of other keys so the cache
+// does not grow with the number of tenants; s.mu must be held
+func (s *sessionCache) store(apiKey string, token sessionToken, now time.Time) {
+	if s.tokens == nil {
+		s.tokens = make(map[string]sessionToken)
+	}
+	for key, cached := range s.tokens {
+		if cached.ExpiresAt.Before(now) {
+			delete(s.tokens, key)
+		}
+	}
+	s.tokens[apiKey] = token
+}
+
+// isSessionRequired reports whether err asks for a new session token
+func isSessionRequired(err error) bool {
+	var apiErr *APIError
+	return errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusUnauthorized &&
+		(apiErr.Code == ErrCodeSessionRequired || apiErr.Code == ErrCodeSessionExpired)
+}
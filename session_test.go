@@ -0,0 +1,101 @@
+package itispay
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// TestSessionTokenExchangedOncePerKey checks that concurrent calls share one session
+// exchange, and that a token rejected by several calls at once is replaced only once
+func TestSessionTokenExchangedOncePerKey(t *testing.T) {
+	var fetches, current atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/auth/session":
+			time.Sleep(50 * time.Millisecond)
+			n := fetches.Add(1)
+			current.Store(n)
+			json.NewEncoder(w).Encode(sessionToken{Token: fmt.Sprint("tok", n), ExpiresAt: time.Now().Add(time.Hour)})
+		default:
+			auth := r.Header.Get("Authorization")
+			if auth == "" {
+				w.WriteHeader(401)
+				json.NewEncoder(w).Encode(ErrorResponse{Error: "unauthorized", Code: ErrCodeSessionRequired})
+				return
+			}
+			if auth != fmt.Sprint("Bearer tok", current.Load()) {
+				w.WriteHeader(401)
+				json.NewEncoder(w).Encode(ErrorResponse{Error: "unauthorized", Code: ErrCodeSessionExpired})
+				return
+			}
+			json.NewEncoder(w).Encode(RatesResponse{})
+		}
+	}))
+	defer srv.Close()
+	client := NewClient("test_key", WithBaseURL(srv.URL))
+	callConcurrently := func() {
+		var wg sync.WaitGroup
+		for i := 0; i < 10; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				if _, err := client.GetRates(context.Background()); err != nil {
+					t.Error(err)
+				}
+			}()
+		}
+		wg.Wait()
+	}
+	callConcurrently()
+	if got := fetches.Load(); got != 1 {
+		t.Errorf("%d session exchanges for concurrent calls, want 1", got)
+	}
+
+	// The server revokes the token: every call is rejected, one refreshes it
+	current.Store(0)
+	callConcurrently()
+	if got := fetches.Load(); got != 2 {
+		t.Errorf("%d session exchanges after the token was revoked, want 2", got)
+	}
+}
+
+func TestSessionExchangeIsNotDryRun(t *testing.T) {
+	dryRuns := make(map[string]string)
+	var mu sync.Mutex
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		dryRuns[r.URL.Path] = r.Header.Get(DryRunHeader)
+		mu.Unlock()
+		switch {
+		case r.URL.Path == "/auth/session":
+			json.NewEncoder(w).Encode(sessionToken{Token: "tok", ExpiresAt: time.Now().Add(time.Hour)})
+		case r.Header.Get("Authorization") == "":
+			w.WriteHeader(401)
+			json.NewEncoder(w).Encode(ErrorResponse{Error: "unauthorized", Code: ErrCodeSessionRequired})
+		default:
+			w.Header().Set(DryRunHeader, "true")
+			json.NewEncoder(w).Encode(Invoice{InvoiceID: "inv-1"})
+		}
+	}))
+	defer srv.Close()
+	client := NewClient("test_key", WithBaseURL(srv.URL), WithDryRun())
+
+	amount := 10.0
+	req := CreateInvoiceRequest{OrderID: "ORDER-1", FiatAmount: &amount, FiatCurrency: "EUR", Currency: "BTC"}
+	if _, err := client.CreateInvoice(context.Background(), req); err != nil {
+		t.Fatal(err)
+	}
+	if got := dryRuns["/auth/session"]; got != "" {
+		t.Errorf("session exchange sent %s: %q", DryRunHeader, got)
+	}
+	if got := dryRuns["/invoices"]; got != "true" {
+		t.Errorf("invoice creation sent %s: %q, want true", DryRunHeader, got)
+	}
+}
@@ -0,0 +1,138 @@
+package itispay
+
+import (
+	"context"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// Settlement status constants
+const (
+	SettlementStatusPending = "pending"
+	SettlementStatusPaid    = "paid"
+	SettlementStatusFailed  = "failed"
+)
+
+// Settlement item type constants
+const (
+	SettlementItemPayment    = "payment"
+	SettlementItemRefund     = "refund"
+	SettlementItemFee        = "fee"
+	SettlementItemAdjustment = "adjustment"
+)
+
+// Settlement is a batch of funds settled to the merchant for a period
+type Settlement struct {
+	ID          string    `json:"id"`
+	PeriodStart time.Time `json:"period_start"`
+	PeriodEnd   time.Time `json:"period_end"`
+	// Currency is the currency the amounts are settled in
+	Currency    string                `json:"currency"`
+	GrossAmount float64               `json:"gross_amount"`
+	FeeAmount   float64               `json:"fee_amount"`
+	NetAmount   float64               `json:"net_amount"`
+	Status      string                `json:"status"`
+	Destination SettlementDestination `json:"destination"`
+	// ItemCount is the number of line items; see GetSettlementItems
+	ItemCount int        `json:"item_count"`
+	CreatedAt time.Time  `json:"created_at"`
+	PaidAt    *time.Time `json:"paid_at,omitempty"`
+}
+
+// SettlementDestination is where a settlement is paid to
+type SettlementDestination struct {
+	// Type is "bank_account" or "wallet"
+	Type string `json:"type"`
+	// Account is the masked IBAN or wallet address
+	Account string `json:"account"`
+	Label   string `json:"label,omitempty"`
+}
+
+// SettlementItem is a line item of a settlement
+type SettlementItem struct {
+	// Type is one of the SettlementItem* constants
+	Type       string    `json:"type"`
+	InvoiceID  string    `json:"invoice_id,omitempty"`
+	PayoutID   string    `json:"payout_id,omitempty"`
+	OrderID    string    `json:"order_id,omitempty"`
+	Gross      float64   `json:"gross"`
+	Fee        float64   `json:"fee"`
+	Net        float64   `json:"net"`
+	OccurredAt time.Time `json:"occurred_at"`
+}
+
+// GetSettlementsParams represents parameters for listing settlements
+type GetSettlementsParams struct {
+	// From and To select settlements whose period overlaps [From, To), e.g. a
+	// MonthRange for month-end close
+	From     time.Time
+	To       time.Time
+	Status   string
+	Page     int
+	PageSize int
+}
+
+// GetSettlementsResponse represents the response from listing settlements
+type GetSettlementsResponse struct {
+	Items      []Settlement   `json:"items"`
+	Pagination PaginationInfo `json:"pagination"`
+}
+
+// ListSettlementItemsParams represents parameters for listing settlement items
+type ListSettlementItemsParams struct {
+	Page     int
+	PageSize int
+}
+
+// ListSettlementItemsResponse represents the response from listing settlement items
+type ListSettlementItemsResponse struct {
+	Items      []SettlementItem `json:"items"`
+	Pagination PaginationInfo   `json:"pagination"`
+}
+
+// GetSettlements retrieves a paginated list of settlement batches, newest first
+func (c *Client) GetSettlements(ctx context.Context, params GetSettlementsParams, opts ...RequestOption) (*GetSettlementsResponse, error) {
+	queryParams := url.Values{}
+	if !params.From.IsZero() {
+		queryParams.Set("from", params.From.Format(time.RFC3339))
+	}
+	if !params.To.IsZero() {
+		queryParams.Set("to", params.To.Format(time.RFC3339))
+	}
+	if params.Status != "" {
+		queryParams.Set("status", params.Status)
+	}
+	if params.Page > 0 {
+		queryParams.Set("page", strconv.Itoa(params.Page))
+	}
+	if params.PageSize > 0 {
+		queryParams.Set("page_size", strconv.Itoa(params.PageSize))
+	}
+
+	path := "/settlements"
+	if len(queryParams) > 0 {
+		path += "?" + queryParams.Encode()
+	}
+
+	return do[GetSettlementsResponse](ctx, c, "GET", path, nil, opts...)
+}
+
+// GetSettlementItems retrieves a paginated list of the line items of a settlement:
+// the payments, refunds, fees and adjustments making up its amounts
+func (c *Client) GetSettlementItems(ctx context.Context, settlementID string, params ListSettlementItemsParams, opts ...RequestOption) (*ListSettlementItemsResponse, error) {
+	queryParams := url.Values{}
+	if params.Page > 0 {
+		queryParams.Set("page", strconv.Itoa(params.Page))
+	}
+	if params.PageSize > 0 {
+		queryParams.Set("page_size", strconv.Itoa(params.PageSize))
+	}
+
+	path := "/settlements/" + url.PathEscape(settlementID) + "/items"
+	if len(queryParams) > 0 {
+		path += "?" + queryParams.Encode()
+	}
+
+	return do[ListSettlementItemsResponse](ctx, c, "GET", path, nil, opts...)
+}
@@ -0,0 +1,65 @@
+package itispay_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	itispay "github.com/ItIsPay/go-client"
+	"github.com/ItIsPay/go-client/itispaytest"
+)
+
+func TestRequestSignedWhenSent(t *testing.T) {
+	clock := itispaytest.NewFakeClock(time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC))
+	first := make(chan struct{})
+	release := make(chan struct{})
+	var mu sync.Mutex
+	var timestamps []string
+	transport := transportFunc(func(req *http.Request) (*http.Response, error) {
+		mu.Lock()
+		timestamps = append(timestamps, req.Header.Get(itispay.TimestampHeader))
+		n := len(timestamps)
+		mu.Unlock()
+		if n == 1 {
+			close(first)
+			<-release
+		}
+		return &http.Response{StatusCode: http.StatusOK, Header: http.Header{}, Body: io.NopCloser(strings.NewReader(`{}`)), Request: req}, nil
+	})
+	client := itispay.NewClient("key",
+		itispay.WithTransport(transport),
+		itispay.WithClock(clock),
+		itispay.WithRequestSigning([]byte("secret")),
+		itispay.WithMaxConcurrentRequests(1),
+	)
+
+	var wg sync.WaitGroup
+	call := func() {
+		defer wg.Done()
+		if _, err := client.GetRates(context.Background()); err != nil {
+			t.Error(err)
+		}
+	}
+	wg.Add(2)
+	go call()
+	<-first
+	// The second request waits for the slot held by the first
+	go call()
+	time.Sleep(20 * time.Millisecond)
+	clock.Advance(time.Minute)
+	close(release)
+	wg.Wait()
+
+	want := []string{
+		strconv.FormatInt(clock.Now().Add(-time.Minute).Unix(), 10),
+		strconv.FormatInt(clock.Now().Unix(), 10),
+	}
+	if len(timestamps) != 2 || timestamps[0] != want[0] || timestamps[1] != want[1] {
+		t.Errorf("timestamps = %v, want %v", timestamps, want)
+	}
+}
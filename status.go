@@ -0,0 +1,15 @@
+package itispay
+
+import "github.com/ItIsPay/go-client/types"
+
+// Status represents the status of an invoice
+type Status = types.Status
+
+// DefaultLocale is the locale used when no description is available for the requested one
+const DefaultLocale = types.DefaultLocale
+
+// RegisterStatusDescriptions adds or overrides status descriptions for a locale.
+// Entries are merged with any existing descriptions for that locale.
+func RegisterStatusDescriptions(locale string, descriptions map[Status]string) {
+	types.RegisterStatusDescriptions(locale, descriptions)
+}
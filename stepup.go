@@ -0,0 +1,108 @@
+package itispay
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// StepUpTokenHeader carries the token proving a completed step-up challenge
+const StepUpTokenHeader = "X-Step-Up-Token"
+
+// Step-up authentication methods
+const (
+	StepUpMethodTOTP     = "totp"
+	StepUpMethodSMS      = "sms"
+	StepUpMethodWebAuthn = "webauthn"
+)
+
+// ErrStepUpRequired matches (with errors.Is) the *StepUpChallenge returned when an
+// operation such as a large payout or key creation needs a second factor
+var ErrStepUpRequired = errors.New("itispay: step-up authentication required")
+
+// StepUpChallenge is returned as an error by operations protected with step-up
+// authentication. Complete it with Client.CompleteStepUp and retry the operation with
+// WithStepUpToken.
+type StepUpChallenge struct {
+	ChallengeID string `json:"challenge_id"`
+	// Methods lists the accepted StepUpMethod* values
+	Methods   []string  `json:"methods"`
+	ExpiresAt time.Time `json:"expires_at"`
+	// WebAuthnOptions are the PublicKeyCredentialRequestOptions to pass to the
+	// authenticator when Methods includes StepUpMethodWebAuthn
+	WebAuthnOptions json.RawMessage `json:"webauthn_options,omitempty"`
+	// Message is the API's explanation of why step-up is required
+	Message string `json:"-"`
+}
+
+// Error returns the error message
+func (c *StepUpChallenge) Error() string {
+	if c.Message != "" {
+		return fmt.Sprintf("itispay: step-up authentication required: %s", c.Message)
+	}
+	return ErrStepUpRequired.Error()
+}
+
+// Is reports whether target is ErrStepUpRequired
+func (c *StepUpChallenge) Is(target error) bool {
+	return target == ErrStepUpRequired
+}
+
+// StepUpResponse answers a step-up challenge
+type StepUpResponse struct {
+	// Method is the StepUpMethod* used
+	Method string `json:"method"`
+	// Code is the one-time code for StepUpMethodTOTP and StepUpMethodSMS
+	Code string `json:"code,omitempty"`
+	// WebAuthnAssertion is the authenticator's assertion (PublicKeyCredential JSON)
+	// for StepUpMethodWebAuthn
+	WebAuthnAssertion json.RawMessage `json:"webauthn_assertion,omitempty"`
+}
+
+// StepUpToken proves a completed step-up challenge for a limited time
+type StepUpToken struct {
+	Token     string    `json:"step_up_token"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// WithStepUpToken attaches the token of a completed step-up challenge to the call
+func WithStepUpToken(token string) RequestOption {
+	return WithHeader(StepUpTokenHeader, token)
+}
+
+// CompleteStepUp answers a step-up challenge and returns the token to retry the
+// protected operation with:
+//
+//	payout, err := client.ApprovePayout(ctx, id, approverToken)
+//	var challenge *itispay.StepUpChallenge
+//	if errors.As(err, &challenge) {
+//		token, err := client.CompleteStepUp(ctx, challenge, itispay.StepUpResponse{
+//			Method: itispay.StepUpMethodTOTP,
+//			Code:   code,
+//		})
+//		...
+//		payout, err = client.ApprovePayout(ctx, id, approverToken, itispay.WithStepUpToken(token.Token))
+//	}
+func (c *Client) CompleteStepUp(ctx context.Context, challenge *StepUpChallenge, response StepUpResponse, opts ...RequestOption) (*StepUpToken, error) {
+	path := "/auth/step-up/" + url.PathEscape(challenge.ChallengeID) + "/complete"
+	return do[StepUpToken](ctx, c, "POST", path, response, opts...)
+}
+
+// stepUpChallenge extracts the challenge from an error response, if it is one
+func stepUpChallenge(statusCode int, apiError ErrorResponse, body []byte) (*StepUpChallenge, bool) {
+	if statusCode != http.StatusForbidden || ErrorCode(apiError.Error) != ErrCodeStepUpRequired {
+		return nil, false
+	}
+
+	var response struct {
+		Challenge StepUpChallenge `json:"challenge"`
+	}
+	// A malformed challenge still reports ErrStepUpRequired
+	_ = json.Unmarshal(body, &response)
+	response.Challenge.Message = apiError.Message
+	return &response.Challenge, true
+}
@@ -0,0 +1,86 @@
+package store
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	itispay "github.com/ItIsPay/go-client"
+)
+
+// MemoryBackend is an in-process Backend
+type MemoryBackend struct {
+	mu       sync.RWMutex
+	invoices map[string]entry[itispay.Invoice]
+	payouts  map[string]entry[itispay.Payout]
+}
+
+// entry is a cached object and when it was stored
+type entry[T any] struct {
+	value    T
+	storedAt time.Time
+}
+
+// NewMemoryBackend returns an empty in-memory backend
+func NewMemoryBackend() *MemoryBackend {
+	return &MemoryBackend{
+		invoices: make(map[string]entry[itispay.Invoice]),
+		payouts:  make(map[string]entry[itispay.Payout]),
+	}
+}
+
+// LoadInvoice implements Backend
+func (b *MemoryBackend) LoadInvoice(ctx context.Context, invoiceID string) (*itispay.Invoice, time.Time, error) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	e, ok := b.invoices[invoiceID]
+	if !ok {
+		return nil, time.Time{}, ErrNotCached
+	}
+	invoice := e.value
+	return &invoice, e.storedAt, nil
+}
+
+// SaveInvoice implements Backend
+func (b *MemoryBackend) SaveInvoice(ctx context.Context, invoice *itispay.Invoice, storedAt time.Time) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.invoices[invoice.InvoiceID] = entry[itispay.Invoice]{value: *invoice, storedAt: storedAt}
+	return nil
+}
+
+// DeleteInvoice implements Backend
+func (b *MemoryBackend) DeleteInvoice(ctx context.Context, invoiceID string) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	delete(b.invoices, invoiceID)
+	return nil
+}
+
+// LoadPayout implements Backend
+func (b *MemoryBackend) LoadPayout(ctx context.Context, payoutID string) (*itispay.Payout, time.Time, error) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	e, ok := b.payouts[payoutID]
+	if !ok {
+		return nil, time.Time{}, ErrNotCached
+	}
+	payout := e.value
+	return &payout, e.storedAt, nil
+}
+
+// SavePayout implements Backend
+func (b *MemoryBackend) SavePayout(ctx context.Context, payout *itispay.Payout, storedAt time.Time) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.payouts[payout.PayoutID] = entry[itispay.Payout]{value: *payout, storedAt: storedAt}
+	return nil
+}
+
+// DeletePayout implements Backend
+func (b *MemoryBackend) DeletePayout(ctx context.Context, payoutID string) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	delete(b.payouts, payoutID)
+	return nil
+}
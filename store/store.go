@@ -0,0 +1,218 @@
+// Package store is a data-access layer combining the ItIsPay client with a local cache
+// of invoices and payouts. Mutations made through the Store write through to the cache,
+// and every read chooses its freshness:
+//
+//	s := store.New(client, store.NewMemoryBackend())
+//	invoice, err := s.CreateInvoice(ctx, req)              // cached
+//	invoice, err = s.GetInvoice(ctx, id, store.CacheThenAPI) // no API call
+//	invoice, err = s.GetInvoice(ctx, id, store.API)          // refreshed
+package store
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	itispay "github.com/ItIsPay/go-client"
+)
+
+// ErrNotCached is returned by Backend loads and by reads with Cache freshness when the
+// object is not cached
+var ErrNotCached = errors.New("store: not cached")
+
+// Freshness selects where a read is served from
+type Freshness int
+
+const (
+	// API always fetches from the API and refreshes the cache
+	API Freshness = iota
+	// Cache only reads the cache and returns ErrNotCached on a miss
+	Cache
+	// CacheThenAPI reads the cache and falls back to the API on a miss or when the
+	// cached copy is older than Store.MaxAge
+	CacheThenAPI
+)
+
+// Backend persists cached objects. Implementations must be safe for concurrent use;
+// back it with Redis or a database to share the cache between instances.
+type Backend interface {
+	// LoadInvoice returns a cached invoice and when it was stored, or ErrNotCached
+	LoadInvoice(ctx context.Context, invoiceID string) (*itispay.Invoice, time.Time, error)
+	SaveInvoice(ctx context.Context, invoice *itispay.Invoice, storedAt time.Time) error
+	DeleteInvoice(ctx context.Context, invoiceID string) error
+	// LoadPayout returns a cached payout and when it was stored, or ErrNotCached
+	LoadPayout(ctx context.Context, payoutID string) (*itispay.Payout, time.Time, error)
+	SavePayout(ctx context.Context, payout *itispay.Payout, storedAt time.Time) error
+	DeletePayout(ctx context.Context, payoutID string) error
+}
+
+// Store reads and writes invoices and payouts through a cache
+type Store struct {
+	client  *itispay.Client
+	backend Backend
+
+	// MaxAge is how long cached objects are served by CacheThenAPI reads; zero means
+	// no limit
+	MaxAge time.Duration
+	// OnError, if set, is called when the cache cannot be written. Such errors do not
+	// fail the call, since the API already succeeded.
+	OnError func(err error)
+	// Clock stamps cached objects and tells their age, itispay.SystemClock if nil
+	Clock itispay.Clock
+}
+
+// New returns a Store using client for API calls and backend as the cache
+func New(client *itispay.Client, backend Backend) *Store {
+	return &Store{client: client, backend: backend}
+}
+
+// GetInvoice reads an invoice with the given freshness
+func (s *Store) GetInvoice(ctx context.Context, invoiceID string, freshness Freshness, opts ...itispay.RequestOption) (*itispay.Invoice, error) {
+	if freshness != API {
+		invoice, storedAt, err := s.backend.LoadInvoice(ctx, invoiceID)
+		switch {
+		case err == nil && (freshness == Cache || s.fresh(storedAt)):
+			return invoice, nil
+		case err != nil && !errors.Is(err, ErrNotCached):
+			return nil, err
+		case freshness == Cache:
+			return nil, ErrNotCached
+		}
+	}
+
+	invoice, err := s.client.GetInvoice(ctx, invoiceID, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return s.saveInvoice(ctx, invoice), nil
+}
+
+// CreateInvoice creates an invoice and caches it. Dry runs are not cached, since they
+// create nothing.
+func (s *Store) CreateInvoice(ctx context.Context, req itispay.CreateInvoiceRequest, opts ...itispay.RequestOption) (*itispay.Invoice, error) {
+	invoice, resp, err := s.client.CreateInvoiceWithResponse(ctx, req, opts...)
+	// The API echoes the dry run header, whether the client or the call asked for it
+	dryRun := resp != nil && resp.Header.Get(itispay.DryRunHeader) == "true"
+	if invoice != nil && !dryRun {
+		s.saveInvoice(ctx, invoice)
+	}
+	return invoice, err
+}
+
+// UpdateInvoiceStatus updates the status of an invoice and caches the result
+func (s *Store) UpdateInvoiceStatus(ctx context.Context, invoiceID, status string, opts ...itispay.RequestOption) (*itispay.Invoice, error) {
+	invoice, err := s.client.UpdateInvoiceStatus(ctx, invoiceID, status, opts...)
+	if invoice != nil {
+		s.saveInvoice(ctx, invoice)
+	} else {
+		s.invalidateInvoice(ctx, invoiceID)
+	}
+	return invoice, err
+}
+
+// ApplyWebhook drops the cached copy of the invoice a webhook reports a change for, so
+// the next CacheThenAPI read fetches the new state
+func (s *Store) ApplyWebhook(ctx context.Context, payload *itispay.WebhookPayload) error {
+	return s.backend.DeleteInvoice(ctx, payload.InvoiceID)
+}
+
+// GetPayout reads a payout with the given freshness
+func (s *Store) GetPayout(ctx context.Context, payoutID string, freshness Freshness, opts ...itispay.RequestOption) (*itispay.Payout, error) {
+	if freshness != API {
+		payout, storedAt, err := s.backend.LoadPayout(ctx, payoutID)
+		switch {
+		case err == nil && (freshness == Cache || s.fresh(storedAt)):
+			return payout, nil
+		case err != nil && !errors.Is(err, ErrNotCached):
+			return nil, err
+		case freshness == Cache:
+			return nil, ErrNotCached
+		}
+	}
+
+	payout, err := s.client.GetPayout(ctx, payoutID, opts...)
+	if err != nil {
+		return nil, err
+	}
+	s.savePayout(ctx, payout)
+	return payout, nil
+}
+
+// CreatePayoutDraft creates a payout draft and caches it
+func (s *Store) CreatePayoutDraft(ctx context.Context, req itispay.CreatePayoutDraftRequest, opts ...itispay.RequestOption) (*itispay.Payout, error) {
+	payout, err := s.client.CreatePayoutDraft(ctx, req, opts...)
+	if err != nil {
+		return nil, err
+	}
+	s.savePayout(ctx, payout)
+	return payout, nil
+}
+
+// ApprovePayout approves a payout and caches the result
+func (s *Store) ApprovePayout(ctx context.Context, payoutID, approverToken string, opts ...itispay.RequestOption) (*itispay.Payout, error) {
+	payout, err := s.client.ApprovePayout(ctx, payoutID, approverToken, opts...)
+	if err != nil {
+		s.invalidatePayout(ctx, payoutID)
+		return nil, err
+	}
+	s.savePayout(ctx, payout)
+	return payout, nil
+}
+
+// RejectPayout rejects a payout and caches the result
+func (s *Store) RejectPayout(ctx context.Context, payoutID, approverToken, reason string, opts ...itispay.RequestOption) (*itispay.Payout, error) {
+	payout, err := s.client.RejectPayout(ctx, payoutID, approverToken, reason, opts...)
+	if err != nil {
+		s.invalidatePayout(ctx, payoutID)
+		return nil, err
+	}
+	s.savePayout(ctx, payout)
+	return payout, nil
+}
+
+// fresh reports whether an object stored at storedAt may be served by CacheThenAPI
+func (s *Store) fresh(storedAt time.Time) bool {
+	return s.MaxAge <= 0 || s.now().Sub(storedAt) < s.MaxAge
+}
+
+// now returns the time of the store's clock
+func (s *Store) now() time.Time {
+	if s.Clock == nil {
+		return itispay.SystemClock.Now()
+	}
+	return s.Clock.Now()
+}
+
+// saveInvoice writes an invoice to the cache and returns it, unless the cache holds a
+// newer copy, e.g. written by a mutation that completed while a read was in flight, which
+// it keeps and returns instead
+func (s *Store) saveInvoice(ctx context.Context, invoice *itispay.Invoice) *itispay.Invoice {
+	cached, _, err := s.backend.LoadInvoice(ctx, invoice.InvoiceID)
+	if err == nil && cached.UpdatedAt.After(invoice.UpdatedAt) {
+		return cached
+	}
+	s.report(s.backend.SaveInvoice(ctx, invoice, s.now()))
+	return invoice
+}
+
+// invalidateInvoice drops an invoice whose state is unknown after a failed mutation
+func (s *Store) invalidateInvoice(ctx context.Context, invoiceID string) {
+	s.report(s.backend.DeleteInvoice(ctx, invoiceID))
+}
+
+// savePayout writes a payout to the cache
+func (s *Store) savePayout(ctx context.Context, payout *itispay.Payout) {
+	s.report(s.backend.SavePayout(ctx, payout, s.now()))
+}
+
+// invalidatePayout drops a payout whose state is unknown after a failed mutation
+func (s *Store) invalidatePayout(ctx context.Context, payoutID string) {
+	s.report(s.backend.DeletePayout(ctx, payoutID))
+}
+
+// report passes a cache write error to OnError
+func (s *Store) report(err error) {
+	if err != nil && s.OnError != nil {
+		s.OnError(err)
+	}
+}
@@ -0,0 +1,80 @@
+package store_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	itispay "github.com/ItIsPay/go-client"
+	"github.com/ItIsPay/go-client/itispaytest"
+	"github.com/ItIsPay/go-client/store"
+)
+
+func invoiceRequest() itispay.CreateInvoiceRequest {
+	amount := 10.0
+	return itispay.CreateInvoiceRequest{OrderID: "ORDER-1", FiatAmount: &amount, FiatCurrency: "EUR", Currency: "BTC"}
+}
+
+func TestCreateInvoiceDryRunIsNotCached(t *testing.T) {
+	tests := []struct {
+		name       string
+		clientOpts []itispay.Option
+		opts       []itispay.RequestOption
+	}{
+		{name: "client dry run", clientOpts: []itispay.Option{itispay.WithDryRun()}},
+		{name: "request dry run", opts: []itispay.RequestOption{itispay.WithRequestDryRun()}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := itispaytest.NewServer()
+			defer srv.Close()
+			s := store.New(srv.Client(tt.clientOpts...), store.NewMemoryBackend())
+			ctx := context.Background()
+
+			invoice, err := s.CreateInvoice(ctx, invoiceRequest(), tt.opts...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if _, err := s.GetInvoice(ctx, invoice.InvoiceID, store.Cache); !errors.Is(err, store.ErrNotCached) {
+				t.Errorf("err = %v, want the dry run not cached", err)
+			}
+		})
+	}
+}
+
+func TestLateReadKeepsNewerCachedInvoice(t *testing.T) {
+	srv := itispaytest.NewServer()
+	defer srv.Close()
+	backend := store.NewMemoryBackend()
+	s := store.New(srv.Client(), backend)
+	ctx := context.Background()
+
+	invoice, err := s.CreateInvoice(ctx, invoiceRequest())
+	if err != nil {
+		t.Fatal(err)
+	}
+	// A mutation completed while a read was in flight
+	newer := *invoice
+	newer.Status = itispay.StatusCompleted
+	newer.UpdatedAt = invoice.UpdatedAt.Add(time.Minute)
+	if err := backend.SaveInvoice(ctx, &newer, time.Now()); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := s.GetInvoice(ctx, invoice.InvoiceID, store.API)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Status != itispay.StatusCompleted {
+		t.Errorf("read returned status %q, want the newer cached copy", got.Status)
+	}
+	cached, err := s.GetInvoice(ctx, invoice.InvoiceID, store.Cache)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !cached.UpdatedAt.Equal(newer.UpdatedAt) {
+		t.Errorf("cached copy updated at %v, want the newer %v kept", cached.UpdatedAt, newer.UpdatedAt)
+	}
+}
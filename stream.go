@@ -0,0 +1,110 @@
+package itispay
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+// withStream makes sendTo hand a successful response body to stream instead of
+// buffering it
+func withStream(stream func(body io.Reader) error) RequestOption {
+	return func(o *requestOptions) {
+		o.stream = stream
+	}
+}
+
+// ListInvoicesStream calls fn for every invoice matching params, following pages from
+// params.Page (or the first page) to the last. Responses are decoded incrementally, so
+// memory stays flat however large PageSize is, e.g. for exports. An error returned by
+// fn stops the iteration and is returned as is.
+func (c *Client) ListInvoicesStream(ctx context.Context, params ListInvoicesParams, fn func(*Invoice) error, opts ...RequestOption) error {
+	if params.Page < 1 {
+		params.Page = 1
+	}
+	for {
+		path := "/invoices?" + params.query().Encode()
+		endpoint := endpointLabel("GET", path)
+
+		var pagination PaginationInfo
+		count := 0
+		stream := withStream(func(body io.Reader) error {
+			return decodeInvoiceStream(body, endpoint, c.strictDecoding, &pagination, func(invoice *Invoice) error {
+				count++
+				return fn(invoice)
+			})
+		})
+		if _, err := c.doRequest(ctx, "GET", path, nil, append(opts[:len(opts):len(opts)], stream)...); err != nil {
+			return err
+		}
+
+		if !pagination.HasNext || count == 0 {
+			return nil
+		}
+		params.Page++
+	}
+}
+
+// decodeInvoiceStream decodes a ListInvoicesResponse from body, calling fn for each
+// invoice as soon as it is decoded
+func decodeInvoiceStream(body io.Reader, endpoint string, strict bool, pagination *PaginationInfo, fn func(*Invoice) error) error {
+	dec := json.NewDecoder(body)
+	if strict {
+		dec.DisallowUnknownFields()
+	}
+	fail := func(field string, err error) error {
+		return &DecodeError{Endpoint: endpoint, Field: field, Offset: dec.InputOffset(), Err: err}
+	}
+	delim := func(field string, want json.Delim) error {
+		token, err := dec.Token()
+		if err != nil {
+			return fail(field, err)
+		}
+		if token != want {
+			return fail(field, fmt.Errorf("expected %v, got %v", want, token))
+		}
+		return nil
+	}
+
+	if err := delim("", '{'); err != nil {
+		return err
+	}
+	for dec.More() {
+		token, err := dec.Token()
+		if err != nil {
+			return fail("", err)
+		}
+		switch key, _ := token.(string); key {
+		case "items":
+			if err := delim("items", '['); err != nil {
+				return err
+			}
+			for dec.More() {
+				var invoice Invoice
+				if err := dec.Decode(&invoice); err != nil {
+					return fail("items", err)
+				}
+				if err := fn(&invoice); err != nil {
+					return err
+				}
+			}
+			if err := delim("items", ']'); err != nil {
+				return err
+			}
+		case "pagination":
+			if err := dec.Decode(pagination); err != nil {
+				return fail("pagination", err)
+			}
+		default:
+			var skipped json.RawMessage
+			if err := dec.Decode(&skipped); err != nil {
+				return fail(key, err)
+			}
+			if strict {
+				return fail(key, fmt.Errorf("json: unknown field %q", key))
+			}
+		}
+	}
+	return delim("", '}')
+}
@@ -0,0 +1,149 @@
+package itispay
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrInvalidSwap is returned for swaps with a non-positive amount or identical currencies
+var ErrInvalidSwap = errors.New("itispay: invalid swap")
+
+// SwapStatus is the status of a currency swap
+type SwapStatus string
+
+// Swap status constants
+const (
+	SwapStatusPending    SwapStatus = "pending"
+	SwapStatusProcessing SwapStatus = "processing"
+	SwapStatusCompleted  SwapStatus = "completed"
+	SwapStatusFailed     SwapStatus = "failed"
+)
+
+// IsFinal reports whether the swap will not change status anymore
+func (s SwapStatus) IsFinal() bool {
+	return s == SwapStatusCompleted || s == SwapStatusFailed
+}
+
+// SwapQuote is a firm offer to convert an amount between two currencies, valid until
+// ExpiresAt
+type SwapQuote struct {
+	QuoteID      string    `json:"quote_id"`
+	FromCurrency string    `json:"from_currency"`
+	ToCurrency   string    `json:"to_currency"`
+	FromAmount   float64   `json:"from_amount"`
+	ToAmount     float64   `json:"to_amount"`
+	Rate         float64   `json:"rate"`
+	Fee          float64   `json:"fee"`
+	ExpiresAt    time.Time `json:"expires_at"`
+}
+
+// Swap is a conversion of part of the balance from one currency to another
+type Swap struct {
+	SwapID       string     `json:"swap_id"`
+	QuoteID      string     `json:"quote_id,omitempty"`
+	FromCurrency string     `json:"from_currency"`
+	ToCurrency   string     `json:"to_currency"`
+	FromAmount   float64    `json:"from_amount"`
+	ToAmount     float64    `json:"to_amount"`
+	Rate         float64    `json:"rate"`
+	Fee          float64    `json:"fee"`
+	Status       SwapStatus `json:"status"`
+	// FailureReason explains a SwapStatusFailed swap
+	FailureReason string     `json:"failure_reason,omitempty"`
+	CreatedAt     time.Time  `json:"created_at"`
+	CompletedAt   *time.Time `json:"completed_at,omitempty"`
+}
+
+// ListSwapsParams represents parameters for listing swaps
+type ListSwapsParams struct {
+	Status   SwapStatus
+	Page     int
+	PageSize int
+}
+
+// ListSwapsResponse represents the response from listing swaps
+type ListSwapsResponse struct {
+	Items      []Swap         `json:"items"`
+	Pagination PaginationInfo `json:"pagination"`
+}
+
+// createSwapRequest is the body of the swap creation endpoint
+type createSwapRequest struct {
+	FromCurrency string  `json:"from_currency,omitempty"`
+	ToCurrency   string  `json:"to_currency,omitempty"`
+	Amount       float64 `json:"amount,omitempty"`
+	QuoteID      string  `json:"quote_id,omitempty"`
+}
+
+// GetSwapQuote quotes converting amount of the from currency to the to currency
+func (c *Client) GetSwapQuote(ctx context.Context, from, to string, amount float64, opts ...RequestOption) (*SwapQuote, error) {
+	if err := validateSwap(from, to, amount); err != nil {
+		return nil, err
+	}
+	queryParams := url.Values{}
+	queryParams.Set("from", from)
+	queryParams.Set("to", to)
+	queryParams.Set("amount", strconv.FormatFloat(amount, 'f', -1, 64))
+
+	return do[SwapQuote](ctx, c, "GET", "/swaps/quote?"+queryParams.Encode(), nil, opts...)
+}
+
+// CreateSwap converts amount of the from currency to the to currency at the current
+// rate, e.g. to move volatile coins into a stablecoin on receipt. Swaps settle
+// asynchronously; poll GetSwap until the status is final.
+func (c *Client) CreateSwap(ctx context.Context, from, to string, amount float64, opts ...RequestOption) (*Swap, error) {
+	if err := validateSwap(from, to, amount); err != nil {
+		return nil, err
+	}
+	return do[Swap](ctx, c, "POST", "/swaps", createSwapRequest{FromCurrency: from, ToCurrency: to, Amount: amount}, opts...)
+}
+
+// ExecuteSwapQuote converts at the rate of a quote obtained with GetSwapQuote, which
+// must not have expired
+func (c *Client) ExecuteSwapQuote(ctx context.Context, quoteID string, opts ...RequestOption) (*Swap, error) {
+	return do[Swap](ctx, c, "POST", "/swaps", createSwapRequest{QuoteID: quoteID}, opts...)
+}
+
+// GetSwap retrieves a specific swap by ID
+func (c *Client) GetSwap(ctx context.Context, swapID string, opts ...RequestOption) (*Swap, error) {
+	return do[Swap](ctx, c, "GET", "/swaps/"+url.PathEscape(swapID), nil, opts...)
+}
+
+// ListSwaps retrieves a paginated list of swaps, newest first
+func (c *Client) ListSwaps(ctx context.Context, params ListSwapsParams, opts ...RequestOption) (*ListSwapsResponse, error) {
+	queryParams := url.Values{}
+	if params.Status != "" {
+		queryParams.Set("status", string(params.Status))
+	}
+	if params.Page > 0 {
+		queryParams.Set("page", strconv.Itoa(params.Page))
+	}
+	if params.PageSize > 0 {
+		queryParams.Set("page_size", strconv.Itoa(params.PageSize))
+	}
+
+	path := "/swaps"
+	if len(queryParams) > 0 {
+		path += "?" + queryParams.Encode()
+	}
+
+	return do[ListSwapsResponse](ctx, c, "GET", path, nil, opts...)
+}
+
+// validateSwap checks the currencies and amount of a swap
+func validateSwap(from, to string, amount float64) error {
+	switch {
+	case from == "" || to == "":
+		return fmt.Errorf("%w: both currencies are required", ErrInvalidSwap)
+	case strings.EqualFold(from, to):
+		return fmt.Errorf("%w: cannot swap %s to itself", ErrInvalidSwap, from)
+	case amount <= 0:
+		return fmt.Errorf("%w: amount must be positive, got %v", ErrInvalidSwap, amount)
+	}
+	return nil
+}
@@ -0,0 +1,133 @@
+package itispay
+
+import (
+	"context"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// Sweep execution status constants
+const (
+	SweepExecutionStatusPending = "pending"
+	SweepExecutionStatusSent    = "sent"
+	SweepExecutionStatusFailed  = "failed"
+	SweepExecutionStatusSkipped = "skipped"
+)
+
+// CreateSweepRuleRequest represents the request to create an automatic sweep rule.
+// When the balance of Currency exceeds ThresholdAmount, everything above KeepAmount
+// is sent to DestinationAddress. Schedule is a cron expression ("0 * * * *"); an empty
+// schedule evaluates the rule whenever the balance changes.
+type CreateSweepRuleRequest struct {
+	Name               string  `json:"name,omitempty"`
+	Currency           string  `json:"currency"`
+	Network            string  `json:"network,omitempty"`
+	ThresholdAmount    float64 `json:"threshold_amount"`
+	KeepAmount         float64 `json:"keep_amount,omitempty"`
+	DestinationAddress string  `json:"destination_address"`
+	Schedule           string  `json:"schedule,omitempty"`
+	Enabled            bool    `json:"enabled"`
+}
+
+// UpdateSweepRuleRequest represents the request to update a sweep rule. Nil fields are left unchanged.
+type UpdateSweepRuleRequest struct {
+	Name               *string  `json:"name,omitempty"`
+	ThresholdAmount    *float64 `json:"threshold_amount,omitempty"`
+	KeepAmount         *float64 `json:"keep_amount,omitempty"`
+	DestinationAddress *string  `json:"destination_address,omitempty"`
+	Schedule           *string  `json:"schedule,omitempty"`
+	Enabled            *bool    `json:"enabled,omitempty"`
+}
+
+// SweepRule represents an automatic treasury sweep rule
+type SweepRule struct {
+	RuleID             string    `json:"rule_id"`
+	Name               string    `json:"name,omitempty"`
+	Currency           string    `json:"currency"`
+	Network            string    `json:"network,omitempty"`
+	ThresholdAmount    float64   `json:"threshold_amount"`
+	KeepAmount         float64   `json:"keep_amount"`
+	DestinationAddress string    `json:"destination_address"`
+	Schedule           string    `json:"schedule,omitempty"`
+	Enabled            bool      `json:"enabled"`
+	CreatedAt          time.Time `json:"created_at"`
+	UpdatedAt          time.Time `json:"updated_at"`
+}
+
+// ListSweepRulesResponse represents the response from listing sweep rules
+type ListSweepRulesResponse struct {
+	Items []SweepRule `json:"items"`
+}
+
+// ListSweepExecutionsParams represents the parameters for listing sweep executions
+type ListSweepExecutionsParams struct {
+	RuleID   string `json:"rule_id,omitempty"`
+	Status   string `json:"status,omitempty"`
+	Page     int    `json:"page,omitempty"`
+	PageSize int    `json:"page_size,omitempty"`
+}
+
+// SweepExecution represents a single run of a sweep rule
+type SweepExecution struct {
+	ExecutionID        string    `json:"execution_id"`
+	RuleID             string    `json:"rule_id"`
+	Currency           string    `json:"currency"`
+	Amount             float64   `json:"amount"`
+	DestinationAddress string    `json:"destination_address"`
+	Status             string    `json:"status"`
+	TxHash             string    `json:"tx_hash,omitempty"`
+	Error              string    `json:"error,omitempty"`
+	ExecutedAt         time.Time `json:"executed_at"`
+}
+
+// ListSweepExecutionsResponse represents the response from listing sweep executions
+type ListSweepExecutionsResponse struct {
+	Items      []SweepExecution `json:"items"`
+	Pagination PaginationInfo   `json:"pagination"`
+}
+
+// CreateSweepRule creates an automatic sweep rule
+func (c *Client) CreateSweepRule(ctx context.Context, req CreateSweepRuleRequest, opts ...RequestOption) (*SweepRule, error) {
+	return do[SweepRule](ctx, c, "POST", "/treasury/sweep-rules", req, opts...)
+}
+
+// ListSweepRules retrieves all sweep rules of the account
+func (c *Client) ListSweepRules(ctx context.Context, opts ...RequestOption) (*ListSweepRulesResponse, error) {
+	return do[ListSweepRulesResponse](ctx, c, "GET", "/treasury/sweep-rules", nil, opts...)
+}
+
+// UpdateSweepRule updates an existing sweep rule
+func (c *Client) UpdateSweepRule(ctx context.Context, ruleID string, req UpdateSweepRuleRequest, opts ...RequestOption) (*SweepRule, error) {
+	return do[SweepRule](ctx, c, "PATCH", "/treasury/sweep-rules/"+url.PathEscape(ruleID), req, opts...)
+}
+
+// DeleteSweepRule deletes a sweep rule
+func (c *Client) DeleteSweepRule(ctx context.Context, ruleID string, opts ...RequestOption) error {
+	_, err := c.doRequest(ctx, "DELETE", "/treasury/sweep-rules/"+url.PathEscape(ruleID), nil, opts...)
+	return err
+}
+
+// ListSweepExecutions retrieves a paginated list of sweep executions
+func (c *Client) ListSweepExecutions(ctx context.Context, params ListSweepExecutionsParams, opts ...RequestOption) (*ListSweepExecutionsResponse, error) {
+	queryParams := url.Values{}
+	if params.RuleID != "" {
+		queryParams.Set("rule_id", params.RuleID)
+	}
+	if params.Status != "" {
+		queryParams.Set("status", params.Status)
+	}
+	if params.Page > 0 {
+		queryParams.Set("page", strconv.Itoa(params.Page))
+	}
+	if params.PageSize > 0 {
+		queryParams.Set("page_size", strconv.Itoa(params.PageSize))
+	}
+
+	path := "/treasury/sweep-executions"
+	if len(queryParams) > 0 {
+		path += "?" + queryParams.Encode()
+	}
+
+	return do[ListSweepExecutionsResponse](ctx, c, "GET", path, nil, opts...)
+}
@@ -0,0 +1,37 @@
+package itispay
+
+import "context"
+
+// apiKeyContextKey is the context key for a per-request API key
+type apiKeyContextKey struct{}
+
+// ContextWithAPIKey returns a context making requests use apiKey instead of the client's
+// key, so one client can serve many tenants with their own keys. Set it where the tenant
+// is resolved, e.g. in HTTP middleware.
+func ContextWithAPIKey(ctx context.Context, apiKey string) context.Context {
+	return context.WithValue(ctx, apiKeyContextKey{}, apiKey)
+}
+
+// APIKeyFromContext returns the API key stored in ctx by ContextWithAPIKey
+func APIKeyFromContext(ctx context.Context) (string, bool) {
+	apiKey, ok := ctx.Value(apiKeyContextKey{}).(string)
+	return apiKey, ok && apiKey != ""
+}
+
+// WithAPIKey makes this call use apiKey instead of the client's key. It takes precedence
+// over ContextWithAPIKey.
+func WithAPIKey(apiKey string) RequestOption {
+	return func(o *requestOptions) {
+		o.apiKey = apiKey
+	}
+}
+
+// overrideAPIKey returns the per-request API key set with WithAPIKey or
+// ContextWithAPIKey, if any. Overridden keys bypass the credentials provider and are not
+// refreshed after authentication failures.
+func overrideAPIKey(ctx context.Context, options *requestOptions) (string, bool) {
+	if options.apiKey != "" {
+		return options.apiKey, true
+	}
+	return APIKeyFromContext(ctx)
+}
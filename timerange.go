@@ -0,0 +1,71 @@
+package itispay
+
+import (
+	"fmt"
+	"time"
+)
+
+// TimeRange is a half-open time interval [Start, End)
+type TimeRange struct {
+	Start time.Time
+	End   time.Time
+}
+
+// DayRange returns the range covering a calendar day in the named time zone, e.g.
+// DayRange("Europe/Berlin", date). The calendar date is taken from date as written
+// (date.Date()), so time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC) means 1 March in Berlin.
+// Days shortened or lengthened by DST transitions are handled correctly.
+func DayRange(location string, date time.Time) (TimeRange, error) {
+	loc, err := time.LoadLocation(location)
+	if err != nil {
+		return TimeRange{}, fmt.Errorf("invalid time zone %q: %w", location, err)
+	}
+	return DayRangeIn(loc, date), nil
+}
+
+// DayRangeIn is DayRange with an already loaded location
+func DayRangeIn(loc *time.Location, date time.Time) TimeRange {
+	year, month, day := date.Date()
+	return TimeRange{
+		Start: time.Date(year, month, day, 0, 0, 0, 0, loc),
+		End:   time.Date(year, month, day+1, 0, 0, 0, 0, loc),
+	}
+}
+
+// DaysRange returns the range from the start of the first day to the end of the last
+// day (inclusive) in the named time zone
+func DaysRange(location string, first, last time.Time) (TimeRange, error) {
+	loc, err := time.LoadLocation(location)
+	if err != nil {
+		return TimeRange{}, fmt.Errorf("invalid time zone %q: %w", location, err)
+	}
+	return TimeRange{
+		Start: DayRangeIn(loc, first).Start,
+		End:   DayRangeIn(loc, last).End,
+	}, nil
+}
+
+// MonthRange returns the range covering a calendar month in the named time zone,
+// e.g. for monthly statements
+func MonthRange(location string, year int, month time.Month) (TimeRange, error) {
+	loc, err := time.LoadLocation(location)
+	if err != nil {
+		return TimeRange{}, fmt.Errorf("invalid time zone %q: %w", location, err)
+	}
+	return TimeRange{
+		Start: time.Date(year, month, 1, 0, 0, 0, 0, loc),
+		End:   time.Date(year, month+1, 1, 0, 0, 0, 0, loc),
+	}, nil
+}
+
+// Contains reports whether t falls within the range
+func (r TimeRange) Contains(t time.Time) bool {
+	return !t.Before(r.Start) && t.Before(r.End)
+}
+
+// Apply returns params filtered to invoices created within the range
+func (r TimeRange) Apply(params ListInvoicesParams) ListInvoicesParams {
+	params.CreatedAfter = r.Start
+	params.CreatedBefore = r.End
+	return params
+}
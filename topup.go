@@ -0,0 +1,88 @@
+package itispay
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+)
+
+// TopUpRefKey is the external reference linking a top-up invoice to the underpaid invoice
+const TopUpRefKey = "top_up_for"
+
+// ErrNoShortfall is returned by CreateTopUpInvoice for invoices that are not underpaid
+var ErrNoShortfall = errors.New("itispay: invoice has no shortfall")
+
+// Shortfall is the amount missing from an underpaid invoice
+type Shortfall struct {
+	Currency     string
+	CryptoAmount float64
+	// CryptoAmountInUnits is set when the invoice reports amounts in base units
+	CryptoAmountInUnits *int64
+	FiatCurrency        string
+	// FiatAmount values CryptoAmount at the rate the invoice was quoted at, rounded to
+	// two decimals
+	FiatAmount float64
+}
+
+// Shortfall returns the amount still to be paid, with ok false if nothing is missing
+func (i *Invoice) Shortfall() (shortfall Shortfall, ok bool) {
+	shortfall = Shortfall{Currency: i.Currency, FiatCurrency: i.FiatCurrency}
+
+	if i.CryptoAmountInUnits != nil {
+		units := *i.CryptoAmountInUnits - i.ActualCryptoAmountPaidInUnits
+		if units <= 0 {
+			return shortfall, false
+		}
+		shortfall.CryptoAmountInUnits = &units
+		// Derive the decimal amount from units to avoid float subtraction errors
+		shortfall.CryptoAmount = i.CryptoAmount * float64(units) / float64(*i.CryptoAmountInUnits)
+	} else {
+		shortfall.CryptoAmount = i.CryptoAmount - i.ActualCryptoAmountPaid
+		if shortfall.CryptoAmount <= 0 {
+			return shortfall, false
+		}
+	}
+
+	if i.CryptoAmount > 0 {
+		rate := i.FiatAmount / i.CryptoAmount
+		shortfall.FiatAmount = math.Round(shortfall.CryptoAmount*rate*100) / 100
+	}
+	return shortfall, true
+}
+
+// CreateTopUpInvoice creates an invoice for the shortfall of a partially paid invoice,
+// in the same currency. The top-up references the original through the TopUpRefKey
+// external reference and, unless an idempotency key is given, uses one derived from the
+// original invoice ID so retries do not create a second top-up.
+func (c *Client) CreateTopUpInvoice(ctx context.Context, invoiceID string, opts ...RequestOption) (*Invoice, error) {
+	if err := c.checkReadOnly("POST", createInvoiceEndpoint); err != nil {
+		return nil, err
+	}
+	original, err := c.GetInvoice(ctx, invoiceID, opts...)
+	if err != nil {
+		return nil, err
+	}
+	if original.Status != StatusPaidPartial {
+		return nil, fmt.Errorf("%w: status is %s", ErrNoShortfall, original.Status)
+	}
+	shortfall, ok := original.Shortfall()
+	if !ok {
+		return nil, ErrNoShortfall
+	}
+
+	req := CreateInvoiceRequest{
+		OrderID:             original.OrderID + "-topup",
+		CryptoAmount:        &shortfall.CryptoAmount,
+		Currency:            original.Currency,
+		AllowedErrorPercent: &original.AllowedErrorPercent,
+		OrderName:           original.OrderName,
+		CallbackURL:         original.CallbackURL,
+		ExternalRefs:        map[string]string{TopUpRefKey: original.InvoiceID},
+		Metadata:            original.Metadata,
+	}
+	if newRequestOptions(opts).headers.Get(IdempotencyKeyHeader) == "" {
+		opts = append(opts[:len(opts):len(opts)], WithIdempotencyKey("topup-"+original.InvoiceID))
+	}
+	return c.CreateInvoice(ctx, req, opts...)
+}
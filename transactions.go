@@ -0,0 +1,26 @@
+package itispay
+
+import (
+	"context"
+	"net/url"
+
+	"github.com/ItIsPay/go-client/types"
+)
+
+// Transaction is an on-chain transaction paying an invoice
+type Transaction = types.Transaction
+
+// invoiceTransactionsResponse represents the response from listing invoice transactions
+type invoiceTransactionsResponse struct {
+	Transactions []Transaction `json:"transactions"`
+}
+
+// GetInvoiceTransactions retrieves the on-chain transactions paying an invoice, with
+// up-to-date confirmation counts
+func (c *Client) GetInvoiceTransactions(ctx context.Context, invoiceID string, opts ...RequestOption) ([]Transaction, error) {
+	response, err := do[invoiceTransactionsResponse](ctx, c, "GET", "/invoices/"+url.PathEscape(invoiceID)+"/transactions", nil, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return response.Transactions, nil
+}
@@ -0,0 +1,156 @@
+package itispay
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"time"
+)
+
+// ErrInvalidTravelRule is returned for travel rule information missing required fields
+var ErrInvalidTravelRule = errors.New("itispay: invalid travel rule information")
+
+// Travel rule person types
+const (
+	NaturalPerson = "natural_person"
+	LegalPerson   = "legal_person"
+)
+
+// Compliance status constants
+const (
+	// ComplianceNotRequired means the transfer is below the travel rule threshold
+	ComplianceNotRequired = "not_required"
+	CompliancePending     = "pending"
+	// ComplianceInfoRequired means travel rule information is missing or incomplete; see
+	// MissingFields
+	ComplianceInfoRequired = "info_required"
+	ComplianceApproved     = "approved"
+	ComplianceRejected     = "rejected"
+)
+
+// PostalAddress is the geographic address of a travel rule party
+type PostalAddress struct {
+	Street     string `json:"street,omitempty"`
+	City       string `json:"city,omitempty"`
+	PostalCode string `json:"postal_code,omitempty"`
+	// Country is the ISO 3166-1 alpha-2 country code
+	Country string `json:"country"`
+}
+
+// TravelRuleParty identifies the originator or beneficiary of a transfer. Natural
+// persons are identified by name plus address, date of birth or national ID; legal
+// persons by name plus address or LEI.
+type TravelRuleParty struct {
+	// Type is NaturalPerson or LegalPerson
+	Type string `json:"type"`
+	Name string `json:"name"`
+	// WalletAddress is the party's blockchain address, if known
+	WalletAddress string         `json:"wallet_address,omitempty"`
+	Address       *PostalAddress `json:"address,omitempty"`
+	// DateOfBirth is formatted as YYYY-MM-DD
+	DateOfBirth string `json:"date_of_birth,omitempty"`
+	NationalID  string `json:"national_id,omitempty"`
+	// CustomerID is your own identifier of the party
+	CustomerID string `json:"customer_id,omitempty"`
+	// LEI is the Legal Entity Identifier of a legal person
+	LEI string `json:"lei,omitempty"`
+	// VASPName and VASPLEI identify the party's virtual asset service provider, if the
+	// funds come from or go to a hosted wallet
+	VASPName string `json:"vasp_name,omitempty"`
+	VASPLEI  string `json:"vasp_lei,omitempty"`
+}
+
+// TravelRuleInfo is the originator and beneficiary information the EU Transfer of Funds
+// Regulation requires VASPs to exchange for crypto transfers
+type TravelRuleInfo struct {
+	Originator  *TravelRuleParty `json:"originator,omitempty"`
+	Beneficiary *TravelRuleParty `json:"beneficiary,omitempty"`
+}
+
+// TravelRuleCompliance is the compliance status of an invoice or payout
+type TravelRuleCompliance struct {
+	// Status is one of the Compliance* constants
+	Status string `json:"status"`
+	// MissingFields lists the fields to attach for ComplianceInfoRequired, e.g.
+	// "originator.address"
+	MissingFields []string `json:"missing_fields,omitempty"`
+	// Reason explains a rejection
+	Reason     string          `json:"reason,omitempty"`
+	TravelRule *TravelRuleInfo `json:"travel_rule,omitempty"`
+	UpdatedAt  time.Time       `json:"updated_at"`
+}
+
+// AttachInvoiceTravelRule attaches travel rule information to an existing invoice, e.g.
+// the originator once the payer is known
+func (c *Client) AttachInvoiceTravelRule(ctx context.Context, invoiceID string, info TravelRuleInfo, opts ...RequestOption) (*TravelRuleCompliance, error) {
+	if err := info.Validate(); err != nil {
+		return nil, err
+	}
+	return do[TravelRuleCompliance](ctx, c, "PUT", "/invoices/"+url.PathEscape(invoiceID)+"/travel-rule", info, opts...)
+}
+
+// GetInvoiceCompliance retrieves the compliance status of an invoice
+func (c *Client) GetInvoiceCompliance(ctx context.Context, invoiceID string, opts ...RequestOption) (*TravelRuleCompliance, error) {
+	return do[TravelRuleCompliance](ctx, c, "GET", "/invoices/"+url.PathEscape(invoiceID)+"/compliance", nil, opts...)
+}
+
+// AttachPayoutTravelRule attaches travel rule information to an existing payout
+func (c *Client) AttachPayoutTravelRule(ctx context.Context, payoutID string, info TravelRuleInfo, opts ...RequestOption) (*TravelRuleCompliance, error) {
+	if err := info.Validate(); err != nil {
+		return nil, err
+	}
+	return do[TravelRuleCompliance](ctx, c, "PUT", "/payouts/"+url.PathEscape(payoutID)+"/travel-rule", info, opts...)
+}
+
+// GetPayoutCompliance retrieves the compliance status of a payout. Payouts with a status
+// other than ComplianceApproved or ComplianceNotRequired are held.
+func (c *Client) GetPayoutCompliance(ctx context.Context, payoutID string, opts ...RequestOption) (*TravelRuleCompliance, error) {
+	return do[TravelRuleCompliance](ctx, c, "GET", "/payouts/"+url.PathEscape(payoutID)+"/compliance", nil, opts...)
+}
+
+// Validate checks that every party present has a type, a name and an identifying detail
+func (info *TravelRuleInfo) Validate() error {
+	if info.Originator == nil && info.Beneficiary == nil {
+		return fmt.Errorf("%w: originator or beneficiary is required", ErrInvalidTravelRule)
+	}
+	if err := info.Originator.validate("originator"); err != nil {
+		return err
+	}
+	return info.Beneficiary.validate("beneficiary")
+}
+
+// validate checks a party; a nil party is valid
+func (p *TravelRuleParty) validate(role string) error {
+	if p == nil {
+		return nil
+	}
+	if p.Name == "" {
+		return fmt.Errorf("%w: %s name is required", ErrInvalidTravelRule, role)
+	}
+	if p.Address != nil && len(p.Address.Country) != 2 {
+		return fmt.Errorf("%w: %s country must be an ISO 3166-1 alpha-2 code", ErrInvalidTravelRule, role)
+	}
+	if p.LEI != "" && len(p.LEI) != 20 {
+		return fmt.Errorf("%w: %s LEI must have 20 characters", ErrInvalidTravelRule, role)
+	}
+
+	switch p.Type {
+	case NaturalPerson:
+		if p.Address == nil && p.DateOfBirth == "" && p.NationalID == "" {
+			return fmt.Errorf("%w: %s needs an address, date of birth or national ID", ErrInvalidTravelRule, role)
+		}
+		if p.DateOfBirth != "" {
+			if _, err := time.Parse("2006-01-02", p.DateOfBirth); err != nil {
+				return fmt.Errorf("%w: %s date of birth must be YYYY-MM-DD", ErrInvalidTravelRule, role)
+			}
+		}
+	case LegalPerson:
+		if p.Address == nil && p.LEI == "" {
+			return fmt.Errorf("%w: %s needs an address or LEI", ErrInvalidTravelRule, role)
+		}
+	default:
+		return fmt.Errorf("%w: %s type must be %s or %s", ErrInvalidTravelRule, role, NaturalPerson, LegalPerson)
+	}
+	return nil
+}
@@ -2,16 +2,19 @@ package itispay
 
 import (
 	"time"
+
+	"github.com/ItIsPay/go-client/types"
 )
 
-// Invoice status constants
+// Invoice status constants. They are untyped so they can be used both as Status
+// values and as plain strings in request parameters.
 const (
-	StatusNew         = "new"
-	StatusPending     = "pending"
-	StatusCompleted   = "completed"
-	StatusExpired     = "expired"
-	StatusCancelled   = "cancelled"
-	StatusPaidPartial = "paid_partial"
+	StatusNew         = types.StatusNew
+	StatusPending     = types.StatusPending
+	StatusCompleted   = types.StatusCompleted
+	StatusExpired     = types.StatusExpired
+	StatusCancelled   = types.StatusCancelled
+	StatusPaidPartial = types.StatusPaidPartial
 )
 
 // Sort order constants
@@ -34,11 +37,33 @@ type CreateInvoiceRequest struct {
 	FiatAmount          *float64 `json:"fiat_amount,omitempty"`
 	FiatCurrency        string   `json:"fiat_currency,omitempty"`
 	CryptoAmount        *float64 `json:"crypto_amount,omitempty"`
-	Currency            string   `json:"currency"`
+	Currency            string   `json:"currency,omitempty"`
 	AllowedErrorPercent *int     `json:"allowed_error_percent,omitempty"`
 	OrderName           string   `json:"order_name,omitempty"`
 	ExpireMin           *int     `json:"expire_min,omitempty"`
+	GracePeriodMin      *int     `json:"grace_period_min,omitempty"`
 	CallbackURL         string   `json:"callback_url,omitempty"`
+	// Currencies offers several currencies on one invoice, letting the buyer choose;
+	// use it instead of Currency, with FiatAmount
+	Currencies []string `json:"currencies,omitempty"`
+	// ExternalRefs correlates the invoice with your own entities, e.g.
+	// {"customer_id": "c-42", "cart_id": "8812"}; see FindByExternalRef
+	ExternalRefs map[string]string `json:"external_refs,omitempty"`
+	// Metadata holds your own key-value data, returned on the invoice and in webhooks;
+	// see ValidateMetadata for the limits
+	Metadata map[string]string `json:"metadata,omitempty"`
+	// RequiredConfirmations is the number of blockchain confirmations needed before the
+	// invoice completes, e.g. more for high-value orders; the currency default if nil
+	RequiredConfirmations *int `json:"required_confirmations,omitempty"`
+	// CustomerID associates the invoice with a customer created by CreateCustomer; see
+	// ListCustomerInvoices
+	CustomerID string `json:"customer_id,omitempty"`
+	// AutoConvertTo converts the payment to another currency on settlement, e.g. "USDT"
+	// or "EUR", so the merchant holds no exposure to the paid coin
+	AutoConvertTo string `json:"auto_convert_to,omitempty"`
+	// TravelRule carries originator and beneficiary information for regulated transfers;
+	// see GetInvoiceCompliance
+	TravelRule *TravelRuleInfo `json:"travel_rule,omitempty"`
 }
 
 // UpdateInvoiceRequest represents the request to update an invoice
@@ -56,6 +81,8 @@ type ListInvoicesParams struct {
 	CreatedBefore time.Time `json:"created_before,omitempty"`
 	SortBy        string    `json:"sort_by,omitempty"`
 	SortOrder     string    `json:"sort_order,omitempty"`
+	// ExternalRefs filters invoices carrying all the given external references
+	ExternalRefs map[string]string `json:"external_refs,omitempty"`
 }
 
 // WebhookSimulateRequest represents the request to simulate a webhook
@@ -68,6 +95,7 @@ type WebhookSimulateRequest struct {
 type Invoice struct {
 	InvoiceID                     string             `json:"invoice_id"`
 	UserID                        string             `json:"user_id"`
+	CustomerID                    string             `json:"customer_id,omitempty"`
 	ProjectID                     string             `json:"project_id"`
 	OrderID                       string             `json:"order_id"`
 	FiatAmount                    float64            `json:"fiat_amount"`
@@ -81,28 +109,59 @@ type Invoice struct {
 	OrderName                     string             `json:"order_name"`
 	ExpireMin                     int                `json:"expire_min"`
 	CallbackURL                   string             `json:"callback_url"`
-	Status                        string             `json:"status"`
+	Status                        Status             `json:"status"`
+	TestMode                      bool               `json:"test_mode"`
 	CreatedAt                     time.Time          `json:"created_at"`
 	UpdatedAt                     time.Time          `json:"updated_at"`
 	ExpiresAt                     time.Time          `json:"expires_at"`
+	GracePeriodMin                int                `json:"grace_period_min"`
+	RequiredConfirmations         int                `json:"required_confirmations"`
+	CurrentConfirmations          int                `json:"current_confirmations"`
+	ExternalRefs                  map[string]string  `json:"external_refs,omitempty"`
+	Metadata                      map[string]string  `json:"metadata,omitempty"`
 	BlockchainDetails             *BlockchainDetails `json:"blockchain_details,omitempty"`
-}
+	// CheckoutURL is the hosted payment page of the invoice, if the account has one;
+	// see CheckoutLink
+	CheckoutURL string `json:"checkout_url,omitempty"`
+	// PaymentOptions lists the amount and address per currency of a multi-currency
+	// invoice. Currency is empty until the buyer pays in one of them.
+	PaymentOptions []PaymentOption `json:"payment_options,omitempty"`
+	// Transactions are the on-chain transactions paying the invoice, if any
+	Transactions []Transaction `json:"transactions,omitempty"`
+	// AutoConvertTo is the currency the payment is converted to on settlement, if any
+	AutoConvertTo string `json:"auto_convert_to,omitempty"`
+	// Conversion is the result of the automatic conversion, once it started
+	Conversion *Conversion `json:"conversion,omitempty"`
+	// RiskScore is the AML risk score of the funds received, from 0 to 100, if the
+	// payment was scored
+	RiskScore *float64 `json:"risk_score,omitempty"`
+	// RiskFlags lists the sources of risk found in the funds received
+	RiskFlags []RiskFlag `json:"risk_flags,omitempty"`
+	// ReissuedFrom is the ID of the invoice this one replaces; see ReissueInvoice
+	ReissuedFrom string `json:"reissued_from,omitempty"`
+	// ReissuedAs is the ID of the invoice replacing this one, if it was reissued
+	ReissuedAs string `json:"reissued_as,omitempty"`
+}
+
+// Conversion is the automatic conversion of an invoice payment into the currency
+// requested with AutoConvertTo
+type Conversion = types.Conversion
+
+// ConversionStatus is the status of an automatic conversion
+type ConversionStatus = types.ConversionStatus
+
+// Conversion status constants
+const (
+	ConversionPending   = types.ConversionPending
+	ConversionCompleted = types.ConversionCompleted
+	ConversionFailed    = types.ConversionFailed
+)
 
 // BlockchainDetails represents blockchain information for an invoice
-type BlockchainDetails struct {
-	WalletID          string             `json:"walletId"`
-	AccountID         string             `json:"accountId"`
-	Currency          string             `json:"currency"`
-	BlockchainAddress string             `json:"blockchainAddress"`
-	BlockchainNetwork *BlockchainNetwork `json:"blockchainNetwork,omitempty"`
-	QRCode            string             `json:"qrcode,omitempty"`
-}
+type BlockchainDetails = types.BlockchainDetails
 
 // BlockchainNetwork represents blockchain network information
-type BlockchainNetwork struct {
-	Name string `json:"name"`
-	Type string `json:"type"`
-}
+type BlockchainNetwork = types.BlockchainNetwork
 
 // ListInvoicesResponse represents the response from listing invoices
 type ListInvoicesResponse struct {
@@ -148,22 +207,7 @@ type WebhookSimulateResponse struct {
 }
 
 // ErrorResponse represents an API error response
-type ErrorResponse struct {
-	Error   string `json:"error"`
-	Message string `json:"message"`
-}
+type ErrorResponse = types.ErrorResponse
 
 // APIError represents an API error
-type APIError struct {
-	StatusCode int
-	ErrorType  string
-	Message    string
-}
-
-// Error returns the error message
-func (e *APIError) Error() string {
-	if e.Message != "" {
-		return e.Message
-	}
-	return e.ErrorType
-}
+type APIError = types.APIError
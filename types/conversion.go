@@ -0,0 +1,29 @@
+package types
+
+import "time"
+
+// ConversionStatus is the status of the automatic conversion of an invoice payment
+type ConversionStatus string
+
+// Conversion status constants
+const (
+	ConversionPending   ConversionStatus = "pending"
+	ConversionCompleted ConversionStatus = "completed"
+	ConversionFailed    ConversionStatus = "failed"
+)
+
+// Conversion is the automatic conversion of an invoice payment into the currency
+// requested with AutoConvertTo
+type Conversion struct {
+	FromCurrency string           `json:"from_currency"`
+	ToCurrency   string           `json:"to_currency"`
+	FromAmount   float64          `json:"from_amount"`
+	ToAmount     float64          `json:"to_amount"`
+	Rate         float64          `json:"rate"`
+	Fee          float64          `json:"fee"`
+	Status       ConversionStatus `json:"status"`
+	// FailureReason explains a ConversionFailed conversion, in which case the payment
+	// stays in the invoice currency
+	FailureReason string     `json:"failure_reason,omitempty"`
+	ConvertedAt   *time.Time `json:"converted_at,omitempty"`
+}
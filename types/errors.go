@@ -0,0 +1,89 @@
+package types
+
+// ErrorCode is a machine-readable API error code
+type ErrorCode string
+
+// API error codes. Codes not listed here may be added by the API at any time, so
+// handle unknown codes by status code.
+const (
+	ErrCodeInvalidRequest      ErrorCode = "invalid_request"
+	ErrCodeUnauthorized        ErrorCode = "unauthorized"
+	ErrCodeForbidden           ErrorCode = "forbidden"
+	ErrCodeNotFound            ErrorCode = "not_found"
+	ErrCodeRateLimited         ErrorCode = "rate_limited"
+	ErrCodeInternal            ErrorCode = "internal_error"
+	ErrCodeServiceUnavailable  ErrorCode = "service_unavailable"
+	ErrCodeInvalidStatus       ErrorCode = "invalid_status"
+	ErrCodeInvalidCurrency     ErrorCode = "invalid_currency"
+	ErrCodeUnsupportedCurrency ErrorCode = "unsupported_currency"
+	ErrCodeInvalidNetwork      ErrorCode = "invalid_network"
+	ErrCodeInvalidAddress      ErrorCode = "invalid_address"
+	ErrCodeAmountTooSmall      ErrorCode = "amount_too_small"
+	ErrCodeAmountTooLarge      ErrorCode = "amount_too_large"
+	ErrCodeDuplicateOrderID    ErrorCode = "duplicate_order_id"
+	ErrCodeInvoiceExpired      ErrorCode = "invoice_expired"
+	ErrCodeInsufficientBalance ErrorCode = "insufficient_balance"
+	ErrCodeIdempotencyConflict ErrorCode = "idempotency_conflict"
+	ErrCodeQuoteExpired        ErrorCode = "quote_expired"
+	ErrCodeSessionRequired     ErrorCode = "session_required"
+	ErrCodeSessionExpired      ErrorCode = "session_expired"
+	ErrCodeStepUpRequired      ErrorCode = "step_up_required"
+)
+
+// ErrorResponse represents an API error response
+type ErrorResponse struct {
+	Error   string `json:"error"`
+	Message string `json:"message"`
+	// Code refines Error, e.g. "amount_too_small" for an "invalid_request" error; empty
+	// if the error type is specific enough
+	Code ErrorCode `json:"code,omitempty"`
+	// LocalizedMessage is Message in the language requested with Accept-Language, if
+	// available
+	LocalizedMessage string `json:"localized_message,omitempty"`
+}
+
+// APIError represents an API error
+type APIError struct {
+	StatusCode int
+	ErrorType  string
+	Message    string
+	// Code is the most specific error code of the response: its Code if present,
+	// otherwise its ErrorType
+	Code ErrorCode
+	// LocalizedMessage is the message in the requested language, for display to users;
+	// empty if no translation is available
+	LocalizedMessage string
+	// Language is the language of LocalizedMessage, from the Content-Language header
+	Language string
+}
+
+// Error returns the error message
+func (e *APIError) Error() string {
+	if e.Message != "" {
+		return e.Message
+	}
+	return e.ErrorType
+}
+
+// DisplayMessage returns the localized message if available, otherwise Message
+func (e *APIError) DisplayMessage() string {
+	if e.LocalizedMessage != "" {
+		return e.LocalizedMessage
+	}
+	return e.Message
+}
+
+// NewAPIError returns the error for a response with the given status code
+func NewAPIError(statusCode int, response ErrorResponse) *APIError {
+	code := response.Code
+	if code == "" {
+		code = ErrorCode(response.Error)
+	}
+	return &APIError{
+		StatusCode:       statusCode,
+		ErrorType:        response.Error,
+		Message:          response.Message,
+		Code:             code,
+		LocalizedMessage: response.LocalizedMessage,
+	}
+}
@@ -0,0 +1,10 @@
+package types
+
+// Event type constants
+const (
+	EventInvoiceStatusChanged = "invoice.status_changed"
+	EventInvoiceCreated       = "invoice.created"
+	EventInvoiceCompleted     = "invoice.completed"
+	EventPayoutSent           = "payout.sent"
+	EventFiatPayoutUpdated    = "fiat_payout.updated"
+)
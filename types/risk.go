@@ -0,0 +1,35 @@
+package types
+
+// DefaultHighRiskScore is the risk score from which IsHighRisk reports a payment as high
+// risk when no threshold is given. Scores range from 0 (no known risk) to 100.
+const DefaultHighRiskScore = 75
+
+// RiskFlag names a source of risk found in the funds paying an invoice
+type RiskFlag string
+
+// Risk flag constants
+const (
+	RiskFlagSanctions    RiskFlag = "sanctions"
+	RiskFlagMixer        RiskFlag = "mixer"
+	RiskFlagDarknet      RiskFlag = "darknet_market"
+	RiskFlagStolenFunds  RiskFlag = "stolen_funds"
+	RiskFlagRansomware   RiskFlag = "ransomware"
+	RiskFlagScam         RiskFlag = "scam"
+	RiskFlagGambling     RiskFlag = "gambling"
+	RiskFlagHighRiskVASP RiskFlag = "high_risk_exchange"
+)
+
+// IsHighRisk reports whether the payment's risk score is at least threshold
+// (DefaultHighRiskScore if zero) or the funds are flagged for sanctions. Unscored
+// payments are not high risk.
+func (p *WebhookPayload) IsHighRisk(threshold float64) bool {
+	if threshold <= 0 {
+		threshold = DefaultHighRiskScore
+	}
+	for _, flag := range p.RiskFlags {
+		if flag == RiskFlagSanctions {
+			return true
+		}
+	}
+	return p.RiskScore != nil && *p.RiskScore >= threshold
+}
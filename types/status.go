@@ -0,0 +1,155 @@
+// Package types holds the ItIsPay data definitions shared across services: invoice
+// statuses, API errors, webhook payloads and event types. It depends only on the
+// standard library, so services that only exchange payloads need not import the HTTP
+// client. The itispay package re-exports everything declared here.
+package types
+
+import (
+	"strings"
+	"sync"
+)
+
+// Invoice status constants. They are untyped so they can be used both as Status
+// values and as plain strings in request parameters.
+const (
+	StatusNew         = "new"
+	StatusPending     = "pending"
+	StatusCompleted   = "completed"
+	StatusExpired     = "expired"
+	StatusCancelled   = "cancelled"
+	StatusPaidPartial = "paid_partial"
+)
+
+// Status represents the status of an invoice
+type Status string
+
+// DefaultLocale is the locale used when no description is available for the requested one
+const DefaultLocale = "en"
+
+var (
+	statusDescriptionsMu sync.RWMutex
+	statusDescriptions   = map[string]map[Status]string{
+		"en": {
+			StatusNew:         "Awaiting payment",
+			StatusPending:     "Payment detected, awaiting confirmation",
+			StatusCompleted:   "Payment completed",
+			StatusExpired:     "Invoice expired",
+			StatusCancelled:   "Invoice cancelled",
+			StatusPaidPartial: "Partially paid",
+		},
+		"de": {
+			StatusNew:         "Zahlung ausstehend",
+			StatusPending:     "Zahlung erkannt, warte auf Bestätigung",
+			StatusCompleted:   "Zahlung abgeschlossen",
+			StatusExpired:     "Rechnung abgelaufen",
+			StatusCancelled:   "Rechnung storniert",
+			StatusPaidPartial: "Teilweise bezahlt",
+		},
+		"fr": {
+			StatusNew:         "En attente de paiement",
+			StatusPending:     "Paiement détecté, en attente de confirmation",
+			StatusCompleted:   "Paiement effectué",
+			StatusExpired:     "Facture expirée",
+			StatusCancelled:   "Facture annulée",
+			StatusPaidPartial: "Partiellement payée",
+		},
+		"es": {
+			StatusNew:         "Pendiente de pago",
+			StatusPending:     "Pago detectado, esperando confirmación",
+			StatusCompleted:   "Pago completado",
+			StatusExpired:     "Factura vencida",
+			StatusCancelled:   "Factura cancelada",
+			StatusPaidPartial: "Pagada parcialmente",
+		},
+		"it": {
+			StatusNew:         "In attesa di pagamento",
+			StatusPending:     "Pagamento rilevato, in attesa di conferma",
+			StatusCompleted:   "Pagamento completato",
+			StatusExpired:     "Fattura scaduta",
+			StatusCancelled:   "Fattura annullata",
+			StatusPaidPartial: "Pagata parzialmente",
+		},
+		"pt": {
+			StatusNew:         "Aguardando pagamento",
+			StatusPending:     "Pagamento detectado, aguardando confirmação",
+			StatusCompleted:   "Pagamento concluído",
+			StatusExpired:     "Fatura expirada",
+			StatusCancelled:   "Fatura cancelada",
+			StatusPaidPartial: "Parcialmente paga",
+		},
+		"nl": {
+			StatusNew:         "In afwachting van betaling",
+			StatusPending:     "Betaling gedetecteerd, wacht op bevestiging",
+			StatusCompleted:   "Betaling voltooid",
+			StatusExpired:     "Factuur verlopen",
+			StatusCancelled:   "Factuur geannuleerd",
+			StatusPaidPartial: "Gedeeltelijk betaald",
+		},
+		"ru": {
+			StatusNew:         "Ожидает оплаты",
+			StatusPending:     "Платёж обнаружен, ожидает подтверждения",
+			StatusCompleted:   "Оплачено",
+			StatusExpired:     "Срок действия счёта истёк",
+			StatusCancelled:   "Счёт отменён",
+			StatusPaidPartial: "Оплачено частично",
+		},
+	}
+)
+
+// String returns the raw status value
+func (s Status) String() string {
+	return string(s)
+}
+
+// Description returns a human-readable description of the status in the given locale.
+// Locales are matched as full tags first ("pt-BR"), then by language ("pt"), falling
+// back to DefaultLocale. Unknown statuses are returned as-is.
+func (s Status) Description(locale string) string {
+	statusDescriptionsMu.RLock()
+	defer statusDescriptionsMu.RUnlock()
+
+	for _, candidate := range localeCandidates(locale) {
+		if descriptions, ok := statusDescriptions[candidate]; ok {
+			if description, ok := descriptions[s]; ok {
+				return description
+			}
+		}
+	}
+	return string(s)
+}
+
+// RegisterStatusDescriptions adds or overrides status descriptions for a locale.
+// Entries are merged with any existing descriptions for that locale.
+func RegisterStatusDescriptions(locale string, descriptions map[Status]string) {
+	locale = normalizeLocale(locale)
+
+	statusDescriptionsMu.Lock()
+	defer statusDescriptionsMu.Unlock()
+
+	existing, ok := statusDescriptions[locale]
+	if !ok {
+		existing = make(map[Status]string, len(descriptions))
+		statusDescriptions[locale] = existing
+	}
+	for status, description := range descriptions {
+		existing[status] = description
+	}
+}
+
+// normalizeLocale converts a locale such as "pt_BR" to its lowercase tag form "pt-br"
+func normalizeLocale(locale string) string {
+	return strings.ToLower(strings.ReplaceAll(strings.TrimSpace(locale), "_", "-"))
+}
+
+// localeCandidates returns the lookup order for a locale
+func localeCandidates(locale string) []string {
+	locale = normalizeLocale(locale)
+	candidates := make([]string, 0, 3)
+	if locale != "" {
+		candidates = append(candidates, locale)
+		if i := strings.IndexByte(locale, '-'); i > 0 {
+			candidates = append(candidates, locale[:i])
+		}
+	}
+	return append(candidates, DefaultLocale)
+}
@@ -0,0 +1,75 @@
+package types
+
+import (
+	"encoding/json"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// explorerTxURLs maps currency codes to block explorer transaction URL prefixes
+var explorerTxURLs = map[string]string{
+	"BTC":  "https://mempool.space/tx/",
+	"LTC":  "https://litecoinspace.org/tx/",
+	"BCH":  "https://blockchair.com/bitcoin-cash/transaction/",
+	"DOGE": "https://blockchair.com/dogecoin/transaction/",
+	"DASH": "https://blockchair.com/dash/transaction/",
+	"ETH":  "https://etherscan.io/tx/",
+	"TRX":  "https://tronscan.org/#/transaction/",
+	"SOL":  "https://solscan.io/tx/",
+}
+
+// BlockchainDetails represents blockchain information for an invoice
+type BlockchainDetails struct {
+	WalletID          string             `json:"walletId"`
+	AccountID         string             `json:"accountId"`
+	Currency          string             `json:"currency"`
+	BlockchainAddress string             `json:"blockchainAddress"`
+	BlockchainNetwork *BlockchainNetwork `json:"blockchainNetwork,omitempty"`
+	QRCode            string             `json:"qrcode,omitempty"`
+}
+
+// BlockchainNetwork represents blockchain network information
+type BlockchainNetwork struct {
+	Name string `json:"name"`
+	Type string `json:"type"`
+}
+
+// Transaction is an on-chain transaction paying an invoice
+type Transaction struct {
+	TxHash        string  `json:"tx_hash"`
+	Amount        float64 `json:"amount"`
+	AmountInUnits int64   `json:"amount_in_units"`
+	Confirmations int     `json:"confirmations"`
+	BlockHeight   int64   `json:"block_height,omitempty"`
+	// BlockTime is the timestamp of the including block, zero while unconfirmed
+	BlockTime  time.Time `json:"block_time"`
+	DetectedAt time.Time `json:"detected_at"`
+}
+
+// UnmarshalJSON decodes a transaction, accepting the timestamp formats of WebhookPayload
+func (t *Transaction) UnmarshalJSON(data []byte) error {
+	type plain Transaction
+	aux := struct {
+		*plain
+		BlockTime  webhookTime `json:"block_time"`
+		DetectedAt webhookTime `json:"detected_at"`
+	}{plain: (*plain)(t)}
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+	t.BlockTime = time.Time(aux.BlockTime)
+	t.DetectedAt = time.Time(aux.DetectedAt)
+	return nil
+}
+
+// ExplorerURL returns a block explorer link for the transaction, or an empty string for
+// currencies without a known explorer. Tokens use the explorer of their network, e.g.
+// "TRX" for USDT on Tron.
+func (t *Transaction) ExplorerURL(currency string) string {
+	prefix, ok := explorerTxURLs[strings.ToUpper(currency)]
+	if !ok || t.TxHash == "" {
+		return ""
+	}
+	return prefix + url.PathEscape(t.TxHash)
+}
@@ -0,0 +1,168 @@
+package types
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// WebhookPayload is the body of a webhook callback sent when an invoice changes status
+type WebhookPayload struct {
+	// EventID uniquely identifies the event, if provided
+	EventID                       string            `json:"event_id,omitempty"`
+	InvoiceID                     string            `json:"invoice_id"`
+	Status                        Status            `json:"status"`
+	OrderID                       string            `json:"order_id"`
+	CustomerID                    string            `json:"customer_id,omitempty"`
+	Currency                      string            `json:"currency"`
+	CryptoAmount                  float64           `json:"crypto_amount"`
+	FiatAmount                    float64           `json:"fiat_amount"`
+	FiatCurrency                  string            `json:"fiat_currency"`
+	ActualCryptoAmountPaid        float64           `json:"actual_crypto_amount_paid"`
+	ActualCryptoAmountPaidInUnits int64             `json:"actual_crypto_amount_paid_in_units"`
+	AllowedErrorPercent           int               `json:"allowed_error_percent"`
+	RequiredConfirmations         int               `json:"required_confirmations"`
+	CurrentConfirmations          int               `json:"current_confirmations"`
+	ExternalRefs                  map[string]string `json:"external_refs,omitempty"`
+	Metadata                      map[string]string `json:"metadata,omitempty"`
+	TestMode                      bool              `json:"test_mode"`
+	CreatedAt                     time.Time         `json:"created_at"`
+	UpdatedAt                     time.Time         `json:"updated_at"`
+	ExpiresAt                     time.Time         `json:"expires_at"`
+	// PaymentRate is the fiat price of one unit of Currency when the payment was
+	// received, if provided
+	PaymentRate float64 `json:"payment_rate,omitempty"`
+	// ActualFiatAmountPaid is the fiat value of the paid amount at PaymentRate, if provided
+	ActualFiatAmountPaid float64 `json:"actual_fiat_amount_paid,omitempty"`
+	// AutoConvertTo is the currency the payment is converted to on settlement, if any
+	AutoConvertTo string `json:"auto_convert_to,omitempty"`
+	// Conversion is the result of the automatic conversion, once it started
+	Conversion *Conversion `json:"conversion,omitempty"`
+	// RiskScore is the AML risk score of the funds received, from 0 to 100, if the
+	// payment was scored
+	RiskScore *float64 `json:"risk_score,omitempty"`
+	// RiskFlags lists the sources of risk found in the funds received
+	RiskFlags []RiskFlag `json:"risk_flags,omitempty"`
+	// Transactions are the blockchain transactions paying the invoice, if any
+	Transactions      []WebhookTransaction `json:"transactions,omitempty"`
+	BlockchainDetails *BlockchainDetails   `json:"blockchain_details,omitempty"`
+	// ReceivedAt is the local time the webhook was received, set by itispay.ParseWebhook
+	ReceivedAt time.Time `json:"-"`
+}
+
+// WebhookTransaction is a blockchain transaction reported in a webhook
+type WebhookTransaction = Transaction
+
+// UnmarshalJSON decodes a payload, accepting timestamps as RFC 3339 strings,
+// "2006-01-02 15:04:05" strings (UTC) or Unix seconds
+func (p *WebhookPayload) UnmarshalJSON(data []byte) error {
+	type plain WebhookPayload
+	aux := struct {
+		*plain
+		CreatedAt webhookTime `json:"created_at"`
+		UpdatedAt webhookTime `json:"updated_at"`
+		ExpiresAt webhookTime `json:"expires_at"`
+	}{plain: (*plain)(p)}
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+	p.CreatedAt = time.Time(aux.CreatedAt)
+	p.UpdatedAt = time.Time(aux.UpdatedAt)
+	p.ExpiresAt = time.Time(aux.ExpiresAt)
+	return nil
+}
+
+// Latency returns the delay between the invoice update and the receipt of the webhook.
+// Negative values caused by clock skew are reported as zero, and zero is returned if
+// either time is unknown.
+func (p *WebhookPayload) Latency() time.Duration {
+	if p.UpdatedAt.IsZero() || p.ReceivedAt.IsZero() {
+		return 0
+	}
+	latency := p.ReceivedAt.Sub(p.UpdatedAt)
+	if latency < 0 {
+		return 0
+	}
+	return latency
+}
+
+// Rate returns the fiat price of one unit of Currency used to value the payment:
+// PaymentRate if the webhook carries it, otherwise the rate the invoice was quoted at
+// (the same rate itispay.NewWebhookRateSnapshot records)
+func (p *WebhookPayload) Rate() float64 {
+	if p.PaymentRate > 0 {
+		return p.PaymentRate
+	}
+	if p.CryptoAmount > 0 {
+		return p.FiatAmount / p.CryptoAmount
+	}
+	return 0
+}
+
+// FiatPaid returns the fiat value of the amount actually paid, in FiatCurrency.
+// It is not rounded to the currency's minor unit.
+func (p *WebhookPayload) FiatPaid() float64 {
+	if p.ActualFiatAmountPaid > 0 {
+		return p.ActualFiatAmountPaid
+	}
+	return p.ActualCryptoAmountPaid * p.Rate()
+}
+
+// FiatPaidAt returns the fiat value of the amount actually paid at rate, e.g. a
+// historical rate looked up for the payment time
+func (p *WebhookPayload) FiatPaidAt(rate float64) float64 {
+	return p.ActualCryptoAmountPaid * rate
+}
+
+// FiatShortfall returns how much fiat value is missing from a partial payment, or zero
+// if the invoice was paid in full
+func (p *WebhookPayload) FiatShortfall() float64 {
+	shortfall := p.FiatAmount - p.FiatPaid()
+	if shortfall < 0 {
+		return 0
+	}
+	return shortfall
+}
+
+// ConfirmationsRemaining returns the number of confirmations the payment still needs
+// before the invoice completes, 0 once the threshold is reached
+func (p *WebhookPayload) ConfirmationsRemaining() int {
+	if p.CurrentConfirmations >= p.RequiredConfirmations {
+		return 0
+	}
+	return p.RequiredConfirmations - p.CurrentConfirmations
+}
+
+// webhookTime is a timestamp tolerant of the formats used in webhooks
+type webhookTime time.Time
+
+// UnmarshalJSON implements json.Unmarshaler
+func (t *webhookTime) UnmarshalJSON(data []byte) error {
+	s := string(data)
+	if s == "null" || s == `""` {
+		*t = webhookTime{}
+		return nil
+	}
+	if !strings.HasPrefix(s, `"`) {
+		seconds, err := strconv.ParseInt(s, 10, 64)
+		if err != nil {
+			return fmt.Errorf("invalid timestamp %s", s)
+		}
+		*t = webhookTime(time.Unix(seconds, 0).UTC())
+		return nil
+	}
+
+	s, err := strconv.Unquote(s)
+	if err != nil {
+		return err
+	}
+	for _, layout := range []string{time.RFC3339Nano, "2006-01-02 15:04:05", "2006-01-02T15:04:05"} {
+		if parsed, err := time.Parse(layout, s); err == nil {
+			*t = webhookTime(parsed)
+			return nil
+		}
+	}
+	return fmt.Errorf("invalid timestamp %q", s)
+}
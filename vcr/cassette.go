@@ -0,0 +1,124 @@
+package vcr
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+)
+
+// cassetteVersion is the format version written to cassettes
+const cassetteVersion = 1
+
+// cassette is the file format of recorded interactions
+type cassette struct {
+	Version      int            `json:"version"`
+	Interactions []*Interaction `json:"interactions"`
+}
+
+// Interaction is a recorded request and its response
+type Interaction struct {
+	Request  RecordedRequest  `json:"request"`
+	Response RecordedResponse `json:"response"`
+}
+
+// RecordedRequest is a redacted request. URL holds the path and query only, so cassettes
+// replay against any base URL.
+type RecordedRequest struct {
+	Method string          `json:"method"`
+	URL    string          `json:"url"`
+	Header http.Header     `json:"header,omitempty"`
+	Body   json.RawMessage `json:"body,omitempty"`
+	// BodyText holds bodies that are not JSON
+	BodyText string `json:"body_text,omitempty"`
+}
+
+// RecordedResponse is a redacted response
+type RecordedResponse struct {
+	StatusCode int             `json:"status_code"`
+	Header     http.Header     `json:"header,omitempty"`
+	Body       json.RawMessage `json:"body,omitempty"`
+	BodyText   string          `json:"body_text,omitempty"`
+}
+
+// matches reports whether a recorded request answers req
+func (r RecordedRequest) matches(req RecordedRequest) bool {
+	return r.Method == req.Method && r.URL == req.URL &&
+		r.BodyText == req.BodyText && bytes.Equal(r.Body, req.Body)
+}
+
+// toHTTP builds the response to req
+func (r RecordedResponse) toHTTP(req *http.Request) *http.Response {
+	body := []byte(r.BodyText)
+	if len(r.Body) > 0 {
+		body = r.Body
+	}
+	header := r.Header.Clone()
+	if header == nil {
+		header = http.Header{}
+	}
+	header.Set("Content-Length", strconv.Itoa(len(body)))
+	return &http.Response{
+		Status:        strconv.Itoa(r.StatusCode) + " " + http.StatusText(r.StatusCode),
+		StatusCode:    r.StatusCode,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          io.NopCloser(bytes.NewReader(body)),
+		ContentLength: int64(len(body)),
+		Request:       req,
+	}
+}
+
+// loadCassette reads the cassette at path
+func loadCassette(path string) (*cassette, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("vcr: %w", err)
+	}
+	var c cassette
+	if err := json.Unmarshal(data, &c); err != nil {
+		return nil, fmt.Errorf("vcr: decoding %s: %w", path, err)
+	}
+	if c.Version != cassetteVersion {
+		return nil, fmt.Errorf("vcr: %s has unsupported version %d", path, c.Version)
+	}
+	// Bodies are indented in the file but compared compacted
+	for _, interaction := range c.Interactions {
+		interaction.Request.Body = compact(interaction.Request.Body)
+		interaction.Response.Body = compact(interaction.Response.Body)
+	}
+	return &c, nil
+}
+
+// compact removes insignificant whitespace from a JSON body
+func compact(body json.RawMessage) json.RawMessage {
+	if len(body) == 0 {
+		return body
+	}
+	var buf bytes.Buffer
+	if err := json.Compact(&buf, body); err != nil {
+		return body
+	}
+	return buf.Bytes()
+}
+
+// saveCassette writes c to path, indented for readable diffs
+func saveCassette(path string, c *cassette) error {
+	data, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return fmt.Errorf("vcr: encoding cassette: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("vcr: %w", err)
+	}
+	if err := os.WriteFile(path, append(data, '\n'), 0o644); err != nil {
+		return fmt.Errorf("vcr: %w", err)
+	}
+	return nil
+}
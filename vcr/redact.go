@@ -0,0 +1,133 @@
+package vcr
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"unicode/utf8"
+)
+
+// Redactor removes secrets and personal data from interactions before they are
+// recorded. Replaced values are stable, so a redacted request recorded once matches the
+// same request when replayed.
+type Redactor struct {
+	// Headers are dropped from requests and responses
+	Headers []string
+	// Fields are JSON object keys, at any depth, whose string values are replaced with a
+	// placeholder derived from the value
+	Fields []string
+}
+
+// DefaultRedactor returns a redactor dropping credentials and request signatures and
+// replacing blockchain addresses, bank accounts and secrets
+func DefaultRedactor() *Redactor {
+	return &Redactor{
+		Headers: []string{
+			"Api-Key", "Authorization", "Cookie", "Set-Cookie",
+			"X-Step-Up-Token", "X-Signature", "X-Timestamp", "X-Nonce",
+		},
+		Fields: []string{
+			"address", "blockchain_address", "blockchainAddress", "wallet_address",
+			"refund_address", "from_address", "to_address", "qrcode",
+			"iban", "account_number", "secret", "api_key", "session_token", "approver_token",
+		},
+	}
+}
+
+// defaultRedactor is used by recorders without a Redactor
+var defaultRedactor = DefaultRedactor()
+
+// request returns the redacted form of req with the given uncompressed body
+func (r *Redactor) request(req *http.Request, body []byte) RecordedRequest {
+	recorded := RecordedRequest{
+		Method: req.Method,
+		URL:    req.URL.RequestURI(),
+		Header: r.header(req.Header),
+	}
+	recorded.Body, recorded.BodyText = r.body(body)
+	return recorded
+}
+
+// response returns the redacted form of resp with the given uncompressed body
+func (r *Redactor) response(resp *http.Response, body []byte) RecordedResponse {
+	recorded := RecordedResponse{
+		StatusCode: resp.StatusCode,
+		Header:     r.header(resp.Header),
+	}
+	recorded.Body, recorded.BodyText = r.body(body)
+	return recorded
+}
+
+// header returns a copy of h without redacted and transfer headers
+func (r *Redactor) header(h http.Header) http.Header {
+	cleaned := h.Clone()
+	for _, name := range r.Headers {
+		cleaned.Del(name)
+	}
+	// Bodies are stored uncompressed and their length is set on replay
+	cleaned.Del("Content-Encoding")
+	cleaned.Del("Content-Length")
+	if len(cleaned) == 0 {
+		return nil
+	}
+	return cleaned
+}
+
+// body redacts a JSON body, returning it compacted with sorted keys; other bodies are
+// returned as text
+func (r *Redactor) body(body []byte) (json.RawMessage, string) {
+	if len(bytes.TrimSpace(body)) == 0 {
+		return nil, ""
+	}
+	var value interface{}
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.UseNumber()
+	if err := decoder.Decode(&value); err != nil || decoder.More() {
+		if utf8.Valid(body) {
+			return nil, string(body)
+		}
+		return nil, ""
+	}
+	redacted, err := json.Marshal(r.value(value))
+	if err != nil {
+		return nil, string(body)
+	}
+	return redacted, ""
+}
+
+// value redacts the fields of a decoded JSON value
+func (r *Redactor) value(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, field := range v {
+			if s, ok := field.(string); ok && s != "" && r.redacts(key) {
+				v[key] = placeholder(s)
+				continue
+			}
+			v[key] = r.value(field)
+		}
+	case []interface{}:
+		for i, item := range v {
+			v[i] = r.value(item)
+		}
+	}
+	return value
+}
+
+// redacts reports whether the values of key are redacted
+func (r *Redactor) redacts(key string) bool {
+	for _, field := range r.Fields {
+		if field == key {
+			return true
+		}
+	}
+	return false
+}
+
+// placeholder returns the stable replacement of a redacted value
+func placeholder(value string) string {
+	sum := sha256.Sum256([]byte(value))
+	return "redacted-" + hex.EncodeToString(sum[:6])
+}
@@ -0,0 +1,238 @@
+// Package vcr records ItIsPay API interactions to fixture files ("cassettes") and replays
+// them, so tests run offline and deterministically. The Recorder is an http.RoundTripper
+// for itispay.WithTransport:
+//
+//	rec, err := vcr.New("testdata/create_invoice.json", vcr.ModeAuto)
+//	if err != nil {
+//		t.Fatal(err)
+//	}
+//	defer rec.Stop()
+//	client := itispay.NewClient(os.Getenv("ITISPAY_API_KEY"), itispay.WithTransport(rec))
+//
+// Credentials are removed and addresses are replaced with stable placeholders before
+// anything is written, so cassettes can be committed.
+package vcr
+
+import (
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"sync"
+)
+
+// ErrNoInteraction is returned when replaying a request the cassette has no unused
+// recording for
+var ErrNoInteraction = errors.New("vcr: no recorded interaction")
+
+// Mode selects whether a Recorder talks to the API
+type Mode int
+
+const (
+	// ModeReplay serves requests from the cassette only; unknown requests fail with
+	// ErrNoInteraction
+	ModeReplay Mode = iota
+	// ModeRecord sends requests to the API and overwrites the cassette on Stop
+	ModeRecord
+	// ModeAuto replays if the cassette exists and records it otherwise
+	ModeAuto
+)
+
+// String returns the name of the mode
+func (m Mode) String() string {
+	switch m {
+	case ModeReplay:
+		return "replay"
+	case ModeRecord:
+		return "record"
+	case ModeAuto:
+		return "auto"
+	default:
+		return fmt.Sprintf("Mode(%d)", int(m))
+	}
+}
+
+// Recorder records or replays API interactions. It is safe for concurrent use; replayed
+// requests are matched by method, path, query and redacted body, and identical requests
+// receive their recorded responses in order.
+type Recorder struct {
+	// Transport sends requests while recording, http.DefaultTransport if nil
+	Transport http.RoundTripper
+	// Redactor sanitizes interactions; DefaultRedactor() if nil. Set it before the first
+	// request.
+	Redactor *Redactor
+
+	path      string
+	recording bool
+
+	mu           sync.Mutex
+	interactions []*Interaction
+	used         []bool
+}
+
+// New returns a recorder for the cassette at path. Replaying requires the cassette to
+// exist.
+func New(path string, mode Mode) (*Recorder, error) {
+	r := &Recorder{path: path}
+	switch mode {
+	case ModeRecord:
+		r.recording = true
+		return r, nil
+	case ModeAuto:
+		if _, err := os.Stat(path); errors.Is(err, os.ErrNotExist) {
+			r.recording = true
+			return r, nil
+		}
+	case ModeReplay:
+	default:
+		return nil, fmt.Errorf("vcr: unknown mode %v", mode)
+	}
+
+	cassette, err := loadCassette(path)
+	if err != nil {
+		return nil, err
+	}
+	r.interactions = cassette.Interactions
+	r.used = make([]bool, len(cassette.Interactions))
+	return r, nil
+}
+
+// Recording reports whether the recorder sends requests to the API
+func (r *Recorder) Recording() bool {
+	return r.recording
+}
+
+// RoundTrip implements http.RoundTripper
+func (r *Recorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	body, err := readRequestBody(req)
+	if err != nil {
+		return nil, err
+	}
+	recorded := r.redactor().request(req, body)
+	if r.recording {
+		return r.record(req, recorded, body)
+	}
+	return r.replay(req, recorded)
+}
+
+// Stop saves the cassette when recording. Call it once all requests are done.
+func (r *Recorder) Stop() error {
+	if !r.recording {
+		return nil
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return saveCassette(r.path, &cassette{Version: cassetteVersion, Interactions: r.interactions})
+}
+
+// record sends req to the API and keeps the redacted interaction
+func (r *Recorder) record(req *http.Request, recorded RecordedRequest, body []byte) (*http.Response, error) {
+	transport := r.Transport
+	if transport == nil {
+		transport = http.DefaultTransport
+	}
+	outgoing := req.Clone(req.Context())
+	outgoing.Body = io.NopCloser(bytes.NewReader(body))
+	outgoing.ContentLength = int64(len(body))
+	outgoing.Header.Del("Content-Encoding")
+
+	resp, err := transport.RoundTrip(outgoing)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	respBody, err := readResponseBody(resp)
+	if err != nil {
+		return nil, fmt.Errorf("vcr: reading response: %w", err)
+	}
+
+	interaction := &Interaction{
+		Request:  recorded,
+		Response: r.redactor().response(resp, respBody),
+	}
+	r.mu.Lock()
+	r.interactions = append(r.interactions, interaction)
+	r.used = append(r.used, true)
+	r.mu.Unlock()
+
+	// Hand the caller what the cassette will replay, so recording and replaying behave
+	// the same
+	return interaction.Response.toHTTP(req), nil
+}
+
+// replay serves req from the first unused matching interaction
+func (r *Recorder) replay(req *http.Request, recorded RecordedRequest) (*http.Response, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for i, interaction := range r.interactions {
+		if !r.used[i] && interaction.Request.matches(recorded) {
+			r.used[i] = true
+			return interaction.Response.toHTTP(req), nil
+		}
+	}
+	return nil, fmt.Errorf("%w for %s %s", ErrNoInteraction, recorded.Method, recorded.URL)
+}
+
+// Unused returns the recorded interactions that were not replayed, e.g. to fail tests
+// whose requests changed
+func (r *Recorder) Unused() []*Interaction {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var unused []*Interaction
+	for i, interaction := range r.interactions {
+		if !r.used[i] {
+			unused = append(unused, interaction)
+		}
+	}
+	return unused
+}
+
+// redactor returns the redactor in use
+func (r *Recorder) redactor() *Redactor {
+	if r.Redactor == nil {
+		return defaultRedactor
+	}
+	return r.Redactor
+}
+
+// readRequestBody returns the uncompressed body of req without consuming it
+func readRequestBody(req *http.Request) ([]byte, error) {
+	if req.Body == nil || req.Body == http.NoBody {
+		return nil, nil
+	}
+	body, err := io.ReadAll(req.Body)
+	req.Body.Close()
+	if err != nil {
+		return nil, fmt.Errorf("vcr: reading request: %w", err)
+	}
+	req.Body = io.NopCloser(bytes.NewReader(body))
+	if req.Header.Get("Content-Encoding") == "gzip" {
+		return gunzip(body)
+	}
+	return body, nil
+}
+
+// readResponseBody returns the uncompressed body of resp
+func readResponseBody(resp *http.Response) ([]byte, error) {
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if resp.Header.Get("Content-Encoding") == "gzip" {
+		return gunzip(body)
+	}
+	return body, nil
+}
+
+// gunzip decompresses a gzip body
+func gunzip(body []byte) ([]byte, error) {
+	zr, err := gzip.NewReader(bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("vcr: decompressing body: %w", err)
+	}
+	defer zr.Close()
+	return io.ReadAll(zr)
+}
@@ -0,0 +1,39 @@
+package itispay
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// Warmup prepares the client for traffic, e.g. right after a deploy: it opens
+// connections to the API, validates the credentials and loads the currency cache, so the
+// first customer checkout does not pay for the cold start. Currencies and rates are
+// fetched in parallel, leaving two connections in the idle pool.
+func (c *Client) Warmup(ctx context.Context, opts ...RequestOption) error {
+	var wg sync.WaitGroup
+	var currenciesErr, ratesErr error
+
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		response, err := c.GetCurrencies(ctx, opts...)
+		if err != nil {
+			currenciesErr = fmt.Errorf("itispay: warmup: loading currencies: %w", err)
+			return
+		}
+		c.currencyCache.mu.Lock()
+		c.currencyCache.store(response.Currencies, c.clock.Now())
+		c.currencyCache.mu.Unlock()
+	}()
+	go func() {
+		defer wg.Done()
+		if _, err := c.GetRates(ctx, opts...); err != nil {
+			ratesErr = fmt.Errorf("itispay: warmup: loading rates: %w", err)
+		}
+	}()
+	wg.Wait()
+
+	return errors.Join(currenciesErr, ratesErr)
+}
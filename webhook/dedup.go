@@ -0,0 +1,126 @@
+package webhook
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	itispay "github.com/ItIsPay/go-client"
+)
+
+// DefaultDedupWindow is how long processed event IDs are remembered by default. It
+// should exceed the provider's retry schedule.
+const DefaultDedupWindow = 72 * time.Hour
+
+// DedupStore records processed webhook event IDs so retried deliveries are skipped
+type DedupStore interface {
+	// Claim marks id as processed for window. It reports false if id was already
+	// claimed within its window, in which case the event must be skipped.
+	Claim(ctx context.Context, id string, window time.Duration) (bool, error)
+	// Release forgets id, so an event whose processing failed is handled again when
+	// the provider retries it
+	Release(ctx context.Context, id string) error
+}
+
+// MemoryDedupStore is an in-process DedupStore. It does not share state between
+// replicas; use RedisDedupStore for multi-instance deployments.
+type MemoryDedupStore struct {
+	// Clock tells the time, itispay.SystemClock if nil
+	Clock itispay.Clock
+
+	mu        sync.Mutex
+	expiries  map[string]time.Time
+	lastSweep time.Time
+}
+
+// NewMemoryDedupStore returns an empty in-memory store
+func NewMemoryDedupStore() *MemoryDedupStore {
+	return &MemoryDedupStore{expiries: make(map[string]time.Time)}
+}
+
+// Claim implements DedupStore
+func (s *MemoryDedupStore) Claim(ctx context.Context, id string, window time.Duration) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := clockOrSystem(s.Clock).Now()
+	s.sweep(now, window)
+	if expiry, ok := s.expiries[id]; ok && now.Before(expiry) {
+		return false, nil
+	}
+	s.expiries[id] = now.Add(window)
+	return true, nil
+}
+
+// Release implements DedupStore
+func (s *MemoryDedupStore) Release(ctx context.Context, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.expiries, id)
+	return nil
+}
+
+// sweep drops expired IDs, at most once per window fraction; s.mu must be held
+func (s *MemoryDedupStore) sweep(now time.Time, window time.Duration) {
+	if now.Sub(s.lastSweep) < window/16 {
+		return
+	}
+	for id, expiry := range s.expiries {
+		if !now.Before(expiry) {
+			delete(s.expiries, id)
+		}
+	}
+	s.lastSweep = now
+}
+
+// RedisCommander is the subset of Redis commands used by RedisDedupStore. Adapt your
+// Redis client to it, e.g. for github.com/redis/go-redis:
+//
+//	type goRedis struct{ *redis.Client }
+//
+//	func (r goRedis) SetNX(ctx context.Context, key string, ttl time.Duration) (bool, error) {
+//		return r.Client.SetNX(ctx, key, 1, ttl).Result()
+//	}
+//
+//	func (r goRedis) Del(ctx context.Context, key string) error {
+//		return r.Client.Del(ctx, key).Err()
+//	}
+type RedisCommander interface {
+	// SetNX sets key with the given TTL if it does not exist and reports whether it was set
+	SetNX(ctx context.Context, key string, ttl time.Duration) (bool, error)
+	Del(ctx context.Context, key string) error
+}
+
+// RedisDedupStore is a DedupStore shared by all replicas through Redis
+type RedisDedupStore struct {
+	Client RedisCommander
+	// Prefix namespaces the keys, "itispay:webhook:" if empty
+	Prefix string
+}
+
+// Claim implements DedupStore
+func (s *RedisDedupStore) Claim(ctx context.Context, id string, window time.Duration) (bool, error) {
+	return s.Client.SetNX(ctx, s.key(id), window)
+}
+
+// Release implements DedupStore
+func (s *RedisDedupStore) Release(ctx context.Context, id string) error {
+	return s.Client.Del(ctx, s.key(id))
+}
+
+// key returns the Redis key of an event ID
+func (s *RedisDedupStore) key(id string) string {
+	prefix := s.Prefix
+	if prefix == "" {
+		prefix = "itispay:webhook:"
+	}
+	return prefix + id
+}
+
+// clockOrSystem returns clock, or itispay.SystemClock if it is nil
+func clockOrSystem(clock itispay.Clock) itispay.Clock {
+	if clock == nil {
+		return itispay.SystemClock
+	}
+	return clock
+}
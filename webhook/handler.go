@@ -0,0 +1,109 @@
+// Package webhook helps processing ItIsPay webhook callbacks safely.
+//
+// Providers retry deliveries that time out or fail, so the same event can arrive more
+// than once. Handler skips events that were already processed:
+//
+//	store := webhook.NewMemoryDedupStore()
+//	http.Handle("/webhook", webhook.NewHandler(store, func(ctx context.Context, payload *itispay.WebhookPayload) error {
+//		return fulfillOrder(ctx, payload.OrderID)
+//	}))
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"time"
+
+	itispay "github.com/ItIsPay/go-client"
+)
+
+// EventIDHeader carries the unique ID of a webhook event, if the provider sends one
+const EventIDHeader = "X-Event-ID"
+
+// ProcessFunc handles a webhook. Returning an error makes the handler respond with 500
+// so the provider retries the delivery.
+type ProcessFunc func(ctx context.Context, payload *itispay.WebhookPayload) error
+
+// Handler is an http.Handler processing each webhook event at most once per window
+type Handler struct {
+	store   DedupStore
+	process ProcessFunc
+
+	// Window is how long processed event IDs are remembered, DefaultDedupWindow if zero
+	Window time.Duration
+	// Metrics, if set, records the delivery latency of every webhook received
+	Metrics itispay.WebhookLatencyMetrics
+	// Clock stamps the time webhooks are received, itispay.SystemClock if nil
+	Clock itispay.Clock
+}
+
+// NewHandler returns a Handler deduplicating events with store before calling process
+func NewHandler(store DedupStore, process ProcessFunc) *Handler {
+	return &Handler{store: store, process: process, Window: DefaultDedupWindow}
+}
+
+// ServeHTTP implements http.Handler
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	payload, err := itispay.ParseWebhookWithClock(r, h.Clock)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	observeLatency(h.Metrics, payload)
+
+	window := h.Window
+	if window <= 0 {
+		window = DefaultDedupWindow
+	}
+	id := EventID(r, payload)
+	claimed, err := h.store.Claim(r.Context(), id, window)
+	if err != nil {
+		// Let the provider retry rather than risk processing twice
+		http.Error(w, "Deduplication unavailable", http.StatusInternalServerError)
+		return
+	}
+	if !claimed {
+		writeStatus(w, "duplicate")
+		return
+	}
+
+	if err := h.process(r.Context(), payload); err != nil {
+		// Forget the event so the provider's retry is processed
+		_ = h.store.Release(context.WithoutCancel(r.Context()), id)
+		http.Error(w, "Processing failed", http.StatusInternalServerError)
+		return
+	}
+	writeStatus(w, "ok")
+}
+
+// EventID returns the unique ID of a webhook event: the EventIDHeader or the payload's
+// EventID if present, otherwise the invoice ID, status and update time, which identify
+// a status change
+func EventID(r *http.Request, payload *itispay.WebhookPayload) string {
+	if id := r.Header.Get(EventIDHeader); id != "" {
+		return id
+	}
+	if payload.EventID != "" {
+		return payload.EventID
+	}
+	return payload.InvoiceID + ":" + string(payload.Status) + ":" + payload.UpdatedAt.UTC().Format(time.RFC3339Nano)
+}
+
+// observeLatency records the delivery latency of a webhook, including redeliveries
+func observeLatency(metrics itispay.WebhookLatencyMetrics, payload *itispay.WebhookPayload) {
+	if metrics != nil && !payload.UpdatedAt.IsZero() {
+		metrics.ObserveWebhookLatency(string(payload.Status), payload.Latency())
+	}
+}
+
+// writeStatus acknowledges a delivery
+func writeStatus(w http.ResponseWriter, status string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	_ = json.NewEncoder(w).Encode(map[string]string{"status": status})
+}
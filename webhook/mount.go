@@ -0,0 +1,38 @@
+//go:build go1.22
+
+package webhook
+
+import (
+	"net/http"
+	"strings"
+)
+
+// eventPathValue is the wildcard of the per-event sub-route registered by Mount
+const eventPathValue = "event"
+
+// Mount registers handler on mux for POST requests to pattern and to its per-event
+// sub-routes pattern/{event}, e.g. "/webhooks/itispay" and "/webhooks/itispay/completed",
+// so callback URLs can name the event they are configured for:
+//
+//	mux := http.NewServeMux()
+//	webhook.Mount(mux, "/webhooks/itispay", webhook.NewHandler(store, process))
+//
+// It relies on the pattern routing of Go 1.22; the main module must declare go 1.22 or
+// later for mux to honor it. Other methods are answered with 405 by mux. Mounted on "/",
+// handler receives POST requests to "/" and to "/{event}" only.
+func Mount(mux *http.ServeMux, pattern string, handler http.Handler) {
+	pattern = strings.TrimSuffix(pattern, "/")
+	if pattern == "" {
+		// "POST /" would match every path, and the sub-route conflicts with it
+		mux.Handle("POST /{$}", handler)
+	} else {
+		mux.Handle("POST "+pattern, handler)
+	}
+	mux.Handle("POST "+pattern+"/{"+eventPathValue+"}", handler)
+}
+
+// RouteEvent returns the {event} segment of a request routed by Mount, empty for
+// requests to the base pattern
+func RouteEvent(r *http.Request) string {
+	return r.PathValue(eventPathValue)
+}
@@ -0,0 +1,52 @@
+//go:build go1.22
+
+//go:debug httpmuxgo121=0
+
+package webhook_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ItIsPay/go-client/webhook"
+)
+
+func TestMount(t *testing.T) {
+	tests := []struct {
+		pattern string
+		path    string
+		method  string
+		want    int
+		event   string
+	}{
+		{pattern: "/webhooks/itispay", path: "/webhooks/itispay", method: http.MethodPost, want: http.StatusNoContent},
+		{pattern: "/webhooks/itispay/", path: "/webhooks/itispay/completed", method: http.MethodPost, want: http.StatusNoContent, event: "completed"},
+		{pattern: "/webhooks/itispay", path: "/webhooks/itispay", method: http.MethodGet, want: http.StatusMethodNotAllowed},
+		{pattern: "/webhooks/itispay", path: "/webhooks/other", method: http.MethodPost, want: http.StatusNotFound},
+		{pattern: "/", path: "/", method: http.MethodPost, want: http.StatusNoContent},
+		{pattern: "/", path: "/completed", method: http.MethodPost, want: http.StatusNoContent, event: "completed"},
+		{pattern: "/", path: "/completed/more", method: http.MethodPost, want: http.StatusNotFound},
+		{pattern: "", path: "/", method: http.MethodPost, want: http.StatusNoContent},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.pattern+" "+tt.method+" "+tt.path, func(t *testing.T) {
+			mux := http.NewServeMux()
+			var event string
+			webhook.Mount(mux, tt.pattern, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				event = webhook.RouteEvent(r)
+				w.WriteHeader(http.StatusNoContent)
+			}))
+
+			rec := httptest.NewRecorder()
+			mux.ServeHTTP(rec, httptest.NewRequest(tt.method, tt.path, nil))
+			if rec.Code != tt.want {
+				t.Fatalf("status = %d, want %d", rec.Code, tt.want)
+			}
+			if event != tt.event {
+				t.Errorf("event = %q, want %q", event, tt.event)
+			}
+		})
+	}
+}
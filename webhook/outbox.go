@@ -0,0 +1,346 @@
+package webhook
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	itispay "github.com/ItIsPay/go-client"
+)
+
+// Outbox defaults
+const (
+	DefaultMaxAttempts  = 10
+	DefaultPollInterval = time.Second
+	DefaultLease        = 5 * time.Minute
+)
+
+// OutboxMessage is a received webhook waiting to be processed
+type OutboxMessage struct {
+	// ID is the event ID (see EventID); enqueuing an existing ID is a no-op
+	ID         string
+	Payload    *itispay.WebhookPayload
+	ReceivedAt time.Time
+	// Attempts is the number of failed processing attempts
+	Attempts      int
+	NextAttemptAt time.Time
+	LastError     string
+}
+
+// OutboxStore persists outbox messages. Implementations backed by a database make
+// accepted webhooks survive restarts.
+type OutboxStore interface {
+	// Enqueue durably stores msg unless a message with the same ID is queued or was
+	// completed within the dedup window, so redeliveries are not processed again
+	Enqueue(ctx context.Context, msg *OutboxMessage) error
+	// Lease returns up to limit messages due at now and postpones them by lease, so
+	// they are not handed out again while being processed
+	Lease(ctx context.Context, now time.Time, limit int, lease time.Duration) ([]*OutboxMessage, error)
+	// Reschedule saves the attempt count, error and next attempt time of msg
+	Reschedule(ctx context.Context, msg *OutboxMessage) error
+	// Complete removes a processed message, remembering its ID for the dedup window
+	Complete(ctx context.Context, id string) error
+	// DeadLetter moves a message that exhausted its attempts out of the queue
+	DeadLetter(ctx context.Context, msg *OutboxMessage) error
+}
+
+// Outbox accepts webhooks by persisting them and acknowledging immediately, then
+// processes them in the background with retries, so slow processing does not time out
+// the delivery and trigger provider-side retries:
+//
+//	outbox := webhook.NewOutbox(webhook.NewMemoryOutboxStore(), fulfill)
+//	http.Handle("/webhook", outbox)
+//	go outbox.Run(ctx)
+type Outbox struct {
+	store   OutboxStore
+	process ProcessFunc
+
+	// MaxAttempts is the number of attempts before a message is dead-lettered,
+	// DefaultMaxAttempts if zero
+	MaxAttempts int
+	// Backoff returns the delay after the given failed attempt (1-based); exponential
+	// from one second up to one hour if nil
+	Backoff func(attempt int) time.Duration
+	// PollInterval is how often due messages are fetched, DefaultPollInterval if zero
+	PollInterval time.Duration
+	// Lease is how long a message is reserved for processing, DefaultLease if zero
+	Lease time.Duration
+	// Concurrency is the number of messages processed in parallel, 1 if zero
+	Concurrency int
+	// OnDeadLetter, if set, is called for every dead-lettered message
+	OnDeadLetter func(msg *OutboxMessage, err error)
+	// OnError, if set, is called with store errors encountered by Run
+	OnError func(err error)
+	// Metrics, if set, records the delivery latency of every webhook received
+	Metrics itispay.WebhookLatencyMetrics
+	// Clock tells the time and schedules polls and retries, itispay.SystemClock if nil
+	Clock itispay.Clock
+}
+
+// NewOutbox returns an outbox storing webhooks in store and handing them to process
+func NewOutbox(store OutboxStore, process ProcessFunc) *Outbox {
+	return &Outbox{store: store, process: process}
+}
+
+// ServeHTTP accepts a webhook by persisting it. It responds with 500 only if the
+// webhook could not be stored, so the provider retries it.
+func (o *Outbox) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	payload, err := itispay.ParseWebhookWithClock(r, o.Clock)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	observeLatency(o.Metrics, payload)
+
+	now := clockOrSystem(o.Clock).Now()
+	msg := &OutboxMessage{
+		ID:            EventID(r, payload),
+		Payload:       payload,
+		ReceivedAt:    now,
+		NextAttemptAt: now,
+	}
+	if err := o.store.Enqueue(r.Context(), msg); err != nil {
+		http.Error(w, "Failed to store webhook", http.StatusInternalServerError)
+		return
+	}
+	writeStatus(w, "accepted")
+}
+
+// Run processes due messages until ctx is done
+func (o *Outbox) Run(ctx context.Context) error {
+	interval := o.PollInterval
+	if interval <= 0 {
+		interval = DefaultPollInterval
+	}
+	clock := clockOrSystem(o.Clock)
+
+	for {
+		// Store errors are retried on the next poll
+		if err := o.ProcessDue(ctx); err != nil && ctx.Err() == nil && o.OnError != nil {
+			o.OnError(err)
+		}
+		timer := clock.NewTimer(interval)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C():
+		}
+	}
+}
+
+// ProcessDue processes the messages currently due, e.g. from a cron job instead of Run.
+// Messages rescheduled while it runs are left for the next call.
+func (o *Outbox) ProcessDue(ctx context.Context) error {
+	concurrency := o.Concurrency
+	if concurrency <= 0 {
+		concurrency = 1
+	}
+	lease := o.Lease
+	if lease <= 0 {
+		lease = DefaultLease
+	}
+
+	now := clockOrSystem(o.Clock).Now()
+	for {
+		msgs, err := o.store.Lease(ctx, now, concurrency, lease)
+		if err != nil || len(msgs) == 0 {
+			return err
+		}
+
+		errs := make([]error, len(msgs))
+		var wg sync.WaitGroup
+		for i, msg := range msgs {
+			wg.Add(1)
+			go func(i int, msg *OutboxMessage) {
+				defer wg.Done()
+				errs[i] = o.handle(ctx, msg)
+			}(i, msg)
+		}
+		wg.Wait()
+
+		if err := errors.Join(errs...); err != nil {
+			return err
+		}
+	}
+}
+
+// handle processes one message and records the outcome
+func (o *Outbox) handle(ctx context.Context, msg *OutboxMessage) error {
+	err := o.process(ctx, msg.Payload)
+	if err == nil {
+		return o.store.Complete(ctx, msg.ID)
+	}
+	if ctx.Err() != nil {
+		// Shutting down: the lease expires and the message is retried later
+		return nil
+	}
+
+	msg.Attempts++
+	msg.LastError = err.Error()
+	maxAttempts := o.MaxAttempts
+	if maxAttempts <= 0 {
+		maxAttempts = DefaultMaxAttempts
+	}
+	if msg.Attempts >= maxAttempts {
+		if dlErr := o.store.DeadLetter(ctx, msg); dlErr != nil {
+			return dlErr
+		}
+		if o.OnDeadLetter != nil {
+			o.OnDeadLetter(msg, err)
+		}
+		return nil
+	}
+
+	msg.NextAttemptAt = clockOrSystem(o.Clock).Now().Add(o.backoff(msg.Attempts))
+	return o.store.Reschedule(ctx, msg)
+}
+
+// backoff returns the delay after a failed attempt
+func (o *Outbox) backoff(attempt int) time.Duration {
+	if o.Backoff != nil {
+		return o.Backoff(attempt)
+	}
+	delay := time.Second
+	for i := 1; i < attempt && delay < time.Hour; i++ {
+		delay *= 2
+	}
+	if delay > time.Hour {
+		delay = time.Hour
+	}
+	return delay
+}
+
+// MemoryOutboxStore is an in-process OutboxStore. Messages are lost on restart; use
+// a database-backed store in production.
+type MemoryOutboxStore struct {
+	// Window is how long completed IDs are remembered, DefaultDedupWindow if zero
+	Window time.Duration
+	// Clock tells the time, itispay.SystemClock if nil
+	Clock itispay.Clock
+
+	mu          sync.Mutex
+	messages    map[string]*OutboxMessage
+	completed   map[string]time.Time
+	lastSweep   time.Time
+	deadLetters []*OutboxMessage
+}
+
+// NewMemoryOutboxStore returns an empty in-memory store
+func NewMemoryOutboxStore() *MemoryOutboxStore {
+	return &MemoryOutboxStore{
+		messages:  make(map[string]*OutboxMessage),
+		completed: make(map[string]time.Time),
+	}
+}
+
+// Enqueue implements OutboxStore
+func (s *MemoryOutboxStore) Enqueue(ctx context.Context, msg *OutboxMessage) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if expiry, ok := s.completed[msg.ID]; ok && clockOrSystem(s.Clock).Now().Before(expiry) {
+		return nil
+	}
+	if _, ok := s.messages[msg.ID]; !ok {
+		copied := *msg
+		s.messages[msg.ID] = &copied
+	}
+	return nil
+}
+
+// Lease implements OutboxStore
+func (s *MemoryOutboxStore) Lease(ctx context.Context, now time.Time, limit int, lease time.Duration) ([]*OutboxMessage, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var due []*OutboxMessage
+	for _, msg := range s.messages {
+		if !msg.NextAttemptAt.After(now) {
+			due = append(due, msg)
+		}
+	}
+	sort.Slice(due, func(i, j int) bool { return due[i].ReceivedAt.Before(due[j].ReceivedAt) })
+	if len(due) > limit {
+		due = due[:limit]
+	}
+
+	leased := make([]*OutboxMessage, len(due))
+	for i, msg := range due {
+		msg.NextAttemptAt = now.Add(lease)
+		copied := *msg
+		leased[i] = &copied
+	}
+	return leased, nil
+}
+
+// Reschedule implements OutboxStore
+func (s *MemoryOutboxStore) Reschedule(ctx context.Context, msg *OutboxMessage) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.messages[msg.ID]; ok {
+		copied := *msg
+		s.messages[msg.ID] = &copied
+	}
+	return nil
+}
+
+// Complete implements OutboxStore
+func (s *MemoryOutboxStore) Complete(ctx context.Context, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.messages, id)
+
+	window := s.Window
+	if window <= 0 {
+		window = DefaultDedupWindow
+	}
+	now := clockOrSystem(s.Clock).Now()
+	s.sweep(now, window)
+	s.completed[id] = now.Add(window)
+	return nil
+}
+
+// sweep forgets expired completed IDs, at most once per window fraction; s.mu must be
+// held
+func (s *MemoryOutboxStore) sweep(now time.Time, window time.Duration) {
+	if now.Sub(s.lastSweep) < window/16 {
+		return
+	}
+	for id, expiry := range s.completed {
+		if !now.Before(expiry) {
+			delete(s.completed, id)
+		}
+	}
+	s.lastSweep = now
+}
+
+// DeadLetter implements OutboxStore
+func (s *MemoryOutboxStore) DeadLetter(ctx context.Context, msg *OutboxMessage) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.messages, msg.ID)
+	copied := *msg
+	s.deadLetters = append(s.deadLetters, &copied)
+	return nil
+}
+
+// Pending returns the number of messages waiting to be processed
+func (s *MemoryOutboxStore) Pending() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.messages)
+}
+
+// DeadLetters returns the dead-lettered messages
+func (s *MemoryOutboxStore) DeadLetters() []*OutboxMessage {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]*OutboxMessage(nil), s.deadLetters...)
+}
@@ -0,0 +1,211 @@
+package webhook
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	itispay "github.com/ItIsPay/go-client"
+)
+
+// Condition reports whether a rule applies to a webhook
+type Condition func(payload *itispay.WebhookPayload) bool
+
+// Action is run for a webhook matching a rule
+type Action func(ctx context.Context, payload *itispay.WebhookPayload) error
+
+// Rule runs Then for webhooks matching When
+type Rule struct {
+	Name string
+	When Condition
+	Then Action
+}
+
+// Decision records the evaluation of one rule for one webhook
+type Decision struct {
+	Rule      string
+	InvoiceID string
+	Status    itispay.Status
+	Matched   bool
+	// Err is the error returned by the action, if it ran and failed
+	Err error
+	At  time.Time
+}
+
+// String formats the decision for logs
+func (d Decision) String() string {
+	outcome := "skipped"
+	switch {
+	case d.Err != nil:
+		outcome = "failed: " + d.Err.Error()
+	case d.Matched:
+		outcome = "applied"
+	}
+	return fmt.Sprintf("rule %q on invoice %s (%s): %s", d.Rule, d.InvoiceID, d.Status, outcome)
+}
+
+// Rules declares automatic actions on webhook events. Rules are evaluated in order and
+// every matching rule runs; the first failing action stops evaluation and fails the
+// delivery, so actions must be safe to repeat. Process is a ProcessFunc.
+//
+// Webhook payloads are not authenticated, so anyone able to reach the endpoint can
+// forge one. With Client set, rules see the invoice as returned by GetInvoice instead
+// of the fields received:
+//
+//	rules := &webhook.Rules{Client: client, Audit: func(d webhook.Decision) { log.Println(d) }}
+//	rules.Add("accept small shortfall",
+//		webhook.All(webhook.StatusIs(itispay.StatusPaidPartial), webhook.FiatShortfallBelow(1)),
+//		markCompleted)
+//	rules.Add("ticket for expired paid invoice",
+//		webhook.All(webhook.StatusIs(itispay.StatusExpired), webhook.HasPayment()),
+//		openTicket)
+//	http.Handle("/webhook", webhook.NewHandler(store, rules.Process))
+type Rules struct {
+	Rules []Rule
+	// Audit, if set, is called with the decision of every rule for every webhook
+	Audit func(Decision)
+	// Client, if set, fetches the invoice of every webhook, whose status, amounts,
+	// transactions and risk replace the received ones before the rules are evaluated
+	Client *itispay.Client
+	// Clock stamps decisions, itispay.SystemClock if nil
+	Clock itispay.Clock
+}
+
+// Add appends a rule and returns r for chaining
+func (r *Rules) Add(name string, when Condition, then Action) *Rules {
+	r.Rules = append(r.Rules, Rule{Name: name, When: when, Then: then})
+	return r
+}
+
+// Process evaluates the rules for a webhook. If Client is set and the invoice cannot be
+// fetched, no rule runs and the delivery fails so it is retried.
+func (r *Rules) Process(ctx context.Context, payload *itispay.WebhookPayload) error {
+	if r.Client != nil {
+		invoice, err := r.Client.GetInvoice(ctx, payload.InvoiceID)
+		if err != nil {
+			return fmt.Errorf("fetching invoice %s: %w", payload.InvoiceID, err)
+		}
+		payload = fromInvoice(payload, invoice)
+	}
+
+	clock := clockOrSystem(r.Clock)
+	for _, rule := range r.Rules {
+		decision := Decision{
+			Rule:      rule.Name,
+			InvoiceID: payload.InvoiceID,
+			Status:    payload.Status,
+			Matched:   rule.When == nil || rule.When(payload),
+			At:        clock.Now(),
+		}
+		if decision.Matched && rule.Then != nil {
+			decision.Err = rule.Then(ctx, payload)
+		}
+		if r.Audit != nil {
+			r.Audit(decision)
+		}
+		if decision.Err != nil {
+			return fmt.Errorf("rule %q: %w", rule.Name, decision.Err)
+		}
+	}
+	return nil
+}
+
+// fromInvoice returns a copy of payload with the invoice's state. The payment rate and
+// fiat amount paid, which invoices do not carry, are cleared so they are derived from
+// the invoice's amounts.
+func fromInvoice(payload *itispay.WebhookPayload, invoice *itispay.Invoice) *itispay.WebhookPayload {
+	p := *payload
+	p.Status = invoice.Status
+	p.OrderID = invoice.OrderID
+	p.CustomerID = invoice.CustomerID
+	p.Currency = invoice.Currency
+	p.CryptoAmount = invoice.CryptoAmount
+	p.FiatAmount = invoice.FiatAmount
+	p.FiatCurrency = invoice.FiatCurrency
+	p.ActualCryptoAmountPaid = invoice.ActualCryptoAmountPaid
+	p.ActualCryptoAmountPaidInUnits = invoice.ActualCryptoAmountPaidInUnits
+	p.AllowedErrorPercent = invoice.AllowedErrorPercent
+	p.RequiredConfirmations = invoice.RequiredConfirmations
+	p.CurrentConfirmations = invoice.CurrentConfirmations
+	p.ExternalRefs = invoice.ExternalRefs
+	p.Metadata = invoice.Metadata
+	p.TestMode = invoice.TestMode
+	p.CreatedAt = invoice.CreatedAt
+	p.UpdatedAt = invoice.UpdatedAt
+	p.ExpiresAt = invoice.ExpiresAt
+	p.PaymentRate = 0
+	p.ActualFiatAmountPaid = 0
+	p.AutoConvertTo = invoice.AutoConvertTo
+	p.Conversion = invoice.Conversion
+	p.RiskScore = invoice.RiskScore
+	p.RiskFlags = invoice.RiskFlags
+	p.Transactions = invoice.Transactions
+	p.BlockchainDetails = invoice.BlockchainDetails
+	return &p
+}
+
+// StatusIs matches webhooks with any of the given statuses
+func StatusIs(statuses ...itispay.Status) Condition {
+	return func(payload *itispay.WebhookPayload) bool {
+		for _, status := range statuses {
+			if payload.Status == status {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// FiatShortfallBelow matches webhooks whose fiat shortfall is below amount, in the
+// invoice's fiat currency
+func FiatShortfallBelow(amount float64) Condition {
+	return func(payload *itispay.WebhookPayload) bool {
+		return payload.FiatShortfall() < amount
+	}
+}
+
+// HasPayment matches webhooks of invoices that received any payment
+func HasPayment() Condition {
+	return func(payload *itispay.WebhookPayload) bool {
+		return payload.ActualCryptoAmountPaid > 0 || len(payload.Transactions) > 0
+	}
+}
+
+// HighRisk matches webhooks whose payment is high risk at threshold, or at
+// DefaultHighRiskScore if zero; see WebhookPayload.IsHighRisk
+func HighRisk(threshold float64) Condition {
+	return func(payload *itispay.WebhookPayload) bool {
+		return payload.IsHighRisk(threshold)
+	}
+}
+
+// All matches webhooks matching every condition
+func All(conditions ...Condition) Condition {
+	return func(payload *itispay.WebhookPayload) bool {
+		for _, condition := range conditions {
+			if !condition(payload) {
+				return false
+			}
+		}
+		return true
+	}
+}
+
+// Any matches webhooks matching at least one condition
+func Any(conditions ...Condition) Condition {
+	return func(payload *itispay.WebhookPayload) bool {
+		for _, condition := range conditions {
+			if condition(payload) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// Not matches webhooks not matching condition
+func Not(condition Condition) Condition {
+	return func(payload *itispay.WebhookPayload) bool {
+		return !condition(payload)
+	}
+}
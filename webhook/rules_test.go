@@ -0,0 +1,64 @@
+package webhook_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	itispay "github.com/ItIsPay/go-client"
+	"github.com/ItIsPay/go-client/itispaytest"
+	"github.com/ItIsPay/go-client/webhook"
+)
+
+func TestRulesUseFetchedInvoice(t *testing.T) {
+	srv := itispaytest.NewServer()
+	defer srv.Close()
+	invoice := itispaytest.NewTestInvoice(itispaytest.WithStatus(itispay.StatusNew))
+	srv.AddInvoice(*invoice)
+
+	// A forged webhook claims the invoice was paid
+	forged := itispaytest.NewTestWebhookPayload(itispaytest.NewTestInvoice(itispaytest.WithStatus(itispay.StatusCompleted)))
+	forged.InvoiceID = invoice.InvoiceID
+
+	clock := itispaytest.NewFakeClock(time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC))
+	var decisions []webhook.Decision
+	fulfilled := false
+	rules := &webhook.Rules{
+		Client: srv.Client(),
+		Clock:  clock,
+		Audit:  func(d webhook.Decision) { decisions = append(decisions, d) },
+	}
+	rules.Add("fulfill", webhook.StatusIs(itispay.StatusCompleted), func(context.Context, *itispay.WebhookPayload) error {
+		fulfilled = true
+		return nil
+	})
+
+	if err := rules.Process(context.Background(), forged); err != nil {
+		t.Fatal(err)
+	}
+	if fulfilled {
+		t.Error("rule ran on the status of the forged payload")
+	}
+	if len(decisions) != 1 || decisions[0].Status != itispay.StatusNew || !decisions[0].At.Equal(clock.Now()) {
+		t.Errorf("decisions = %+v, want one on status new at %v", decisions, clock.Now())
+	}
+}
+
+func TestRulesFailWhenInvoiceCannotBeFetched(t *testing.T) {
+	srv := itispaytest.NewServer()
+	defer srv.Close()
+	ran := false
+	rules := &webhook.Rules{Client: srv.Client()}
+	rules.Add("any", nil, func(context.Context, *itispay.WebhookPayload) error {
+		ran = true
+		return nil
+	})
+
+	payload := itispaytest.NewTestWebhookPayload(itispaytest.NewTestInvoice(itispaytest.WithInvoiceID("missing")))
+	if err := rules.Process(context.Background(), payload); err == nil {
+		t.Error("unknown invoice processed without error")
+	}
+	if ran {
+		t.Error("rule ran without the invoice")
+	}
+}
@@ -0,0 +1,9 @@
+package itispay
+
+import "time"
+
+// WebhookLatencyMetrics is an optional extension of Metrics recording how long after
+// an invoice changed the corresponding webhook arrived
+type WebhookLatencyMetrics interface {
+	ObserveWebhookLatency(status string, latency time.Duration)
+}
@@ -0,0 +1,51 @@
+package itispay
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/ItIsPay/go-client/types"
+)
+
+// maxWebhookBodySize limits the size of webhook bodies read by ParseWebhook
+const maxWebhookBodySize = 1 << 20
+
+// ErrInvalidWebhook is returned by ParseWebhook for malformed webhook requests
+var ErrInvalidWebhook = errors.New("itispay: invalid webhook")
+
+// WebhookPayload is the body of a webhook callback sent when an invoice changes status
+type WebhookPayload = types.WebhookPayload
+
+// WebhookTransaction is a blockchain transaction reported in a webhook
+type WebhookTransaction = types.WebhookTransaction
+
+// ParseWebhook reads and decodes the webhook payload of a callback request
+func ParseWebhook(r *http.Request) (*WebhookPayload, error) {
+	return ParseWebhookWithClock(r, SystemClock)
+}
+
+// ParseWebhookWithClock is like ParseWebhook, taking the time the webhook was received
+// from clock
+func ParseWebhookWithClock(r *http.Request, clock Clock) (*WebhookPayload, error) {
+	receivedAt := clockOrSystem(clock).Now()
+	if r.Method != http.MethodPost {
+		return nil, fmt.Errorf("%w: method %s", ErrInvalidWebhook, r.Method)
+	}
+
+	body, err := io.ReadAll(io.LimitReader(r.Body, maxWebhookBodySize))
+	if err != nil {
+		return nil, fmt.Errorf("%w: reading body: %v", ErrInvalidWebhook, err)
+	}
+	var payload WebhookPayload
+	if err := json.Unmarshal(body, &payload); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidWebhook, err)
+	}
+	if payload.InvoiceID == "" {
+		return nil, fmt.Errorf("%w: missing invoice_id", ErrInvalidWebhook)
+	}
+	payload.ReceivedAt = receivedAt
+	return &payload, nil
+}